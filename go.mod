@@ -4,18 +4,20 @@ go 1.24.5
 
 require (
 	github.com/abemedia/go-don v0.2.1
+	github.com/andybalholm/brotli v1.0.5
 	github.com/brianvoe/gofakeit/v7 v7.3.0
 	github.com/go-playground/validator/v10 v10.27.0
 	github.com/golang-jwt/jwt/v5 v5.2.3
 	github.com/oklog/ulid/v2 v2.1.1
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/crypto v0.33.0
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/abemedia/fasthttpfs v0.0.0-20220405193636-731805b0c723 // indirect
 	github.com/abemedia/httprouter v0.0.0-20230505023925-232e0e5a4b1b // indirect
-	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
@@ -28,6 +30,4 @@ require (
 	github.com/valyala/fasthttp v1.47.0 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,24 +1,34 @@
 module github.com/peteraba/go-frameworks/godon
 
-go 1.24.4
+go 1.24.5
+
+require (
+	github.com/abemedia/go-don v0.2.1
+	github.com/peteraba/go-frameworks v0.0.0-00010101000000-000000000000
+	github.com/valyala/fasthttp v1.47.0
+)
 
 require (
 	github.com/abemedia/fasthttpfs v0.0.0-20220405193636-731805b0c723 // indirect
-	github.com/abemedia/go-don v0.2.1 // indirect
 	github.com/abemedia/httprouter v0.0.0-20230505023925-232e0e5a4b1b // indirect
 	github.com/andybalholm/brotli v1.0.5 // indirect
+	github.com/brianvoe/gofakeit/v7 v7.3.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.27.0 // indirect
 	github.com/goccy/go-json v0.10.2 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.3 // indirect
 	github.com/klauspost/compress v1.16.5 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
+	github.com/oklog/ulid/v2 v2.1.1 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.47.0 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// The shared packages are taken from the repository, as they are not published separately
+replace github.com/peteraba/go-frameworks => ../
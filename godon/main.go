@@ -3,24 +3,32 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/abemedia/go-don"
 	_ "github.com/abemedia/go-don/encoding/json" // Enable JSON parsing & rendering.
+	"github.com/valyala/fasthttp"
 
-	"github.com/peteraba/go-frameworks/shared"
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/peteraba/go-frameworks/shared/model"
 )
 
-func CreateTodo(ctx context.Context, req shared.TodoCreate) (*shared.Todo, error) {
+func CreateTodo(ctx context.Context, req model.TodoCreate) (*model.Todo, error) {
 	if req.Title == "" {
 		return nil, don.Error(errors.New("missing title"), http.StatusBadRequest)
 	}
 
-	res := &shared.Todo{
+	res := &model.Todo{
 		ID:          "01K02MFYHQTCZRPAZGMYC1CZFH",
 		Title:       "This is a title",
 		Description: "This is a description",
-		Complete:    false,
+		Completed:   false,
 	}
 
 	return res, nil
@@ -31,11 +39,87 @@ func Pong(context.Context, any) (string, error) {
 }
 
 func main() {
+	loader := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := loader.Load(os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if loader.PrintRequested() {
+		fmt.Print(cfg)
+		return
+	}
+
+	warnUnsupported(cfg)
+
 	r := don.New(&don.Config{
 		DefaultEncoding: "application/json",
 	})
-	r.Get("/ping", don.H(Pong)) // Handlers are wrapped with `don.H`.
-	r.Post("/todos", don.H(CreateTodo))
-	r.Put("/todos/:id", don.H(CreateTodo))
-	r.ListenAndServe(":8080")
+	api := r.Group(cfg.API.BasePath)
+	api.Get("/ping", don.H(Pong)) // Handlers are wrapped with `don.H`.
+	api.Post("/todos", don.H(CreateTodo))
+	api.Put("/todos/:id", don.H(CreateTodo))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = listenAndServe(ctx, newServer(r, cfg.Server), cfg.Server)
+	stop()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Server stopped")
+}
+
+// newServer returns a server with the settings go-don uses, and the limits of the configuration. fasthttp has no
+// separate timeout for the request headers, they have to be read within the read timeout, and their size is limited
+// by the read buffer.
+func newServer(r *don.API, cfg config.ServerConfig) *fasthttp.Server {
+	return &fasthttp.Server{
+		Handler:               r.RequestHandler(),
+		StreamRequestBody:     true,
+		NoDefaultContentType:  true,
+		NoDefaultServerHeader: true,
+		ReadTimeout:           cfg.ReadTimeout,
+		WriteTimeout:          cfg.WriteTimeout,
+		IdleTimeout:           cfg.IdleTimeout,
+		ReadBufferSize:        cfg.MaxHeaderBytes,
+	}
+}
+
+// listenAndServe serves requests until ctx is done, then waits for the open requests at most for the shutdown timeout
+func listenAndServe(ctx context.Context, srv *fasthttp.Server, cfg config.ServerConfig) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe(cfg.Addr)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.ShutdownWithContext(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down the server, err: %w", err)
+	}
+
+	return <-errCh
+}
+
+// warnUnsupported logs the settings which are configured, but have no effect, as this server has no users, audit log
+// or browser clients
+func warnUnsupported(cfg config.Config) {
+	if cfg.Users.File != "" {
+		log.Printf("Users are not supported, the user file is ignored: %s", cfg.Users.File)
+	}
+	if cfg.Audit.File != "" {
+		log.Printf("Nothing is audited, the audit log file is ignored: %s", cfg.Audit.File)
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		log.Println("CORS is not supported, the allowed origins are ignored")
+	}
 }
@@ -0,0 +1,51 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+func (s *Server) handleListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.AuditEventFilter{
+		Type:    model.AuditEventType(query.Get("type")),
+		Actor:   query.Get("actor"),
+		Target:  query.Get("target"),
+		Outcome: model.AuditOutcome(query.Get("outcome")),
+		After:   query.Get("after"),
+	}
+
+	var err error
+	if v := query.Get("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Invalid since, RFC 3339 timestamp expected")
+			return
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Invalid until, RFC 3339 timestamp expected")
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+			writeError(w, r, http.StatusBadRequest, "Invalid limit, positive integer expected")
+			return
+		}
+	}
+
+	events, err := s.auditService.Query(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
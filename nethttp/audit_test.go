@@ -0,0 +1,55 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRoutes(t *testing.T) {
+	t.Run("denied requests are listed", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, token := ts.createUser(t)
+		require.Equal(t, http.StatusForbidden, ts.do(t, http.MethodGet, "/api/v1/projects", token, nil).Code)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/audit-events?type=auth.access_denied&actor="+user.ID, ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		events := decode[[]model.AuditEvent](t, rec)
+		require.Len(t, events, 1)
+		assert.Equal(t, model.AuditOutcomeDenied, events[0].Outcome)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		for _, query := range []string{"since=yesterday", "until=tomorrow", "limit=0", "limit=ten"} {
+			t.Run(query, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, http.MethodGet, "/api/v1/audit-events?"+query, ts.adminToken, nil)
+
+				// verify
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			})
+		}
+	})
+
+	t.Run("non-admins are forbidden", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, token := ts.createUser(t, model.GroupProjectRead, model.GroupProjectWrite)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/audit-events", token, nil)
+
+		// verify
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,191 @@
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+type loggedInUserContextKey struct{}
+
+type bearerUserContextKey struct{}
+
+var errMissingBearerToken = errors.New("missing bearer token")
+
+// resolvedBearer is the result of resolving the bearer token of a request
+type resolvedBearer struct {
+	user model.LoggedInUser
+	err  error
+}
+
+// withBearerUser returns r carrying the user of its bearer token, so that the token is only parsed once
+func (s *Server) withBearerUser(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(bearerUserContextKey{}).(resolvedBearer); ok {
+		return r
+	}
+
+	var b resolvedBearer
+	tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		b.err = errMissingBearerToken
+	} else {
+		b.user, b.err = s.userService.TokenToLoggedInUser(r.Context(), tokenString)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), bearerUserContextKey{}, b))
+}
+
+// bearerUser returns the user of the bearer token resolved by withBearerUser
+func bearerUser(r *http.Request) (model.LoggedInUser, error) {
+	b, ok := r.Context().Value(bearerUserContextKey{}).(resolvedBearer)
+	if !ok {
+		return model.LoggedInUser{}, errMissingBearerToken
+	}
+
+	return b.user, b.err
+}
+
+// authorizer decides whether a logged-in user may access the requested resource
+type authorizer struct {
+	// group is the group authorized by inGroup, empty for other authorizers
+	group string
+	allow func(r *http.Request, liu model.LoggedInUser) bool
+}
+
+// inGroup authorizes members of the given group
+func inGroup(group string) authorizer {
+	return authorizer{
+		group: group,
+		allow: func(_ *http.Request, liu model.LoggedInUser) bool {
+			return liu.HasGroup(group)
+		},
+	}
+}
+
+// isSelf authorizes users whose ID matches the given path parameter
+func isSelf(param string) authorizer {
+	return authorizer{
+		allow: func(r *http.Request, liu model.LoggedInUser) bool {
+			return liu.ID != "" && r.PathValue(param) == liu.ID
+		},
+	}
+}
+
+// clientIP returns the IP address of the remote end of the connection
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// withActor makes the client IP available to the services for every request
+func withActor(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := service.ContextWithActor(r.Context(), service.Actor{IP: clientIP(r)})
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticated is a handler requiring a valid bearer token, and if authorizers are given, at least one of them
+// to succeed. Denied requests are recorded in the audit log, authorized ones are validated against the OpenAPI
+// document.
+type authenticated struct {
+	s           *Server
+	h           http.HandlerFunc
+	authorizers []authorizer
+}
+
+// authenticate returns h requiring authentication, the routes record the groups of the authorizers
+func (s *Server) authenticate(h http.HandlerFunc, authorizers ...authorizer) *authenticated {
+	return &authenticated{s: s, h: h, authorizers: authorizers}
+}
+
+func (a *authenticated) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = a.s.withBearerUser(r)
+	liu, err := bearerUser(r)
+	if errors.Is(err, errMissingBearerToken) {
+		a.s.deny(w, r, "", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	if err != nil {
+		a.s.deny(w, r, "", http.StatusUnauthorized, "invalid bearer token")
+		return
+	}
+	setLogUser(r.Context(), liu.ID)
+
+	if !isAuthorized(r, liu, a.authorizers) {
+		a.s.deny(w, r, liu.ID, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	if !a.s.validRequest(w, r) {
+		return
+	}
+
+	actor := service.ActorFromContext(r.Context())
+	actor.UserID = liu.ID
+
+	ctx := service.ContextWithActor(r.Context(), actor)
+	ctx = context.WithValue(ctx, loggedInUserContextKey{}, liu)
+
+	a.h(w, r.WithContext(ctx))
+}
+
+// groups returns the groups authorized by the authorizers
+func (a *authenticated) groups() []string {
+	var groups []string
+	for _, authorizer := range a.authorizers {
+		if authorizer.group != "" {
+			groups = append(groups, authorizer.group)
+		}
+	}
+
+	return groups
+}
+
+// loggedInUser returns the user authenticated for the request, or an anonymous user for public routes
+func loggedInUser(r *http.Request) model.LoggedInUser {
+	liu, _ := r.Context().Value(loggedInUserContextKey{}).(model.LoggedInUser)
+
+	return liu
+}
+
+func isAuthorized(r *http.Request, liu model.LoggedInUser, authorizers []authorizer) bool {
+	if len(authorizers) == 0 {
+		return true
+	}
+
+	for _, a := range authorizers {
+		if a.allow(r, liu) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deny records the denied request in the audit log and writes the error response
+func (s *Server) deny(w http.ResponseWriter, r *http.Request, userID string, status int, reason string) {
+	s.auditService.Record(r.Context(), model.AuditEvent{
+		Type:    model.AuditEventAccessDenied,
+		Actor:   userID,
+		Target:  r.Method + " " + r.URL.Path,
+		Outcome: model.AuditOutcomeDenied,
+		Reason:  reason,
+	})
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+
+	writeError(w, r, status, reason)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// resolvePassword returns the configured password, reading it from the password file if necessary. Setting both
+// is rejected by the config validation. If neither is set, a random password is generated and generated is set to true.
+func resolvePassword(c config.AdminConfig) (password string, generated bool, err error) {
+	switch {
+	case c.Password != "":
+		return string(c.Password), false, nil
+	case c.PasswordFile != "":
+		content, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read admin password file: %s, err: %w", c.PasswordFile, err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), false, nil
+	}
+
+	return service.GeneratePassword(), true, nil
+}
+
+// bootstrapAdmin creates the first administrator, unless one already exists
+func bootstrapAdmin(ctx context.Context, userService *service.UserService, c config.AdminConfig) error {
+	password, generated, err := resolvePassword(c)
+	if err != nil {
+		return err
+	}
+
+	admin, err := userService.BootstrapAdmin(ctx, c.Name, c.Email, password)
+	if errors.Is(err, service.ErrAdminExists) {
+		log.Println("Administrator already exists, skipping bootstrap")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap administrator, err: %w", err)
+	}
+
+	log.Printf("Created administrator %s (%s)", admin.Email, admin.ID)
+	if generated {
+		printGeneratedPassword(admin.Email, password)
+	}
+
+	return nil
+}
+
+// runCreateAdmin implements the create-admin command used for recovering administrator access
+func runCreateAdmin(ctx context.Context, userService *service.UserService, c config.AdminConfig) error {
+	password, generated, err := resolvePassword(c)
+	if err != nil {
+		return err
+	}
+
+	admin, err := userService.CreateAdmin(ctx, c.Name, c.Email, password)
+	if err != nil {
+		return fmt.Errorf("failed to create administrator, err: %w", err)
+	}
+
+	log.Printf("Administrator %s (%s) is ready", admin.Email, admin.ID)
+	if generated {
+		printGeneratedPassword(admin.Email, password)
+	}
+
+	return nil
+}
+
+// printGeneratedPassword shows a generated password exactly once, it is not stored anywhere in plain text
+func printGeneratedPassword(email, password string) {
+	fmt.Fprintf(os.Stderr, "\nGenerated password for %s: %s\nIt will not be shown again, store it securely.\n\n", email, password)
+}
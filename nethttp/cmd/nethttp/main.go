@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+func main() {
+	// The standard logger is redirected to the default slog logger, so everything is logged as JSON
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	slog.SetDefault(logger)
+
+	// The create-admin command takes the same configuration as the server
+	fs, args := flag.CommandLine, os.Args[1:]
+	createAdmin := len(args) > 0 && args[0] == "create-admin"
+	if createAdmin {
+		fs, args = flag.NewFlagSet("create-admin", flag.ExitOnError), args[1:]
+	}
+
+	loader := config.RegisterFlags(fs)
+	_ = fs.Parse(args) // Both flag sets exit on error
+
+	cfg, err := loader.Load(os.LookupEnv)
+	if err != nil {
+		logger.Error("Failed to load the configuration", "err", err)
+		os.Exit(1)
+	}
+	if loader.PrintRequested() {
+		fmt.Print(cfg)
+		return
+	}
+	if cfg.Auth.JWTKey == "" {
+		logger.Warn("No JWT key is configured, tokens are signed with a random key and do not survive restarts")
+	}
+
+	auditSink := newAuditSink(cfg)
+	auditService := service.NewAuditService(auditSink)
+	userRepo, err := newUserRepo(cfg)
+	if err != nil {
+		logger.Error("Failed to open the user store", "err", err)
+		os.Exit(1)
+	}
+	userService := service.NewUserServiceWithConfig(userRepo, auditService, cfg.UserConfig())
+
+	index := search.NewIndex()
+	projectRepo := repo.NewIndexedProjectRepo(repo.NewInMemoryProjectRepoWithLimit(cfg.Limits.Projects), index)
+	listRepo := repo.NewIndexedListRepo(repo.NewInMemoryListRepoWithLimit(cfg.Limits.Lists), index)
+	todoRepo := repo.NewIndexedTodoRepo(repo.NewInMemoryTodoRepoWithLimit(cfg.Limits.Todos), index)
+
+	deps := nethttp.Deps{
+		ProjectService: service.NewProjectService(projectRepo),
+		ListService:    service.NewListService(listRepo),
+		TodoService:    service.NewTodoService(todoRepo),
+		UserService:    userService,
+		AuditService:   auditService,
+		SearchService:  service.NewSearchService(index, projectRepo, listRepo, todoRepo),
+	}
+
+	if createAdmin {
+		// Admins created in memory would be lost when the command exits, instead of restoring access to the server
+		if cfg.Users.File == "" {
+			logger.Error("The create-admin command needs the user file of the server, set USERS_FILE or -users-file")
+			os.Exit(1)
+		}
+		if err := runCreateAdmin(context.Background(), userService, cfg.Admin); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := bootstrapAdmin(context.Background(), userService, cfg.Admin); err != nil {
+		log.Fatal(err)
+	}
+
+	// Hooks are run in order on shutdown: background workers first, as they may still use the repos
+	hooks := []nethttp.ShutdownHook{
+		nethttp.Worker("user purge", func(ctx context.Context) {
+			purgeDeletedUsers(ctx, userService, userPurgeInterval)
+		}),
+	}
+	if closer, ok := auditSink.(io.Closer); ok {
+		hooks = append(hooks, nethttp.ShutdownHook{
+			Name:  "audit log",
+			Close: func(context.Context) error { return closer.Close() },
+		})
+	}
+
+	spec, err := loadSpec()
+	if err != nil {
+		logger.Error("Failed to load the OpenAPI document", "err", err)
+		os.Exit(1)
+	}
+
+	rateLimiter, err := nethttp.NewRateLimiter(nethttp.DefaultRateLimitConfig())
+	if err != nil {
+		logger.Error("Failed to configure rate limits", "err", err)
+		os.Exit(1)
+	}
+	serverConfig := nethttp.Config{
+		Logger:      logger,
+		RateLimiter: rateLimiter,
+		BasePath:    cfg.API.BasePath,
+		Spec:        spec,
+		Idempotency: nethttp.NewIdempotencyStore(nethttp.IdempotencyConfig{TTL: cfg.API.IdempotencyTTL}),
+		Compression: &nethttp.CompressionConfig{},
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		serverConfig.CORS, err = nethttp.NewCORS(nethttp.CORSConfig{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		})
+		if err != nil {
+			logger.Error("Failed to configure CORS", "err", err)
+			os.Exit(1)
+		}
+	}
+	if cfg.API.LegacyRoutes {
+		serverConfig.LegacyRoutes = &nethttp.LegacyRoutes{Deprecated: cfg.API.Deprecated, Sunset: cfg.API.Sunset}
+	}
+	handler := nethttp.NewServer(deps, serverConfig)
+	srv := nethttp.NewHTTPServer(handler, cfg.Server, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = nethttp.ListenAndServe(ctx, srv, cfg.Server.ShutdownTimeout, logger, hooks...)
+	stop()
+
+	if err != nil {
+		logger.Error("Server failed", "err", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Server stopped")
+}
+
+// newUserRepo returns a repo stored in the configured user file, shared with the create-admin command, or an
+// in-memory one if no file is configured
+func newUserRepo(cfg config.Config) (repo.UserRepo, error) {
+	if cfg.Users.File == "" {
+		return repo.NewInMemoryUserRepoWithLimit(cfg.Limits.Users), nil
+	}
+
+	return repo.NewJSONFileUserRepo(cfg.Users.File, cfg.Limits.Users)
+}
+
+// newAuditSink returns a JSON-lines audit sink if an audit log file is configured, an in-memory one otherwise
+func newAuditSink(cfg config.Config) repo.AuditSink {
+	path := cfg.Audit.File
+	if path == "" {
+		return repo.NewInMemoryAuditSink()
+	}
+
+	sink, err := repo.NewJSONLinesAuditSink(path)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+
+	return sink
+}
+
+// loadSpec returns the embedded OpenAPI document the requests are validated against
+func loadSpec() (*openapi.Spec, error) {
+	doc, err := openapi.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Spec()
+}
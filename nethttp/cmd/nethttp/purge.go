@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// userPurgeInterval is how often users whose deletion grace period is over get anonymized
+const userPurgeInterval = time.Hour
+
+// purgeDeletedUsers periodically anonymizes users whose deletion grace period is over, until ctx is done
+func purgeDeletedUsers(ctx context.Context, userService *service.UserService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			count, err := userService.PurgeDeleted(ctx, now)
+			if err != nil {
+				slog.Error("Failed to purge deleted users", "err", err)
+			}
+			if count > 0 {
+				slog.Info("Anonymized deleted users", "count", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package nethttp
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultCompressionMinSize is the size of the smallest body compressed by default, smaller ones barely shrink
+const DefaultCompressionMinSize = 1024
+
+// brotliLevel trades some compression for speed, as the responses are compressed on the fly
+const brotliLevel = 4
+
+// CompressionConfig configures the compression of responses
+type CompressionConfig struct {
+	// MinSize is the size of the smallest body compressed in bytes, DefaultCompressionMinSize if zero
+	MinSize int
+}
+
+// encoder is a pooled compressor of one content coding
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encodings are the supported content codings, in the order preferred if the client accepts several equally
+var encodings = []string{"br", "gzip", "deflate"}
+
+// encoderPools keep the encoders of each content coding, as they allocate large buffers
+var encoderPools = map[string]*sync.Pool{
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(nil, brotliLevel)
+	}},
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	// the deflate content coding is the zlib format of RFC 1950
+	"deflate": {New: func() any {
+		w, _ := zlib.NewWriterLevel(nil, zlib.DefaultCompression)
+		return w
+	}},
+}
+
+var compressWriterPool = sync.Pool{New: func() any {
+	return &compressWriter{}
+}}
+
+// compress compresses the responses in the content coding the client prefers, as negotiated by the
+// Accept-Encoding header. Bodies smaller than the minimum size, bodies which are already encoded, and media types
+// which do not compress well are sent as they are.
+func compress(cfg CompressionConfig) Middleware {
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := compressWriterPool.Get().(*compressWriter)
+			cw.reset(w, encoding, minSize, r.Header.Get("If-None-Match"))
+
+			next.ServeHTTP(cw, r)
+
+			cw.close()
+			cw.reset(nil, "", 0, "")
+			compressWriterPool.Put(cw)
+		})
+	}
+}
+
+// negotiateEncoding returns the supported content coding with the highest quality value in the Accept-Encoding
+// header, or an empty string if the response should not be encoded
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	qualities := map[string]float64{}
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "x-gzip" {
+			coding = "gzip"
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(strings.ToLower(name)) != "q" {
+				continue
+			}
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+		}
+		qualities[coding] = q
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQuality {
+			best, bestQuality = encoding, q
+		}
+	}
+
+	if q, ok := qualities["identity"]; ok && q > bestQuality {
+		return ""
+	}
+
+	return best
+}
+
+// compressible reports whether the media type is worth compressing
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/yaml", "application/xml", "application/javascript":
+		return true
+	}
+
+	return false
+}
+
+// compressWriter holds back the beginning of the body until it is known to be large enough to be compressed
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	// ifNoneMatch is the If-None-Match header of the request, telling the representation a 304 response is about
+	ifNoneMatch string
+
+	status int
+	buf    []byte
+	// started is set once the header is written, the body is compressed from then on if enc is set
+	started bool
+	enc     encoder
+}
+
+func (cw *compressWriter) reset(w http.ResponseWriter, encoding string, minSize int, ifNoneMatch string) {
+	cw.ResponseWriter = w
+	cw.encoding = encoding
+	cw.minSize = minSize
+	cw.ifNoneMatch = ifNoneMatch
+	cw.status = 0
+	cw.buf = cw.buf[:0]
+	cw.started = false
+	cw.enc = nil
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started || cw.status != 0 {
+		return
+	}
+	// informational responses are sent right away, they are followed by the final one
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	if !cw.started {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.minSize {
+			return len(b), nil
+		}
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+
+	return cw.ResponseWriter.Write(b)
+}
+
+// start writes the header, compressing the body if it is worth it, and the body held back so far
+func (cw *compressWriter) start(worthIt bool) error {
+	cw.started = true
+
+	h := cw.ResponseWriter.Header()
+	tag := h.Get("ETag")
+	if worthIt && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", cw.encoding)
+		if tag != "" {
+			h.Set("ETag", encodedETag(tag, cw.encoding))
+		}
+		h.Del("Content-Length")
+		cw.enc = encoderPools[cw.encoding].Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	// A 304 response carries the entity tag of the representation the client has, which may be an encoded one
+	if cw.status == http.StatusNotModified && tag != "" && slices.Contains(entityTags(cw.ifNoneMatch), encodedETag(tag, cw.encoding)) {
+		h.Set("ETag", encodedETag(tag, cw.encoding))
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = cw.buf[:0]
+
+	return err
+}
+
+// close writes the rest of the body, and returns the encoder to its pool
+func (cw *compressWriter) close() {
+	if !cw.started && cw.status != 0 {
+		_ = cw.start(false)
+	}
+
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		encoderPools[cw.encoding].Put(cw.enc)
+	}
+}
+
+// Flush sends the body written so far, compressing the rest of the body, as streamed responses are usually large
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		_ = cw.start(true)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
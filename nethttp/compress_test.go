@@ -0,0 +1,209 @@
+package nethttp_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decompress returns the body of the response decoded from its content coding
+func decompress(t testing.TB, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+
+	var (
+		r   io.Reader = rec.Body
+		err error
+	)
+	switch rec.Header().Get("Content-Encoding") {
+	case "gzip":
+		r, err = gzip.NewReader(rec.Body)
+	case "deflate":
+		r, err = zlib.NewReader(rec.Body)
+	case "br":
+		r = brotli.NewReader(rec.Body)
+	}
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return body
+}
+
+func TestCompression(t *testing.T) {
+	t.Run("negotiation", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{Compression: &nethttp.CompressionConfig{}})
+		want := ts.do(t, http.MethodGet, "/api/v1/openapi.json", "", nil).Body.Bytes()
+
+		tests := map[string]struct {
+			acceptEncoding string
+			wantEncoding   string
+		}{
+			"no header":               {},
+			"gzip":                    {acceptEncoding: "gzip", wantEncoding: "gzip"},
+			"deflate":                 {acceptEncoding: "deflate", wantEncoding: "deflate"},
+			"brotli preferred":        {acceptEncoding: "gzip, deflate, br", wantEncoding: "br"},
+			"quality values":          {acceptEncoding: "gzip;q=1, br;q=0.5", wantEncoding: "gzip"},
+			"case insensitive":        {acceptEncoding: "GZIP;Q=0.5", wantEncoding: "gzip"},
+			"x-gzip":                  {acceptEncoding: "x-gzip", wantEncoding: "gzip"},
+			"wildcard":                {acceptEncoding: "*", wantEncoding: "br"},
+			"wildcard with exclusion": {acceptEncoding: "br;q=0, *", wantEncoding: "gzip"},
+			"refused":                 {acceptEncoding: "gzip;q=0"},
+			"invalid quality":         {acceptEncoding: "gzip;q=2"},
+			"unsupported":             {acceptEncoding: "zstd, compress"},
+			"identity preferred":      {acceptEncoding: "gzip;q=0.5, identity"},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/openapi.json", "", nil, http.Header{"Accept-Encoding": {tt.acceptEncoding}})
+
+				// verify
+				require.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+				assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+				assert.Empty(t, rec.Header().Get("Content-Length"))
+				assert.Equal(t, want, decompress(t, rec))
+			})
+		}
+	})
+
+	t.Run("small bodies are not compressed", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{Compression: &nethttp.CompressionConfig{}})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/health", "", nil, http.Header{"Accept-Encoding": {"gzip"}})
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.JSONEq(t, `{"message":"ok"}`, rec.Body.String())
+	})
+
+	t.Run("entity tags and conditional requests", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{Compression: &nethttp.CompressionConfig{MinSize: 1}})
+		project, err := ts.deps.ProjectService.Create(context.Background(), model.RandomProjectCreate())
+		require.NoError(t, err)
+		header := http.Header{"Accept-Encoding": {"gzip"}}
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/projects/"+project.ID, ts.adminToken, nil, header)
+		header.Set("If-None-Match", rec.Header().Get("ETag"))
+		notModifiedRec := ts.doWithHeader(t, http.MethodGet, "/api/v1/projects/"+project.ID, ts.adminToken, nil, header)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `"1-gzip"`, rec.Header().Get("ETag"), "encoded representations have their own entity tags")
+		var got model.Project
+		require.NoError(t, json.Unmarshal(decompress(t, rec), &got))
+		assert.Equal(t, project.Name, got.Name)
+		assert.Equal(t, http.StatusNotModified, notModifiedRec.Code)
+		assert.Equal(t, `"1-gzip"`, notModifiedRec.Header().Get("ETag"))
+		assert.Empty(t, notModifiedRec.Header().Get("Content-Encoding"))
+		assert.Empty(t, notModifiedRec.Body.Bytes())
+	})
+
+	t.Run("encodings have different entity tags", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{Compression: &nethttp.CompressionConfig{MinSize: 1}})
+		project, err := ts.deps.ProjectService.Create(context.Background(), model.RandomProjectCreate())
+		require.NoError(t, err)
+		path := "/api/v1/projects/" + project.ID
+
+		// execute
+		identityRec := ts.doWithHeader(t, http.MethodGet, path, ts.adminToken, nil, http.Header{"Accept-Encoding": {"identity"}})
+		tags := map[string]bool{identityRec.Header().Get("ETag"): true}
+		for _, encoding := range []string{"br", "gzip", "deflate"} {
+			rec := ts.doWithHeader(t, http.MethodGet, path, ts.adminToken, nil, http.Header{"Accept-Encoding": {encoding}})
+			require.Equal(t, encoding, rec.Header().Get("Content-Encoding"))
+			tags[rec.Header().Get("ETag")] = true
+		}
+		updateRec := ts.doWithHeader(t, http.MethodPut, path, ts.adminToken, model.RandomProjectUpdate(), http.Header{"If-Match": {`"1-br"`}})
+
+		// verify
+		assert.Equal(t, `"1"`, identityRec.Header().Get("ETag"))
+		assert.Len(t, tags, 4)
+		assert.Equal(t, http.StatusOK, updateRec.Code, "the entity tags of encoded representations match their version")
+	})
+
+	t.Run("head requests", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{Compression: &nethttp.CompressionConfig{}})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodHead, "/api/v1/docs", "", nil, http.Header{"Accept-Encoding": {"gzip"}})
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/openapi.json", "", nil, http.Header{"Accept-Encoding": {"gzip"}})
+
+		// verify
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Empty(t, rec.Header().Get("Vary"))
+	})
+}
+
+// BenchmarkCompression lists 1000 todo items, the size of the uncompressed body is reported as the bytes processed,
+// and the size of the body sent as wire-bytes/op
+func BenchmarkCompression(b *testing.B) {
+	for _, encoding := range []string{"", "gzip", "deflate", "br"} {
+		name := encoding
+		cfg := nethttp.Config{Compression: &nethttp.CompressionConfig{}}
+		if encoding == "" {
+			name, cfg.Compression = "none", nil
+		}
+
+		b.Run(name, func(b *testing.B) {
+			ts := newTestServerWithConfig(b, cfg)
+			list, err := ts.deps.ListService.Create(context.Background(), model.RandomListCreate())
+			require.NoError(b, err)
+			for range 1000 {
+				tc := model.RandomTodoCreate()
+				tc.ListID = list.ID
+				_, err := ts.deps.TodoService.Create(context.Background(), tc)
+				require.NoError(b, err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/lists/"+list.ID+"/todos", nil)
+			req.Header.Set("Authorization", "Bearer "+ts.adminToken)
+			req.Header.Set("Accept-Encoding", encoding)
+
+			rec := httptest.NewRecorder()
+			ts.handler.ServeHTTP(rec, req)
+			require.Equal(b, http.StatusOK, rec.Code, rec.Body.String())
+			wireBytes := rec.Body.Len()
+			b.SetBytes(int64(len(decompress(b, rec))))
+
+			b.ReportAllocs()
+			for b.Loop() {
+				ts.handler.ServeHTTP(httptest.NewRecorder(), req)
+			}
+			b.ReportMetric(float64(wireBytes), "wire-bytes/op")
+		})
+	}
+}
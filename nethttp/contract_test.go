@@ -0,0 +1,51 @@
+package nethttp_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/contract"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContract(t *testing.T) {
+	// prepare
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	project, err := ts.deps.ProjectService.Create(context.Background(), model.ProjectCreate{Name: "Contract"})
+	require.NoError(t, err)
+	list, err := ts.deps.ListService.Create(context.Background(), model.ListCreate{ProjectID: project.ID, Name: "Contract"})
+	require.NoError(t, err)
+	todo, err := ts.deps.TodoService.Create(context.Background(), model.TodoCreate{ListID: list.ID, Title: "Contract"})
+	require.NoError(t, err)
+	uc := model.RandomUserCreate()
+	user, err := ts.deps.UserService.Create(ctx, uc)
+	require.NoError(t, err)
+	newUser := model.RandomUserCreate()
+
+	fixture := contract.Fixture{
+		BasePath: "/api/v1",
+		Header:   http.Header{"Authorization": {"Bearer " + ts.adminToken}},
+		PathParams: map[string]string{
+			"projectId": project.ID,
+			"listId":    list.ID,
+			"todoId":    todo.ID,
+			"userId":    user.ID,
+		},
+		Bodies: map[string]any{
+			"createList":         model.ListCreate{ProjectID: project.ID, Name: "Contract"},
+			"createUser":         newUser,
+			"loginUser":          model.UserLogin{Email: uc.Email, Password: uc.Password},
+			"updateUserPassword": model.UserPasswordUpdate{Password: "n3w-Passw0rd", Password2: "n3w-Passw0rd"},
+			"patchProject":       []map[string]any{{"op": "replace", "path": "/name", "value": "Patched"}},
+			"patchList":          []map[string]any{{"op": "replace", "path": "/name", "value": "Patched"}},
+			"patchTodo":          []map[string]any{{"op": "replace", "path": "/title", "value": "Patched"}},
+		},
+	}
+
+	// execute & verify
+	contract.Test(t, contract.Handler(ts.handler), testSpec(t), fixture)
+}
@@ -0,0 +1,223 @@
+package nethttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCORSConfig = errors.New("invalid CORS configuration")
+
+// CORSConfig configures cross-origin resource sharing, letting browser apps served from other origins call the API
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to call the API, like "https://app.example.com". "https://*.example.com"
+	// allows every subdomain of example.com served over HTTPS, and "*" allows every origin.
+	AllowedOrigins []string
+	// AllowedMethods limits the methods allowed, every method registered for the requested path is allowed if empty
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed, DefaultCORSAllowedHeaders if empty
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts may read besides the CORS-safelisted ones,
+	// DefaultCORSExposedHeaders if empty
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization headers, it can not be combined with "*"
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight responses, their default applies if it is zero
+	MaxAge time.Duration
+}
+
+// DefaultCORSAllowedHeaders are the request headers the API reads
+var DefaultCORSAllowedHeaders = []string{
+	"Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-API-Key", "X-Request-ID",
+}
+
+// DefaultCORSExposedHeaders are the response headers the API sets, besides the CORS-safelisted ones
+var DefaultCORSExposedHeaders = []string{
+	"Accept-Patch", "Deprecation", "ETag", "Idempotent-Replayed", "Link", "Retry-After", "Sunset", "WWW-Authenticate",
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Request-ID", "X-Total-Count",
+}
+
+// corsMethods are the methods the routes of a path are looked up for when answering preflight requests
+var corsMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// originPattern is an allowed origin, subdomains of host are matched if wildcard is set
+type originPattern struct {
+	scheme   string
+	host     string
+	wildcard bool
+}
+
+func (p originPattern) match(scheme, host string) bool {
+	if scheme != p.scheme {
+		return false
+	}
+	if !p.wildcard {
+		return host == p.host
+	}
+
+	sub, found := strings.CutSuffix(host, "."+p.host)
+	return found && sub != ""
+}
+
+// CORS answers the preflight requests and sets the CORS headers of the responses to allowed origins
+type CORS struct {
+	cfg            CORSConfig
+	anyOrigin      bool
+	origins        []originPattern
+	allowedHeaders string
+	exposedHeaders string
+}
+
+// NewCORS returns the CORS settings of the server, the origins are validated
+func NewCORS(cfg CORSConfig) (*CORS, error) {
+	c := &CORS{cfg: cfg}
+	c.cfg.AllowedMethods = make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		c.cfg.AllowedMethods = append(c.cfg.AllowedMethods, strings.ToUpper(method))
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+			continue
+		}
+
+		p, err := parseOriginPattern(origin)
+		if err != nil {
+			return nil, err
+		}
+		c.origins = append(c.origins, p)
+	}
+
+	if c.anyOrigin && cfg.AllowCredentials {
+		return nil, fmt.Errorf("%w: credentials can not be allowed for every origin", ErrInvalidCORSConfig)
+	}
+
+	allowedHeaders, exposedHeaders := cfg.AllowedHeaders, cfg.ExposedHeaders
+	if len(allowedHeaders) == 0 {
+		allowedHeaders = DefaultCORSAllowedHeaders
+	}
+	if len(exposedHeaders) == 0 {
+		exposedHeaders = DefaultCORSExposedHeaders
+	}
+	c.allowedHeaders = strings.Join(allowedHeaders, ", ")
+	c.exposedHeaders = strings.Join(exposedHeaders, ", ")
+
+	return c, nil
+}
+
+// parseOriginPattern parses origins like "https://app.example.com" and "https://*.example.com:8443"
+func parseOriginPattern(origin string) (originPattern, error) {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return originPattern{}, fmt.Errorf("%w: invalid origin: %s", ErrInvalidCORSConfig, origin)
+	}
+
+	host, wildcard := strings.CutPrefix(u.Host, "*.")
+	if strings.Contains(host, "*") {
+		return originPattern{}, fmt.Errorf("%w: invalid origin: %s", ErrInvalidCORSConfig, origin)
+	}
+
+	return originPattern{scheme: u.Scheme, host: host, wildcard: wildcard}, nil
+}
+
+// allowed reports whether the origin sent by a browser is allowed
+func (c *CORS) allowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(c.origins, func(p originPattern) bool {
+		return p.match(u.Scheme, u.Host)
+	})
+}
+
+// allowOrigin sets the origin the response is shared with, the origin itself unless every origin is allowed
+func (c *CORS) allowOrigin(h http.Header, origin string) {
+	if c.anyOrigin {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+	}
+	if c.cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// handleCORS answers the preflight requests of allowed origins with the methods registered for the path, and
+// shares the responses of the other requests with allowed origins. Preflight requests are answered before
+// authentication and rate limiting, as browsers send them without credentials.
+func (s *Server) handleCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			if s.cors.allowed(origin) {
+				s.cors.allowOrigin(w.Header(), origin)
+				w.Header().Set("Access-Control-Expose-Headers", s.cors.exposedHeaders)
+			} else if !s.cors.anyOrigin {
+				w.Header().Add("Vary", "Origin")
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+
+		if !s.cors.allowed(origin) {
+			w.Header().Add("Vary", "Origin")
+			writeError(w, r, http.StatusForbidden, "The origin is not allowed")
+			return
+		}
+
+		methods := s.routeMethods(r)
+		if len(methods) == 0 {
+			writeError(w, r, http.StatusNotFound, "No route matches the request path")
+			return
+		}
+
+		s.cors.allowOrigin(w.Header(), origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", s.cors.allowedHeaders)
+		if s.cors.cfg.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(s.cors.cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// routeMethods returns the methods registered for the path of the request, limited to the allowed ones
+func (s *Server) routeMethods(r *http.Request) []string {
+	var methods []string
+
+	for _, method := range corsMethods {
+		if len(s.cors.cfg.AllowedMethods) > 0 && !slices.Contains(s.cors.cfg.AllowedMethods, method) {
+			continue
+		}
+
+		probe := *r
+		probe.Method = method
+		if s.route(&probe) != "" {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
@@ -0,0 +1,209 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCORSServer returns a test server allowing the given origins
+func newCORSServer(t *testing.T, cfg nethttp.CORSConfig, serverCfg nethttp.Config) *testServer {
+	t.Helper()
+
+	cors, err := nethttp.NewCORS(cfg)
+	require.NoError(t, err)
+	serverCfg.CORS = cors
+
+	return newTestServerWithConfig(t, serverCfg)
+}
+
+// preflight returns the headers of a preflight request
+func preflight(origin, method string) http.Header {
+	return http.Header{
+		"Origin":                         {origin},
+		"Access-Control-Request-Method":  {method},
+		"Access-Control-Request-Headers": {"authorization, content-type"},
+	}
+}
+
+func TestNewCORS(t *testing.T) {
+	tests := map[string]struct {
+		cfg     nethttp.CORSConfig
+		wantErr bool
+	}{
+		"exact origin":               {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}},
+		"wildcard subdomain":         {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"https://*.example.com:8443"}}},
+		"every origin":               {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"*"}}},
+		"missing scheme":             {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"app.example.com"}}, wantErr: true},
+		"path":                       {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"https://example.com/app"}}, wantErr: true},
+		"wildcard in the middle":     {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"https://app.*.example.com"}}, wantErr: true},
+		"credentials for any origin": {cfg: nethttp.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			_, err := nethttp.NewCORS(tt.cfg)
+
+			// verify
+			if tt.wantErr {
+				assert.ErrorIs(t, err, nethttp.ErrInvalidCORSConfig)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	cfg := nethttp.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	t.Run("preflight lists the methods of the route", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects/01K02SD13A5YKWWZFV9AQP7H1X", "", nil, preflight("https://app.example.com", http.MethodPut))
+
+		// verify
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, HEAD, PUT, PATCH", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Authorization, Content-Type, Idempotency-Key, If-Match, If-None-Match, X-API-Key, X-Request-ID", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, []string{"Access-Control-Request-Method, Access-Control-Request-Headers", "Origin"}, rec.Header().Values("Vary"))
+	})
+
+	t.Run("preflight of a wildcard subdomain", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects", "", nil, preflight("https://a.b.example.org", http.MethodPost))
+		apexRec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects", "", nil, preflight("https://example.org", http.MethodPost))
+		httpRec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects", "", nil, preflight("http://app.example.org", http.MethodPost))
+
+		// verify
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, HEAD, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, http.StatusForbidden, apexRec.Code, "the wildcard only matches subdomains")
+		assert.Equal(t, http.StatusForbidden, httpRec.Code, "the scheme must match")
+		assert.Empty(t, httpRec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight of an unknown path", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/unknown", "", nil, preflight("https://app.example.com", http.MethodGet))
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("allowed methods limit the preflight", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, nethttp.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get", "post"}}, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects/01K02SD13A5YKWWZFV9AQP7H1X", "", nil, preflight("https://any.example.net", http.MethodPut))
+
+		// verify
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("legacy routes", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{LegacyRoutes: testLegacyRoutes})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/lists", "", nil, preflight("https://app.example.com", http.MethodPost))
+
+		// verify
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, HEAD, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("responses are shared with allowed origins", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/projects", ts.adminToken, nil, http.Header{"Origin": {"https://app.example.com"}})
+		deniedRec := ts.doWithHeader(t, http.MethodGet, "/api/v1/projects", ts.adminToken, nil, http.Header{"Origin": {"https://evil.example.net"}})
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "X-Total-Count")
+		assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+		assert.Equal(t, http.StatusOK, deniedRec.Code, "browsers enforce CORS, the server still answers")
+		assert.Empty(t, deniedRec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Origin", deniedRec.Header().Get("Vary"))
+	})
+
+	t.Run("authentication errors are shared", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/projects", "", nil, http.Header{"Origin": {"https://app.example.com"}})
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "WWW-Authenticate")
+	})
+
+	t.Run("preflights do not take rate limit tokens", func(t *testing.T) {
+		// prepare
+		limiter, err := nethttp.NewRateLimiter(nethttp.RateLimitConfig{
+			Anonymous:     nethttp.Quota{Limit: 1, Period: time.Minute},
+			Authenticated: nethttp.Quota{Limit: 1, Period: time.Minute},
+		})
+		require.NoError(t, err)
+		ts := newCORSServer(t, cfg, nethttp.Config{RateLimiter: limiter})
+		origin := http.Header{"Origin": {"https://app.example.com"}}
+
+		// execute
+		for range 3 {
+			rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/health", "", nil, preflight("https://app.example.com", http.MethodGet))
+			require.Equal(t, http.StatusNoContent, rec.Code)
+		}
+		rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/health", "", nil, origin)
+		limitedRec := ts.doWithHeader(t, http.MethodGet, "/api/v1/health", "", nil, origin)
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusTooManyRequests, limitedRec.Code)
+		assert.Equal(t, "https://app.example.com", limitedRec.Header().Get("Access-Control-Allow-Origin"), "rate limited responses are shared")
+	})
+
+	t.Run("requests without an origin are left alone", func(t *testing.T) {
+		// prepare
+		ts := newCORSServer(t, cfg, nethttp.Config{})
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodOptions, "/api/v1/projects", "", nil, nil)
+
+		// verify
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -0,0 +1,136 @@
+package nethttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+)
+
+// maxBodySize is the largest request body accepted, in bytes
+const maxBodySize = 1 << 20
+
+// readBody reads the body of the request, rejecting bodies larger than maxBodySize.
+// If the body can not be read, the error response is written and false is returned.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, r, http.StatusRequestEntityTooLarge, "The request body is too large")
+			return nil, false
+		}
+		writeError(w, r, http.StatusBadRequest, "Failed to read the request body")
+		return nil, false
+	}
+
+	return content, true
+}
+
+// decodeJSON decodes the body of the request into v. The body must be a single JSON value sent as
+// application/json, and must not contain fields unknown to v. If the body can not be decoded, the problem
+// response is written and false is returned.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.ContentLength != 0 {
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" || (params["charset"] != "" && !strings.EqualFold(params["charset"], "utf-8")) {
+			writeError(w, r, http.StatusUnsupportedMediaType, "The request body must be sent as application/json")
+			return false
+		}
+	}
+
+	content, ok := readBody(w, r)
+	if !ok {
+		return false
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		writeError(w, r, http.StatusBadRequest, "The request body is empty")
+		return false
+	}
+
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		writeDecodeError(w, r, content, err)
+		return false
+	}
+
+	// anything but whitespace after the value is rejected, so that concatenated or truncated documents are noticed
+	if rest := bytes.TrimLeft(content[d.InputOffset():], " \t\r\n"); len(rest) > 0 {
+		writeSyntaxError(w, r, content, len(content)-len(rest), "unexpected data after the top-level value")
+		return false
+	}
+
+	return true
+}
+
+// writeDecodeError writes the problem response of a body which could not be decoded. Values of the wrong type
+// and unknown fields are reported as invalid fields, like the validation of the OpenAPI document does.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, content []byte, err error) {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		// the offset counts the offending byte as read
+		writeSyntaxError(w, r, content, int(syntaxErr.Offset)-1, strings.TrimPrefix(syntaxErr.Error(), "json: "))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		writeSyntaxError(w, r, content, len(content), "unexpected end of the body")
+	case errors.As(err, &typeErr):
+		typ := jsonType(typeErr.Type)
+		writeProblem(w, r, newViolationProblem([]openapi.Violation{{Name: typeErr.Field, Rule: "type", Params: []string{typ}, Reason: typeReason(typ)}}))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		name := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeProblem(w, r, newViolationProblem([]openapi.Violation{{Name: name, Rule: "additionalProperties", Reason: "is not allowed"}}))
+	default:
+		writeError(w, r, http.StatusBadRequest, "Invalid JSON body")
+	}
+}
+
+// writeSyntaxError writes a problem response pointing at the line and column of the byte at the offset in the
+// body, both counted from 1
+func writeSyntaxError(w http.ResponseWriter, r *http.Request, content []byte, offset int, reason string) {
+	before := content[:min(max(offset, 0), len(content))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	writeError(w, r, http.StatusBadRequest, fmt.Sprintf("Malformed JSON at line %d, column %d: %s", line, column, reason))
+}
+
+// jsonType returns the JSON type values of the Go type are decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return "string"
+}
+
+// typeReason describes the type rule like the validation of the OpenAPI document does
+func typeReason(typ string) string {
+	switch typ {
+	case "integer", "array", "object":
+		return "must be an " + typ
+	}
+
+	return "must be a " + typ
+}
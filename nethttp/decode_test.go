@@ -0,0 +1,119 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	// an empty document leaves the bodies to the handlers
+	ts := newTestServerWithConfig(t, nethttp.Config{Spec: &openapi.Spec{}})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		tests := map[string]struct {
+			body       string
+			header     http.Header
+			wantStatus int
+			wantDetail string
+		}{
+			"missing content type": {
+				body:       `{"name":"x"}`,
+				header:     http.Header{"Content-Type": {""}},
+				wantStatus: http.StatusUnsupportedMediaType,
+				wantDetail: "The request body must be sent as application/json",
+			},
+			"other media type": {
+				body:       `{"name":"x"}`,
+				header:     http.Header{"Content-Type": {"text/plain"}},
+				wantStatus: http.StatusUnsupportedMediaType,
+				wantDetail: "The request body must be sent as application/json",
+			},
+			"other charset": {
+				body:       `{"name":"x"}`,
+				header:     http.Header{"Content-Type": {"application/json; charset=iso-8859-1"}},
+				wantStatus: http.StatusUnsupportedMediaType,
+				wantDetail: "The request body must be sent as application/json",
+			},
+			"too large": {
+				body:       `{"name":"` + strings.Repeat("x", 1<<20) + `"}`,
+				wantStatus: http.StatusRequestEntityTooLarge,
+				wantDetail: "The request body is too large",
+			},
+			"empty": {
+				body:       "",
+				wantStatus: http.StatusBadRequest,
+				wantDetail: "The request body is empty",
+			},
+			"syntax error": {
+				body:       "{\n  \"name\" \"x\"\n}",
+				wantStatus: http.StatusBadRequest,
+				wantDetail: `Malformed JSON at line 2, column 10: invalid character '"' after object key`,
+			},
+			"truncated": {
+				body:       `{"name": "x"`,
+				wantStatus: http.StatusBadRequest,
+				wantDetail: "Malformed JSON at line 1, column 13: unexpected end of the body",
+			},
+			"trailing data": {
+				body:       `{"name": "x"} {}`,
+				wantStatus: http.StatusBadRequest,
+				wantDetail: "Malformed JSON at line 1, column 15: unexpected data after the top-level value",
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.doWithHeader(t, http.MethodPost, "/api/v1/projects", ts.adminToken, tt.body, tt.header)
+
+				// verify
+				require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+				assert.Equal(t, tt.wantDetail, decode[model.Problem](t, rec).Detail)
+			})
+		}
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		tests := map[string]struct {
+			body any
+			want model.InvalidParam
+		}{
+			"unknown field": {
+				body: map[string]any{"name": "x", "owner": "y"},
+				want: model.InvalidParam{Name: "owner", Rule: "additionalProperties", Reason: "is not allowed"},
+			},
+			"wrong type": {
+				body: map[string]any{"name": 42},
+				want: model.InvalidParam{Name: "name", Rule: "type", Params: []string{"string"}, Reason: "must be a string"},
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, tt.body)
+
+				// verify
+				require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+				problem := decode[model.Problem](t, rec)
+				assert.Equal(t, model.ProblemTypeValidation, problem.Type)
+				assert.Equal(t, []model.InvalidParam{tt.want}, problem.InvalidParams)
+			})
+		}
+	})
+
+	t.Run("valid body", func(t *testing.T) {
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, "/api/v1/projects", ts.adminToken, "\n{\"name\": \"Valid\"}\n", http.Header{"Content-Type": {"application/json; charset=UTF-8"}})
+
+		// verify
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+}
@@ -0,0 +1,94 @@
+package nethttp
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/repo"
+)
+
+// etag returns the strong entity tag of the given version of a resource
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the entity tag of the resource in the response
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// encodedETag returns the entity tag of the representation of a resource in a content coding, as RFC 9110
+// requires the representations of different encodings to have different entity tags
+func encodedETag(tag, encoding string) string {
+	if !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+		return tag
+	}
+
+	return strings.TrimSuffix(tag, `"`) + "-" + encoding + `"`
+}
+
+// decodedETag returns the entity tag of the identity representation of an encoded one, so that conditional
+// requests match whatever representation the client has
+func decodedETag(tag string) string {
+	for _, encoding := range encodings {
+		if base, found := strings.CutSuffix(tag, "-"+encoding+`"`); found {
+			return base + `"`
+		}
+	}
+
+	return tag
+}
+
+// entityTags parses a comma-separated list of entity tags. Weak tags are returned with their W/ prefix.
+func entityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// ifMatch returns the precondition of the If-Match header of the request. A missing header or "*" allows any
+// version, otherwise the version must match one of the listed tags using the strong comparison of RFC 9110,
+// so weak tags never match. The tags of encoded representations match the version they encode.
+func ifMatch(r *http.Request) repo.Precondition {
+	header := r.Header.Get("If-Match")
+	if header == "" || strings.TrimSpace(header) == "*" {
+		return repo.AnyVersion
+	}
+
+	tags := entityTags(header)
+
+	return func(version int) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool {
+			return decodedETag(tag) == etag(version)
+		})
+	}
+}
+
+// notModified sets the entity tag of the resource and reports whether the If-None-Match header of the request
+// matches it, using the weak comparison of RFC 9110. If it does, a 304 response is written without a body. The tags
+// of encoded representations match the version they encode.
+func notModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	setETag(w, version)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	current := etag(version)
+	for _, tag := range entityTags(header) {
+		if tag == "*" || strings.TrimPrefix(decodedETag(tag), "W/") == current {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
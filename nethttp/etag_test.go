@@ -0,0 +1,167 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	// createTodo creates a todo item and returns its path and entity tag
+	createTodo := func(t *testing.T, ts *testServer) (string, string) {
+		t.Helper()
+
+		listID := ts.createList(t)
+		rec := ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", ts.adminToken, model.RandomTodoCreate())
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		return "/api/v1/lists/" + listID + "/todos/" + decode[model.Todo](t, rec).ID, rec.Header().Get("ETag")
+	}
+
+	t.Run("get carries a strong entity tag of the version", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		path, _ := createTodo(t, ts)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, path, ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+		assert.Equal(t, 1, decode[model.Todo](t, rec).Version)
+	})
+
+	t.Run("changes move the entity tag", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		path, tag := createTodo(t, ts)
+
+		// execute
+		putRec := ts.do(t, http.MethodPut, path, ts.adminToken, model.RandomTodoUpdate())
+		patchRec := ts.patch(t, path, ts.adminToken, patch.MergePatchMediaType, `{"completed":true}`)
+
+		// verify
+		assert.Equal(t, `"1"`, tag)
+		require.Equal(t, http.StatusOK, putRec.Code, putRec.Body.String())
+		assert.Equal(t, `"2"`, putRec.Header().Get("ETag"))
+		require.Equal(t, http.StatusOK, patchRec.Code, patchRec.Body.String())
+		assert.Equal(t, `"3"`, patchRec.Header().Get("ETag"))
+	})
+
+	t.Run("if-none-match", func(t *testing.T) {
+		ts := newTestServer(t)
+		path, _ := createTodo(t, ts)
+
+		tests := map[string]struct {
+			ifNoneMatch string
+			want        int
+		}{
+			"matching tag":         {`"1"`, http.StatusNotModified},
+			"matching weak tag":    {`W/"1"`, http.StatusNotModified},
+			"one of the tags":      {`"5", "1"`, http.StatusNotModified},
+			"any tag":              {`*`, http.StatusNotModified},
+			"stale tag":            {`"0"`, http.StatusOK},
+			"unrelated tag format": {`"abc"`, http.StatusOK},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.doWithHeader(t, http.MethodGet, path, ts.adminToken, nil, http.Header{"If-None-Match": {tt.ifNoneMatch}})
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code)
+				assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+				if tt.want == http.StatusNotModified {
+					assert.Empty(t, rec.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("if-match", func(t *testing.T) {
+		tests := map[string]struct {
+			ifMatch string
+			want    int
+		}{
+			"missing header":  {"", http.StatusOK},
+			"current tag":     {`"1"`, http.StatusOK},
+			"one of the tags": {`"0", "1"`, http.StatusOK},
+			"any tag":         {`*`, http.StatusOK},
+			"stale tag":       {`"0"`, http.StatusPreconditionFailed},
+			"weak tag":        {`W/"1"`, http.StatusPreconditionFailed},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// prepare
+				ts := newTestServer(t)
+				path, _ := createTodo(t, ts)
+				header := http.Header{}
+				if tt.ifMatch != "" {
+					header.Set("If-Match", tt.ifMatch)
+				}
+
+				// execute
+				rec := ts.doWithHeader(t, http.MethodPut, path, ts.adminToken, model.RandomTodoUpdate(), header)
+
+				// verify
+				require.Equal(t, tt.want, rec.Code, rec.Body.String())
+				if tt.want == http.StatusPreconditionFailed {
+					assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+					assert.Equal(t, model.ProblemTypePreconditionFailed, decode[model.Problem](t, rec).Type)
+				}
+			})
+		}
+	})
+
+	t.Run("second writer with the same tag is rejected", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		path, tag := createTodo(t, ts)
+		header := http.Header{"If-Match": {tag}}
+
+		// execute
+		firstRec := ts.doWithHeader(t, http.MethodPut, path, ts.adminToken, model.TodoUpdate{Title: "First"}, header)
+		secondRec := ts.doWithHeader(t, http.MethodPut, path, ts.adminToken, model.TodoUpdate{Title: "Second"}, header)
+
+		// verify
+		require.Equal(t, http.StatusOK, firstRec.Code, firstRec.Body.String())
+		assert.Equal(t, http.StatusPreconditionFailed, secondRec.Code)
+		assert.Equal(t, "First", decode[model.Todo](t, ts.do(t, http.MethodGet, path, ts.adminToken, nil)).Title)
+	})
+
+	t.Run("stale patch is rejected", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		path, _ := createTodo(t, ts)
+		header := http.Header{
+			"Content-Type": {patch.MergePatchMediaType},
+			"If-Match":     {`"0"`},
+		}
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPatch, path, ts.adminToken, `{"completed":true}`, header)
+
+		// verify
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+	})
+
+	t.Run("stale user deletion is rejected", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, _ := ts.createUser(t)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodDelete, "/api/v1/users/"+user.ID, ts.adminToken, nil, http.Header{"If-Match": {`"0"`}})
+
+		// verify
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+		assert.Equal(t, model.UserStatusActive, decode[model.User](t, ts.do(t, http.MethodGet, "/api/v1/users/"+user.ID, ts.adminToken, nil)).Status)
+	})
+}
@@ -0,0 +1,14 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": "ok"}); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
@@ -0,0 +1,20 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthRoute(t *testing.T) {
+	// prepare
+	ts := newTestServer(t)
+
+	// execute
+	rec := ts.do(t, http.MethodGet, "/api/v1/health", "", nil)
+
+	// verify
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"message":"ok"}`, rec.Body.String())
+}
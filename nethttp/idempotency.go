@@ -0,0 +1,258 @@
+package nethttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long responses are kept for retries by default
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength is the length of the longest idempotency key accepted
+const maxIdempotencyKeyLength = 255
+
+var (
+	errIdempotencyKeyInUse  = errors.New("idempotency key in use")
+	errIdempotencyKeyReused = errors.New("idempotency key reused")
+)
+
+// IdempotencyConfig configures how long the responses of requests with an Idempotency-Key header are kept
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed to retries of its request, DefaultIdempotencyTTL if zero
+	TTL time.Duration
+	// Now returns the current time, time.Now is used if it is nil
+	Now func() time.Time
+}
+
+// storedResponse is a response replayed to retries of its request
+type storedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// idempotencyEntry is the state of a key, response is nil while the first request is being processed
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	response    *storedResponse
+	expires     time.Time
+}
+
+// IdempotencyStore keeps the responses of requests with an Idempotency-Key header in memory, so that retries do
+// not repeat their effects. Expired responses are evicted, keeping the memory use proportional to the number of
+// requests within the TTL.
+type IdempotencyStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+func NewIdempotencyStore(cfg IdempotencyConfig) *IdempotencyStore {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return &IdempotencyStore{
+		ttl:       ttl,
+		now:       now,
+		entries:   make(map[string]*idempotencyEntry),
+		lastSweep: now(),
+	}
+}
+
+// start claims the key for a request with the given fingerprint. The stored response is returned if the request
+// was already processed, errIdempotencyKeyInUse if it is being processed, and errIdempotencyKeyReused if the key
+// was used for a different request.
+func (st *IdempotencyStore) start(key string, fingerprint [sha256.Size]byte) (*storedResponse, error) {
+	now := st.now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if now.Sub(st.lastSweep) >= st.ttl {
+		st.sweep(now)
+	}
+
+	e, ok := st.entries[key]
+	if ok && now.Before(e.expires) {
+		switch {
+		case e.fingerprint != fingerprint:
+			return nil, errIdempotencyKeyReused
+		case e.response == nil:
+			return nil, errIdempotencyKeyInUse
+		}
+
+		return e.response, nil
+	}
+
+	st.entries[key] = &idempotencyEntry{fingerprint: fingerprint, expires: now.Add(st.ttl)}
+
+	return nil, nil
+}
+
+// finish stores the response of the request which claimed the key, or releases the key if response is nil
+func (st *IdempotencyStore) finish(key string, response *storedResponse) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if response == nil {
+		delete(st.entries, key)
+		return
+	}
+
+	if e, ok := st.entries[key]; ok {
+		e.response = response
+		e.expires = st.now().Add(st.ttl)
+	}
+}
+
+// Len returns the number of keys kept in memory
+func (st *IdempotencyStore) Len() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return len(st.entries)
+}
+
+// sweep evicts the expired entries
+func (st *IdempotencyStore) sweep(now time.Time) {
+	for key, e := range st.entries {
+		if !now.Before(e.expires) {
+			delete(st.entries, key)
+		}
+	}
+	st.lastSweep = now
+}
+
+// validIdempotencyKey reports whether an idempotency key is short and only contains visible ASCII characters
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+
+	for _, c := range key {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// idempotent makes retries of the requests with an Idempotency-Key header safe. The first response to a key is
+// stored, and replayed to later requests of the same user with the same key, method, path and body. Reusing a key
+// for a different request is rejected with 422, and retrying while the first request is being processed with 409.
+// Server errors are not stored, so that the request can be retried. It must be wrapped by authenticate, as keys
+// are scoped to the logged-in user.
+func (s *Server) idempotent(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || s.idempotency == nil {
+			h(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf("The Idempotency-Key header must be 1 to %d visible ASCII characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		_, _ = fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+		_, _ = hash.Write(body)
+		var fingerprint [sha256.Size]byte
+		hash.Sum(fingerprint[:0])
+
+		key = loggedInUser(r).ID + ":" + key
+		stored, err := s.idempotency.start(key, fingerprint)
+		switch {
+		case errors.Is(err, errIdempotencyKeyReused):
+			writeError(w, r, http.StatusUnprocessableEntity, "The Idempotency-Key was used for a different request")
+			return
+		case errors.Is(err, errIdempotencyKeyInUse):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, http.StatusConflict, "A request with the same Idempotency-Key is being processed")
+			return
+		case stored != nil:
+			maps.Copy(w.Header(), stored.header)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.status)
+			_, _ = w.Write(stored.body)
+			return
+		}
+
+		iw := &idempotencyWriter{ResponseWriter: w, header: http.Header{}}
+		completed := false
+		// the key is released if the handler panics, as the response may be incomplete
+		defer func() {
+			if !completed || iw.status >= http.StatusInternalServerError {
+				s.idempotency.finish(key, nil)
+				return
+			}
+			s.idempotency.finish(key, &storedResponse{status: iw.status, header: iw.header, body: iw.body})
+		}()
+
+		h(iw, r)
+		if iw.status == 0 {
+			iw.WriteHeader(http.StatusOK)
+		}
+		completed = true
+	}
+}
+
+// idempotencyWriter records the response written by the handler. The handler sets its headers on a separate
+// header map, so that the headers set by the middleware, like the rate limit ones, are not replayed.
+type idempotencyWriter struct {
+	http.ResponseWriter
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (iw *idempotencyWriter) Header() http.Header {
+	return iw.header
+}
+
+func (iw *idempotencyWriter) WriteHeader(status int) {
+	if iw.status != 0 || status < http.StatusOK {
+		return
+	}
+	iw.status = status
+
+	maps.Copy(iw.ResponseWriter.Header(), iw.header)
+	iw.ResponseWriter.WriteHeader(status)
+}
+
+func (iw *idempotencyWriter) Write(b []byte) (int, error) {
+	if iw.status == 0 {
+		iw.WriteHeader(http.StatusOK)
+	}
+	iw.body = append(iw.body, b...)
+
+	return iw.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (iw *idempotencyWriter) Unwrap() http.ResponseWriter {
+	return iw.ResponseWriter
+}
@@ -0,0 +1,202 @@
+package nethttp_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRecorder records a response like httptest.ResponseRecorder, blocking the first write until released
+type blockingRecorder struct {
+	*httptest.ResponseRecorder
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (br *blockingRecorder) Write(b []byte) (int, error) {
+	if br.writing != nil {
+		close(br.writing)
+		br.writing = nil
+		<-br.release
+	}
+
+	return br.ResponseRecorder.Write(b)
+}
+
+func TestIdempotency(t *testing.T) {
+	// newIdempotencyServer returns a test server storing responses for an hour, and a list to create todo items in
+	newIdempotencyServer := func(t *testing.T, now func() time.Time) (*testServer, string) {
+		t.Helper()
+
+		ts := newTestServerWithConfig(t, nethttp.Config{
+			Idempotency: nethttp.NewIdempotencyStore(nethttp.IdempotencyConfig{TTL: time.Hour, Now: now}),
+		})
+		return ts, "/api/v1/lists/" + ts.createList(t) + "/todos"
+	}
+	key := func(k string) http.Header {
+		return http.Header{"Idempotency-Key": {k}}
+	}
+
+	t.Run("retries are replayed", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		retryRec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+
+		// verify
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, http.StatusCreated, retryRec.Code)
+		assert.Equal(t, "true", retryRec.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, rec.Header().Get("ETag"), retryRec.Header().Get("ETag"))
+		assert.Equal(t, "application/json", retryRec.Header().Get("Content-Type"))
+		assert.Equal(t, rec.Body.String(), retryRec.Body.String())
+		assert.Len(t, decode[[]model.Todo](t, ts.do(t, http.MethodGet, path, ts.adminToken, nil)), 1)
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false})
+		retryRec := ts.do(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false})
+
+		// verify
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, http.StatusCreated, retryRec.Code)
+		assert.Len(t, decode[[]model.Todo](t, ts.do(t, http.MethodGet, path, ts.adminToken, nil)), 2)
+	})
+
+	t.Run("reusing a key for a different request", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		bodyRec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Bread", "completed": false}, key("abc-1"))
+		pathRec := ts.doWithHeader(t, http.MethodPost, "/api/v1/lists/"+ts.createList(t)+"/todos", ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+
+		// verify
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, bodyRec.Code)
+		assert.Equal(t, "The Idempotency-Key was used for a different request", decode[model.Problem](t, bodyRec).Detail)
+		assert.Equal(t, http.StatusUnprocessableEntity, pathRec.Code)
+	})
+
+	t.Run("keys are scoped to the user", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+		_, token := ts.createUser(t, model.GroupProjectWrite, model.GroupProjectRead)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		otherRec := ts.doWithHeader(t, http.MethodPost, path, token, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+
+		// verify
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, http.StatusCreated, otherRec.Code)
+		assert.Empty(t, otherRec.Header().Get("Idempotent-Replayed"))
+		assert.NotEqual(t, decode[model.Todo](t, rec).ID, decode[model.Todo](t, otherRec).ID)
+	})
+
+	t.Run("responses expire", func(t *testing.T) {
+		// prepare
+		now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		ts, path := newIdempotencyServer(t, func() time.Time { return now })
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		now = now.Add(time.Hour)
+		retryRec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Bread", "completed": false}, key("abc-1"))
+
+		// verify
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, http.StatusCreated, retryRec.Code, "the key can be used again after the TTL")
+		assert.Empty(t, retryRec.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("errors of the client are replayed", func(t *testing.T) {
+		// prepare
+		ts, _ := newIdempotencyServer(t, nil)
+		path := "/api/v1/lists/01K02SD13A5YKWWZFV9AQP7H1X/todos"
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		retryRec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+
+		// verify
+		assert.Equal(t, rec.Code, retryRec.Code)
+		assert.Equal(t, "true", retryRec.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("not a key"))
+
+		// verify
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("concurrent retries are rejected", func(t *testing.T) {
+		// prepare
+		ts, path := newIdempotencyServer(t, nil)
+		newRequest := func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(`{"title":"Milk","completed":false}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+ts.adminToken)
+			req.Header.Set("Idempotency-Key", "abc-1")
+			return req
+		}
+		first := &blockingRecorder{ResponseRecorder: httptest.NewRecorder(), writing: make(chan struct{}), release: make(chan struct{})}
+		writing := first.writing
+		done := make(chan struct{})
+
+		// execute
+		go func() {
+			defer close(done)
+			ts.handler.ServeHTTP(first, newRequest())
+		}()
+		<-writing
+		concurrentRec := httptest.NewRecorder()
+		ts.handler.ServeHTTP(concurrentRec, newRequest())
+		close(first.release)
+		<-done
+		retryRec := httptest.NewRecorder()
+		ts.handler.ServeHTTP(retryRec, newRequest())
+
+		// verify
+		assert.Equal(t, http.StatusConflict, concurrentRec.Code)
+		assert.Equal(t, "1", concurrentRec.Header().Get("Retry-After"))
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, retryRec.Code)
+		assert.Equal(t, "true", retryRec.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, first.Body.String(), retryRec.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		path := "/api/v1/lists/" + ts.createList(t) + "/todos"
+
+		// execute
+		ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+		retryRec := ts.doWithHeader(t, http.MethodPost, path, ts.adminToken, map[string]any{"title": "Milk", "completed": false}, key("abc-1"))
+
+		// verify
+		assert.Equal(t, http.StatusCreated, retryRec.Code)
+		assert.Len(t, decode[[]model.Todo](t, ts.do(t, http.MethodGet, path, ts.adminToken, nil)), 2)
+	})
+}
@@ -0,0 +1,95 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+func (s *Server) handleListLists(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	lists, err := s.listService.List(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(lists); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	var lc model.ListCreate
+	if !decodeJSON(w, r, &lc) {
+		return
+	}
+	list, err := s.listService.Create(r.Context(), lc)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, list.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleGetList(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("listId")
+	list, err := s.listService.GetByID(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if notModified(w, r, list.Version) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateList(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("listId")
+	var lu model.ListUpdate
+	if !decodeJSON(w, r, &lu) {
+		return
+	}
+	list, err := s.listService.Update(r.Context(), id, ifMatch(r), lu)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, list.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handlePatchList(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("listId")
+	mediaType, body, ok := readPatch(w, r)
+	if !ok {
+		return
+	}
+	list, err := s.listService.Patch(r.Context(), id, ifMatch(r), applyPatch[model.List](mediaType, body))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, list.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
@@ -0,0 +1,124 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRoutes(t *testing.T) {
+	t.Run("create, get, list and update list", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, token := ts.createUser(t, model.GroupProjectRead, model.GroupProjectWrite)
+		lc := model.RandomListCreate()
+		lu := model.RandomListUpdate()
+
+		// execute
+		createRec := ts.do(t, http.MethodPost, "/api/v1/lists", token, lc)
+		require.Equal(t, http.StatusCreated, createRec.Code, createRec.Body.String())
+		created := decode[model.List](t, createRec)
+
+		getRec := ts.do(t, http.MethodGet, "/api/v1/lists/"+created.ID, token, nil)
+		listRec := ts.do(t, http.MethodGet, "/api/v1/lists", token, nil)
+		updateRec := ts.do(t, http.MethodPut, "/api/v1/lists/"+created.ID, token, lu)
+
+		// verify
+		assert.Equal(t, lc.Name, created.Name)
+		assert.Equal(t, lc.ProjectID, created.ProjectID)
+
+		require.Equal(t, http.StatusOK, getRec.Code)
+		assert.Equal(t, created, decode[model.List](t, getRec))
+
+		require.Equal(t, http.StatusOK, listRec.Code)
+		assert.Equal(t, []model.List{created}, decode[[]model.List](t, listRec))
+
+		require.Equal(t, http.StatusOK, updateRec.Code, updateRec.Body.String())
+		assert.Equal(t, lu.Name, decode[model.List](t, updateRec).Name)
+	})
+
+	t.Run("get unknown list", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/lists/"+model.RandomList().ID, ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("update unknown list", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/lists/"+model.RandomList().ID, ts.adminToken, model.RandomListUpdate())
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, model.ProblemTypeNotFound, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		created := decode[model.List](t, ts.do(t, http.MethodPost, "/api/v1/lists", ts.adminToken, model.RandomListCreate()))
+
+		tests := map[string]struct {
+			method string
+			path   string
+			body   any
+		}{
+			"create with malformed JSON": {http.MethodPost, "/api/v1/lists", "{"},
+			"create without project":     {http.MethodPost, "/api/v1/lists", model.ListCreate{Name: "name"}},
+			"update with malformed JSON": {http.MethodPut, "/api/v1/lists/" + created.ID, "{"},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, ts.adminToken, tt.body)
+
+				// verify
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			})
+		}
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, readToken := ts.createUser(t, model.GroupProjectRead)
+		_, noGroupToken := ts.createUser(t)
+		created := decode[model.List](t, ts.do(t, http.MethodPost, "/api/v1/lists", ts.adminToken, model.RandomListCreate()))
+
+		tests := map[string]struct {
+			method string
+			path   string
+			token  string
+			body   any
+			want   int
+		}{
+			"list without token":          {http.MethodGet, "/api/v1/lists", "", nil, http.StatusUnauthorized},
+			"list without group":          {http.MethodGet, "/api/v1/lists", noGroupToken, nil, http.StatusForbidden},
+			"list with read group":        {http.MethodGet, "/api/v1/lists", readToken, nil, http.StatusOK},
+			"get without group":           {http.MethodGet, "/api/v1/lists/" + created.ID, noGroupToken, nil, http.StatusForbidden},
+			"create with read group only": {http.MethodPost, "/api/v1/lists", readToken, model.RandomListCreate(), http.StatusForbidden},
+			"update with read group only": {http.MethodPut, "/api/v1/lists/" + created.ID, readToken, model.RandomListUpdate(), http.StatusForbidden},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, tt.token, tt.body)
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code)
+			})
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package nethttp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// maxRequestIDLength is the length of the longest request ID accepted from clients
+const maxRequestIDLength = 128
+
+type accessLogContextKey struct{}
+
+// accessLog collects the details of a request which are only known deep in the handler chain
+type accessLog struct {
+	userID string
+}
+
+// setLogUser records the ID of the user authenticated for the request in the access log
+func setLogUser(ctx context.Context, userID string) {
+	if al, ok := ctx.Value(accessLogContextKey{}).(*accessLog); ok {
+		al.userID = userID
+	}
+}
+
+// validRequestID reports whether a client-provided request ID is safe to log and echo: it must be short and only
+// contain letters, digits, dashes, underscores and dots.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// responseRecorder records the status and the size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += n
+
+	return n, err
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// logRequests assigns an ID to every request, or propagates the one in the X-Request-ID header, and returns it in
+// the X-Request-ID response header. A logger carrying the ID is stored in the request context for the handlers and
+// the services. Every request is logged once it is served.
+func (s *Server) logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = ulid.Make().String()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		logger := s.logger.With("requestId", requestID)
+		al := &accessLog{}
+
+		ctx := service.ContextWithLogger(r.Context(), logger)
+		ctx = context.WithValue(ctx, accessLogContextKey{}, al)
+
+		rr := &responseRecorder{ResponseWriter: w}
+		defer func() {
+			status := rr.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(ctx, level, "Request served",
+				slog.String("method", r.Method),
+				slog.String("route", s.route(r)),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", rr.bytes),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("userId", al.userID),
+				slog.String("ip", clientIP(r)),
+			)
+		}()
+
+		h.ServeHTTP(rr, r.WithContext(ctx))
+	})
+}
+
+// recoverPanics turns panicking handlers into 500 Internal Server Error responses, logging the panic with its stack
+// trace. If the response was already started it is cut short. http.ErrAbortHandler is passed on, as it is used to
+// abort responses deliberately.
+func recoverPanics(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rr, ok := w.(*responseRecorder)
+		if !ok {
+			rr = &responseRecorder{ResponseWriter: w}
+		}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			service.LoggerFromContext(r.Context()).Error("Panic serving request",
+				"panic", fmt.Sprint(v),
+				"stack", string(debug.Stack()),
+			)
+
+			if rr.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+
+			writeError(rr, r, http.StatusInternalServerError, "")
+		}()
+
+		h.ServeHTTP(rr, r)
+	})
+}
@@ -0,0 +1,174 @@
+package nethttp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoggedServer returns a test server logging JSON lines to the returned buffer
+func newLoggedServer(t *testing.T, middleware ...nethttp.Middleware) (*testServer, *bytes.Buffer) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	return newTestServerWithConfig(t, nethttp.Config{Logger: logger, Middleware: middleware}), buf
+}
+
+// logLines decodes the log lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		lines = append(lines, entry)
+	}
+
+	return lines
+}
+
+func TestRequestLogging(t *testing.T) {
+	t.Run("access log", func(t *testing.T) {
+		// prepare
+		ts, buf := newLoggedServer(t)
+		listID := model.RandomList().ID
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/lists/"+listID+"/todos", ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		requestID := rec.Header().Get("X-Request-ID")
+		assert.Len(t, requestID, 26)
+
+		lines := logLines(t, buf)
+		require.Len(t, lines, 1)
+		entry := lines[0]
+		assert.Equal(t, "Request served", entry["msg"])
+		assert.Equal(t, "INFO", entry["level"])
+		assert.Equal(t, requestID, entry["requestId"])
+		assert.Equal(t, http.MethodGet, entry["method"])
+		assert.Equal(t, "GET /lists/{listId}/todos", entry["route"])
+		assert.Equal(t, "/api/v1/lists/"+listID+"/todos", entry["path"])
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+		assert.Equal(t, float64(rec.Body.Len()), entry["bytes"])
+		assert.Contains(t, entry, "latency")
+		assert.Equal(t, ts.admin.ID, entry["userId"])
+	})
+
+	t.Run("anonymous and unknown routes", func(t *testing.T) {
+		// prepare
+		ts, buf := newLoggedServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/unknown", "", nil)
+
+		// verify
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		entry := logLines(t, buf)[0]
+		assert.Equal(t, "", entry["route"])
+		assert.Equal(t, "", entry["userId"])
+		assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	})
+
+	t.Run("request IDs", func(t *testing.T) {
+		tests := map[string]struct {
+			requestID string
+			propagate bool
+		}{
+			"propagated":       {"abc-123_x.y", true},
+			"invalid":          {"abc\n123", false},
+			"too long":         {strings.Repeat("a", 129), false},
+			"longest accepted": {strings.Repeat("a", 128), true},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// prepare
+				ts, buf := newLoggedServer(t)
+
+				// execute
+				rec := ts.doWithHeader(t, http.MethodGet, "/api/v1/health", "", nil, http.Header{"X-Request-ID": {tt.requestID}})
+
+				// verify
+				requestID := rec.Header().Get("X-Request-ID")
+				if tt.propagate {
+					assert.Equal(t, tt.requestID, requestID)
+				} else {
+					assert.Len(t, requestID, 26)
+				}
+				assert.Equal(t, requestID, logLines(t, buf)[0]["requestId"])
+			})
+		}
+	})
+
+	t.Run("the request logger is in the context", func(t *testing.T) {
+		// prepare
+		ts, buf := newLoggedServer(t, func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				service.LoggerFromContext(r.Context()).Info("Handling request")
+				h.ServeHTTP(w, r)
+			})
+		})
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/health", "", nil)
+
+		// verify
+		lines := logLines(t, buf)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "Handling request", lines[0]["msg"])
+		assert.Equal(t, rec.Header().Get("X-Request-ID"), lines[0]["requestId"])
+	})
+}
+
+func TestPanicRecovery(t *testing.T) {
+	// prepare
+	ts, buf := newLoggedServer(t, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/health" {
+				panic("boom")
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+
+	// execute
+	rec := ts.do(t, http.MethodGet, "/api/v1/health", "", nil)
+
+	// verify
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "boom", "panic details must not leak")
+	assert.Equal(t, http.StatusInternalServerError, decode[model.Problem](t, rec).Status)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Panic serving request", lines[0]["msg"])
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "boom", lines[0]["panic"])
+	assert.Contains(t, lines[0]["stack"], "goroutine")
+	assert.Equal(t, rec.Header().Get("X-Request-ID"), lines[0]["requestId"])
+	assert.Equal(t, float64(http.StatusInternalServerError), lines[1]["status"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+
+	t.Run("the server keeps serving", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/projects", ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
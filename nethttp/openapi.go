@@ -0,0 +1,57 @@
+package nethttp
+
+import (
+	"net/http"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// serverURL returns the URL the API is reached at by the client, so that the documents point to the server
+// actually serving them
+func (s *Server) serverURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + s.basePath
+}
+
+// writeOpenAPI writes the OpenAPI document in the format returned by encode
+func (s *Server) writeOpenAPI(w http.ResponseWriter, r *http.Request, contentType string, encode func(*openapi.Document) ([]byte, error)) {
+	doc, err := openapi.Load()
+	if err != nil {
+		service.LoggerFromContext(r.Context()).Error("Failed to load OpenAPI document", "err", err)
+		writeError(w, r, http.StatusInternalServerError, "Failed to load OpenAPI document")
+		return
+	}
+
+	content, err := encode(doc.WithServerURL(s.serverURL(r)))
+	if err != nil {
+		service.LoggerFromContext(r.Context()).Error("Failed to encode OpenAPI document", "err", err)
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode OpenAPI document")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+func (s *Server) handleOpenAPIYAML(w http.ResponseWriter, r *http.Request) {
+	s.writeOpenAPI(w, r, "application/yaml", (*openapi.Document).YAML)
+}
+
+func (s *Server) handleOpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	s.writeOpenAPI(w, r, "application/json", (*openapi.Document).JSON)
+}
+
+// handleDocs serves the page rendering the OpenAPI document. The page is self-contained, it only loads the
+// document, so the policy forbids everything else.
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openapi.DocsPage)
+}
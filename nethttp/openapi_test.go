@@ -0,0 +1,74 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/openapi.yaml", "", nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+		var doc struct {
+			Servers []struct {
+				URL string `yaml:"url"`
+			} `yaml:"servers"`
+		}
+		require.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &doc))
+		require.Len(t, doc.Servers, 1)
+		assert.Equal(t, "http://example.com/api/v1", doc.Servers[0].URL, "the server is the one the document was requested from")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/openapi.json", "", nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		doc := decode[map[string]any](t, rec)
+		assert.Equal(t, "3.0.3", doc["openapi"])
+		assert.Equal(t, []any{map[string]any{"url": "http://example.com/api/v1"}}, doc["servers"])
+		assert.Contains(t, doc["paths"], "/projects")
+	})
+
+	t.Run("docs", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/docs", "", nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "default-src 'none'")
+		assert.Contains(t, rec.Body.String(), `fetch("openapi.json")`)
+	})
+
+	t.Run("not served as legacy routes", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{LegacyRoutes: testLegacyRoutes})
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/openapi.json", "", nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
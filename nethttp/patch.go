@@ -0,0 +1,59 @@
+package nethttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/patch"
+)
+
+// acceptPatch lists the patch formats supported by the PATCH endpoints
+var acceptPatch = strings.Join([]string{patch.MergePatchMediaType, patch.JSONPatchMediaType}, ", ")
+
+// readPatch reads the patch document of the request and returns it with its media type.
+// If the patch can not be read, the error response is written and false is returned.
+func readPatch(w http.ResponseWriter, r *http.Request) (string, []byte, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != patch.MergePatchMediaType && mediaType != patch.JSONPatchMediaType) {
+		w.Header().Set("Accept-Patch", acceptPatch)
+		writeError(w, r, http.StatusUnsupportedMediaType, "Supported patch formats: "+acceptPatch)
+		return "", nil, false
+	}
+
+	body, ok := readBody(w, r)
+	if !ok {
+		return "", nil, false
+	}
+
+	return mediaType, body, true
+}
+
+// applyPatch returns a function applying the patch document to the JSON representation of an entity.
+// The patched document must not contain fields unknown to the entity.
+func applyPatch[T any](mediaType string, body []byte) func(T) (T, error) {
+	return func(entity T) (T, error) {
+		var patched T
+
+		doc, err := json.Marshal(entity)
+		if err != nil {
+			return patched, err
+		}
+
+		doc, err = patch.Apply(mediaType, doc, body)
+		if err != nil {
+			return patched, err
+		}
+
+		d := json.NewDecoder(bytes.NewReader(doc))
+		d.DisallowUnknownFields()
+		if err := d.Decode(&patched); err != nil {
+			return patched, fmt.Errorf("%w: the patched document does not match the resource, err: %w", patch.ErrInvalidPatch, err)
+		}
+
+		return patched, nil
+	}
+}
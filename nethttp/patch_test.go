@@ -0,0 +1,124 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	_, readToken := ts.createUser(t, model.GroupProjectRead)
+
+	newProject := func(t *testing.T) model.Project {
+		rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.ProjectCreate{Name: "Name", Description: "Description"})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		return decode[model.Project](t, rec)
+	}
+
+	t.Run("merge patch clears the description", func(t *testing.T) {
+		// prepare
+		project := newProject(t)
+
+		// execute
+		rec := ts.patch(t, "/api/v1/projects/"+project.ID, ts.adminToken, patch.MergePatchMediaType, `{"description":null}`)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		got := decode[model.Project](t, rec)
+		assert.Equal(t, "Name", got.Name)
+		assert.Empty(t, got.Description)
+	})
+
+	t.Run("json patch", func(t *testing.T) {
+		// prepare
+		project := newProject(t)
+
+		// execute
+		rec := ts.patch(t, "/api/v1/projects/"+project.ID, ts.adminToken, patch.JSONPatchMediaType,
+			`[{"op":"test","path":"/name","value":"Name"},{"op":"replace","path":"/name","value":"Patched"}]`)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		got := decode[model.Project](t, rec)
+		assert.Equal(t, "Patched", got.Name)
+		assert.Equal(t, "Description", got.Description)
+	})
+
+	t.Run("list and todo", func(t *testing.T) {
+		// prepare
+		list := decode[model.List](t, ts.do(t, http.MethodPost, "/api/v1/lists", ts.adminToken, model.RandomListCreate()))
+		tc := model.RandomTodoCreate()
+		tc.Completed = false
+		todo := decode[model.Todo](t, ts.do(t, http.MethodPost, "/api/v1/lists/"+list.ID+"/todos", ts.adminToken, tc))
+
+		// execute
+		listRec := ts.patch(t, "/api/v1/lists/"+list.ID, ts.adminToken, patch.MergePatchMediaType, `{"name":"Patched"}`)
+		todoRec := ts.patch(t, "/api/v1/lists/"+list.ID+"/todos/"+todo.ID, ts.adminToken, patch.JSONPatchMediaType, `[{"op":"replace","path":"/completed","value":true}]`)
+
+		// verify
+		require.Equal(t, http.StatusOK, listRec.Code, listRec.Body.String())
+		assert.Equal(t, "Patched", decode[model.List](t, listRec).Name)
+		require.Equal(t, http.StatusOK, todoRec.Code, todoRec.Body.String())
+		assert.True(t, decode[model.Todo](t, todoRec).Completed)
+		assert.Equal(t, todo.Title, decode[model.Todo](t, todoRec).Title)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		// prepare
+		project := newProject(t)
+		path := "/api/v1/projects/" + project.ID
+
+		tests := map[string]struct {
+			path      string
+			token     string
+			mediaType string
+			body      string
+			want      int
+			wantType  string
+		}{
+			"unsupported media type": {path, ts.adminToken, "application/json", `{"name":"x"}`, http.StatusUnsupportedMediaType, model.ProblemTypeDefault},
+			"malformed patch":        {path, ts.adminToken, patch.MergePatchMediaType, `{"name":`, http.StatusBadRequest, model.ProblemTypeDefault},
+			"invalid result":         {path, ts.adminToken, patch.MergePatchMediaType, `{"name":null}`, http.StatusBadRequest, model.ProblemTypeValidation},
+			"wrong field type":       {path, ts.adminToken, patch.MergePatchMediaType, `{"name":5}`, http.StatusBadRequest, model.ProblemTypeValidation},
+			"unknown field":          {path, ts.adminToken, patch.MergePatchMediaType, `{"owner":"me"}`, http.StatusBadRequest, model.ProblemTypeValidation},
+			"read-only field":        {path, ts.adminToken, patch.MergePatchMediaType, `{"id":"01K02SD13A5YKWWZFV9AQP7H1X"}`, http.StatusBadRequest, model.ProblemTypeValidation},
+			"failed test":            {path, ts.adminToken, patch.JSONPatchMediaType, `[{"op":"test","path":"/name","value":"Other"}]`, http.StatusConflict, model.ProblemTypeConflict},
+			"missing path":           {path, ts.adminToken, patch.JSONPatchMediaType, `[{"op":"remove","path":"/owner"}]`, http.StatusConflict, model.ProblemTypeConflict},
+			"unknown project":        {"/api/v1/projects/01K02SD13A5YKWWZFV9AQP7H1X", ts.adminToken, patch.MergePatchMediaType, `{}`, http.StatusNotFound, model.ProblemTypeNotFound},
+			"read group only":        {path, readToken, patch.MergePatchMediaType, `{"name":"x"}`, http.StatusForbidden, model.ProblemTypeForbidden},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.patch(t, tt.path, tt.token, tt.mediaType, tt.body)
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+				assert.Equal(t, tt.wantType, decode[model.Problem](t, rec).Type)
+			})
+		}
+
+		got := decode[model.Project](t, ts.do(t, http.MethodGet, path, ts.adminToken, nil))
+		assert.Equal(t, project, got, "failed patches must not change the project")
+	})
+
+	t.Run("unsupported media type advertises the supported ones", func(t *testing.T) {
+		// prepare
+		project := newProject(t)
+
+		// execute
+		rec := ts.patch(t, "/api/v1/projects/"+project.ID, ts.adminToken, "application/json", `{}`)
+
+		// verify
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		assert.Contains(t, rec.Header().Get("Accept-Patch"), patch.MergePatchMediaType)
+		assert.Contains(t, rec.Header().Get("Accept-Patch"), patch.JSONPatchMediaType)
+	})
+}
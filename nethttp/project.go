@@ -0,0 +1,95 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	projects, err := s.projectService.List(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(projects); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var pc model.ProjectCreate
+	if !decodeJSON(w, r, &pc) {
+		return
+	}
+	project, err := s.projectService.Create(r.Context(), pc)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, project.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("projectId")
+	project, err := s.projectService.GetByID(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if notModified(w, r, project.Version) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("projectId")
+	var pu model.ProjectUpdate
+	if !decodeJSON(w, r, &pu) {
+		return
+	}
+	project, err := s.projectService.Update(r.Context(), id, ifMatch(r), pu)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, project.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handlePatchProject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("projectId")
+	mediaType, body, ok := readPatch(w, r)
+	if !ok {
+		return
+	}
+	project, err := s.projectService.Patch(r.Context(), id, ifMatch(r), applyPatch[model.Project](mediaType, body))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, project.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
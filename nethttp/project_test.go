@@ -0,0 +1,163 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRoutes(t *testing.T) {
+	t.Run("create, get, list and update project", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, token := ts.createUser(t, model.GroupProjectRead, model.GroupProjectWrite)
+		pc := model.RandomProjectCreate()
+		pu := model.RandomProjectUpdate()
+
+		// execute
+		createRec := ts.do(t, http.MethodPost, "/api/v1/projects", token, pc)
+		require.Equal(t, http.StatusCreated, createRec.Code, createRec.Body.String())
+		created := decode[model.Project](t, createRec)
+
+		getRec := ts.do(t, http.MethodGet, "/api/v1/projects/"+created.ID, token, nil)
+		listRec := ts.do(t, http.MethodGet, "/api/v1/projects", token, nil)
+		updateRec := ts.do(t, http.MethodPut, "/api/v1/projects/"+created.ID, token, pu)
+
+		// verify
+		assert.Equal(t, pc.Name, created.Name)
+		assert.Equal(t, "application/json", createRec.Header().Get("Content-Type"))
+
+		require.Equal(t, http.StatusOK, getRec.Code)
+		assert.Equal(t, created, decode[model.Project](t, getRec))
+
+		require.Equal(t, http.StatusOK, listRec.Code)
+		assert.Equal(t, []model.Project{created}, decode[[]model.Project](t, listRec))
+
+		require.Equal(t, http.StatusOK, updateRec.Code, updateRec.Body.String())
+		assert.Equal(t, pu.Name, decode[model.Project](t, updateRec).Name)
+	})
+
+	t.Run("update replaces the whole project", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		created := decode[model.Project](t, ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.RandomProjectCreate()))
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/projects/"+created.ID, ts.adminToken, `{"name":"Replaced"}`)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, model.Project{ID: created.ID, Name: "Replaced", Version: 2}, decode[model.Project](t, rec))
+	})
+
+	t.Run("get unknown project", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/projects/"+model.RandomProject().ID, ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, model.ProblemTypeNotFound, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("update unknown project", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/projects/"+model.RandomProject().ID, ts.adminToken, model.RandomProjectUpdate())
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, model.ProblemTypeNotFound, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		created := decode[model.Project](t, ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.RandomProjectCreate()))
+
+		tests := map[string]struct {
+			method string
+			path   string
+			body   any
+		}{
+			"create with malformed JSON": {http.MethodPost, "/api/v1/projects", "{"},
+			"create without name":        {http.MethodPost, "/api/v1/projects", model.ProjectCreate{}},
+			"update with malformed JSON": {http.MethodPut, "/api/v1/projects/" + created.ID, "{"},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, ts.adminToken, tt.body)
+
+				// verify
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			})
+		}
+	})
+
+	t.Run("validation problem lists invalid fields", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.ProjectCreate{Description: strings.Repeat("a", 256)})
+
+		// verify
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		problem := decode[model.Problem](t, rec)
+		assert.Equal(t, model.ProblemTypeValidation, problem.Type)
+		assert.Equal(t, "/api/v1/projects", problem.Instance)
+		assert.Equal(t, []model.InvalidParam{
+			{Name: "description", Rule: "max", Params: []string{"255"}, Reason: "must be at most 255 characters long"},
+			{Name: "name", Rule: "min", Params: []string{"1"}, Reason: "must be at least 1 characters long"},
+		}, problem.InvalidParams)
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, readToken := ts.createUser(t, model.GroupProjectRead)
+		_, noGroupToken := ts.createUser(t)
+		created := decode[model.Project](t, ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.RandomProjectCreate()))
+
+		tests := map[string]struct {
+			method string
+			path   string
+			token  string
+			body   any
+			want   int
+		}{
+			"list without token":          {http.MethodGet, "/api/v1/projects", "", nil, http.StatusUnauthorized},
+			"list with invalid token":     {http.MethodGet, "/api/v1/projects", "invalid", nil, http.StatusUnauthorized},
+			"list without group":          {http.MethodGet, "/api/v1/projects", noGroupToken, nil, http.StatusForbidden},
+			"list with read group":        {http.MethodGet, "/api/v1/projects", readToken, nil, http.StatusOK},
+			"get without group":           {http.MethodGet, "/api/v1/projects/" + created.ID, noGroupToken, nil, http.StatusForbidden},
+			"create without token":        {http.MethodPost, "/api/v1/projects", "", model.RandomProjectCreate(), http.StatusUnauthorized},
+			"create with read group only": {http.MethodPost, "/api/v1/projects", readToken, model.RandomProjectCreate(), http.StatusForbidden},
+			"update with read group only": {http.MethodPut, "/api/v1/projects/" + created.ID, readToken, model.RandomProjectUpdate(), http.StatusForbidden},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, tt.token, tt.body)
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code)
+				if tt.want >= http.StatusBadRequest {
+					assert.Equal(t, tt.want, decode[model.Problem](t, rec).Status)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package nethttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+)
+
+// parseQuery reads the query of a collection request: q is the search text, sort is a comma-separated list of
+// fields, every other parameter is a field which must equal the given value. Each parameter may only be given
+// once. The repos decide which fields are allowed.
+func parseQuery(r *http.Request) (repo.Query, error) {
+	var query repo.Query
+
+	for name, values := range r.URL.Query() {
+		if len(values) > 1 {
+			return repo.Query{}, &repo.QueryError{
+				Param:  name,
+				Rule:   "max",
+				Params: []string{"1"},
+				Reason: "must be given at most once",
+			}
+		}
+
+		switch name {
+		case "q":
+			query.Search = values[0]
+		case "sort":
+			for _, field := range strings.Split(values[0], ",") {
+				query.Sort = append(query.Sort, strings.TrimSpace(field))
+			}
+		default:
+			if query.Filters == nil {
+				query.Filters = map[string]string{}
+			}
+			query.Filters[name] = values[0]
+		}
+	}
+
+	return query, nil
+}
+
+// newQueryProblem returns a validation problem describing the invalid query parameter
+func newQueryProblem(err *repo.QueryError) model.Problem {
+	return model.Problem{
+		Type:   model.ProblemTypeValidation,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Detail: "The query is invalid, see invalidParams for details",
+		InvalidParams: []model.InvalidParam{{
+			Name:   err.Param,
+			Rule:   err.Rule,
+			Params: err.Params,
+			Reason: err.Reason,
+		}},
+	}
+}
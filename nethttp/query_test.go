@@ -0,0 +1,106 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionQuery(t *testing.T) {
+	ts := newTestServer(t)
+	listID := ts.createList(t)
+
+	for _, tc := range []model.TodoCreate{
+		{Title: "Buy milk", Completed: true},
+		{Title: "Call mom", Description: "Ask about the milk"},
+		{Title: "Buy bread"},
+	} {
+		rec := ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", ts.adminToken, tc)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec := ts.do(t, http.MethodPost, "/api/v1/lists/"+ts.createList(t)+"/todos", ts.adminToken, model.TodoCreate{Title: "Buy milk elsewhere"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	for _, name := range []string{"Beta", "Alpha", "Gamma"} {
+		rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.ProjectCreate{Name: name})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	t.Run("todos", func(t *testing.T) {
+		tests := map[string]struct {
+			query string
+			want  []string
+		}{
+			"only the todos of the list": {"", []string{"Buy milk", "Call mom", "Buy bread"}},
+			"completed":                  {"?completed=true", []string{"Buy milk"}},
+			"search":                     {"?q=MILK", []string{"Buy milk", "Call mom"}},
+			"search and filter":          {"?q=buy&completed=false", []string{"Buy bread"}},
+			"sort":                       {"?sort=-completed,title", []string{"Buy milk", "Buy bread", "Call mom"}},
+			"list in the path wins":      {"?listId=" + model.RandomList().ID, []string{"Buy milk", "Call mom", "Buy bread"}},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, http.MethodGet, "/api/v1/lists/"+listID+"/todos"+tt.query, ts.adminToken, nil)
+
+				// verify
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+				var titles []string
+				for _, todo := range decode[[]model.Todo](t, rec) {
+					titles = append(titles, todo.Title)
+				}
+				assert.Equal(t, tt.want, titles)
+			})
+		}
+	})
+
+	t.Run("sorted projects", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/projects?sort=-name", ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var names []string
+		for _, project := range decode[[]model.Project](t, rec) {
+			names = append(names, project.Name)
+		}
+		assert.Equal(t, []string{"Gamma", "Beta", "Alpha"}, names)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		tests := map[string]struct {
+			path      string
+			wantParam string
+			wantRule  string
+		}{
+			"unknown filter field":  {"/api/v1/projects?colour=red", "colour", "oneof"},
+			"unknown sort field":    {"/api/v1/lists?sort=name,-colour", "sort", "pattern"},
+			"malformed value":       {"/api/v1/lists/" + listID + "/todos?completed=maybe", "completed", "type"},
+			"repeated parameter":    {"/api/v1/projects?name=a&name=b", "name", "max"},
+			"trailing sort comma":   {"/api/v1/projects?sort=name,", "sort", "pattern"},
+			"field of another type": {"/api/v1/projects?completed=true", "completed", "oneof"},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, http.MethodGet, tt.path, ts.adminToken, nil)
+
+				// verify
+				require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+				assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+				problem := decode[model.Problem](t, rec)
+				assert.Equal(t, model.ProblemTypeValidation, problem.Type)
+				require.Len(t, problem.InvalidParams, 1)
+				assert.Equal(t, tt.wantParam, problem.InvalidParams[0].Name)
+				assert.Equal(t, tt.wantRule, problem.InvalidParams[0].Rule)
+				assert.NotEmpty(t, problem.InvalidParams[0].Reason)
+			})
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package nethttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+var ErrInvalidRateLimitConfig = errors.New("invalid rate limit configuration")
+
+// Quota allows Limit requests per Period, both must be positive. Unused capacity accumulates up to Limit,
+// allowing short bursts.
+type Quota struct {
+	Limit  int
+	Period time.Duration
+}
+
+// validate reports quotas which would never refill or allow any request
+func (q Quota) validate(name string) error {
+	if q.Limit <= 0 || q.Period <= 0 {
+		return fmt.Errorf("%w: quota: %s, limit: %d, period: %s", ErrInvalidRateLimitConfig, name, q.Limit, q.Period)
+	}
+
+	return nil
+}
+
+// rate returns the number of tokens the quota refills per second
+func (q Quota) rate() float64 {
+	return float64(q.Limit) / q.Period.Seconds()
+}
+
+// RateLimitConfig configures the token bucket rate limiter. Clients are identified by their user ID if they send
+// a valid bearer token, by their API key if APIKeyQuota knows it, and by their IP address otherwise.
+type RateLimitConfig struct {
+	// Anonymous is the quota of clients identified by their IP address
+	Anonymous Quota
+	// Authenticated is the quota of logged-in users without a group quota
+	Authenticated Quota
+	// Groups maps groups to the quota of their members, users get the highest quota of their groups
+	Groups map[string]Quota
+	// APIKeyQuota returns the quota of an API key sent in the X-API-Key header, and false for unknown keys.
+	// API keys are ignored if it is nil, as they are not authenticated by the server. Keys with invalid quotas
+	// are treated as unknown ones.
+	APIKeyQuota func(key string) (Quota, bool)
+	// Costs maps routes without the base path, like "POST /logins", to the number of tokens a request takes,
+	// 1 by default. Legacy routes cost the same as the ones replacing them.
+	Costs map[string]int
+	// Now returns the current time, time.Now is used if it is nil
+	Now func() time.Time
+}
+
+// DefaultRateLimitConfig returns the rate limits of the API. Logins are expensive to make password guessing slow.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Anonymous:     Quota{Limit: 60, Period: time.Minute},
+		Authenticated: Quota{Limit: 300, Period: time.Minute},
+		Groups: map[string]Quota{
+			model.GroupAdmin: {Limit: 1000, Period: time.Minute},
+		},
+		Costs: map[string]int{
+			"POST /logins": 10,
+			"GET /search":  5,
+		},
+	}
+}
+
+// bucket is the token bucket of a client, full again period after its last update
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// RateLimiter keeps a token bucket per client. Buckets which refilled completely are indistinguishable from new
+// ones, so they are evicted to keep the memory use proportional to the number of recently active clients.
+type RateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// sweepEvery is the longest period of the configured quotas. The periods of API key quotas are not known in
+	// advance, so their buckets are kept until their own period passes.
+	sweepEvery time.Duration
+}
+
+// NewRateLimiter returns a rate limiter, or ErrInvalidRateLimitConfig if a quota has no positive limit and period,
+// or a route has a negative cost
+func NewRateLimiter(cfg RateLimitConfig) (*RateLimiter, error) {
+	if err := cfg.Anonymous.validate("anonymous"); err != nil {
+		return nil, err
+	}
+	if err := cfg.Authenticated.validate("authenticated"); err != nil {
+		return nil, err
+	}
+	for group, q := range cfg.Groups {
+		if err := q.validate("group " + group); err != nil {
+			return nil, err
+		}
+	}
+	for route, cost := range cfg.Costs {
+		if cost < 0 {
+			return nil, fmt.Errorf("%w: route: %s, cost: %d", ErrInvalidRateLimitConfig, route, cost)
+		}
+	}
+
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	sweepEvery := max(cfg.Anonymous.Period, cfg.Authenticated.Period)
+	for _, q := range cfg.Groups {
+		sweepEvery = max(sweepEvery, q.Period)
+	}
+
+	return &RateLimiter{
+		cfg:        cfg,
+		now:        now,
+		buckets:    make(map[string]*bucket),
+		lastSweep:  now(),
+		sweepEvery: sweepEvery,
+	}, nil
+}
+
+// rateLimitResult describes the state of a bucket after taking tokens from it
+type rateLimitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// take takes cost tokens from the bucket of the client if it has enough of them. Costs above the limit of the
+// quota are capped, so that every request can succeed eventually.
+func (l *RateLimiter) take(key string, quota Quota, cost int) rateLimitResult {
+	now := l.now()
+	cost = min(cost, quota.Limit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.sweepEvery {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(quota.Limit), updated: now}
+		l.buckets[key] = b
+	}
+
+	rate := quota.rate()
+	b.tokens = min(float64(quota.Limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	b.period = quota.Period
+
+	result := rateLimitResult{limit: quota.Limit}
+	if b.tokens >= float64(cost) {
+		b.tokens -= float64(cost)
+		result.allowed = true
+	} else {
+		result.retryAfter = seconds((float64(cost) - b.tokens) / rate)
+	}
+
+	result.remaining = int(math.Floor(b.tokens))
+	result.reset = seconds((float64(quota.Limit) - b.tokens) / rate)
+
+	return result
+}
+
+// Len returns the number of buckets kept in memory
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// sweep evicts the buckets which had enough time to refill completely
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= b.period {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// seconds converts seconds to a duration, rounded up to whole seconds
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// client returns the key of the bucket of the request and the quota of the client
+func (s *Server) client(r *http.Request) (string, Quota) {
+	if liu, err := bearerUser(r); err == nil {
+		return "user:" + liu.ID, s.userQuota(liu)
+	}
+
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" && s.rateLimiter.cfg.APIKeyQuota != nil {
+		if quota, ok := s.rateLimiter.cfg.APIKeyQuota(apiKey); ok {
+			// The key is hashed, so that it is not kept in memory in plain text
+			sum := sha256.Sum256([]byte(apiKey))
+			key := "key:" + hex.EncodeToString(sum[:])
+
+			err := quota.validate(key)
+			if err == nil {
+				return key, quota
+			}
+			service.LoggerFromContext(r.Context()).Error("Ignoring API key with invalid quota", "err", err)
+		}
+	}
+
+	return "ip:" + clientIP(r), s.rateLimiter.cfg.Anonymous
+}
+
+// userQuota returns the highest quota of the groups of the user, or the default quota of logged-in users
+func (s *Server) userQuota(liu model.LoggedInUser) Quota {
+	quota := s.rateLimiter.cfg.Authenticated
+	for _, group := range liu.Groups {
+		if q, ok := s.rateLimiter.cfg.Groups[group]; ok && q.rate() > quota.rate() {
+			quota = q
+		}
+	}
+
+	return quota
+}
+
+// rateLimit rejects the requests of clients who ran out of their quota with 429 Too Many Requests. The state of
+// the bucket of the client is reported in the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset
+// headers, the latter being the number of seconds until the bucket is full again.
+func (s *Server) rateLimit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost, ok := s.rateLimiter.cfg.Costs[s.route(r)]
+		if !ok {
+			cost = 1
+		}
+
+		// The bearer token is resolved once, for both the rate limiter and the authenticated handlers
+		r = s.withBearerUser(r)
+		key, quota := s.client(r)
+		result := s.rateLimiter.take(key, quota, cost)
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(result.reset.Seconds())))
+
+		if !result.allowed {
+			retryAfter := strconv.Itoa(int(result.retryAfter.Seconds()))
+			w.Header().Set("Retry-After", retryAfter)
+			writeError(w, r, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded, retry in %s seconds", retryAfter))
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
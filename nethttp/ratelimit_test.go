@@ -0,0 +1,290 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock which only moves when told to
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newRateLimitedServer returns a test server with a small anonymous quota of 3 requests per minute
+func newRateLimitedServer(t *testing.T, cfg nethttp.RateLimitConfig) (*testServer, *nethttp.RateLimiter, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg.Now = clock.Now
+	if cfg.Anonymous == (nethttp.Quota{}) {
+		cfg.Anonymous = nethttp.Quota{Limit: 3, Period: time.Minute}
+	}
+	if cfg.Authenticated == (nethttp.Quota{}) {
+		cfg.Authenticated = nethttp.Quota{Limit: 5, Period: time.Minute}
+	}
+
+	limiter, err := nethttp.NewRateLimiter(cfg)
+	require.NoError(t, err)
+
+	return newTestServerWithConfig(t, nethttp.Config{RateLimiter: limiter}), limiter, clock
+}
+
+// fromIP sends an anonymous request from the given IP address
+func (ts *testServer) fromIP(ip, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	for key, values := range header {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("headers report the state of the bucket", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+
+		for i, wantRemaining := range []string{"2", "1", "0"} {
+			// execute
+			rec := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil)
+
+			// verify
+			require.Equal(t, http.StatusOK, rec.Code, "request %d", i)
+			assert.Equal(t, "3", rec.Header().Get("X-RateLimit-Limit"))
+			assert.Equal(t, wantRemaining, rec.Header().Get("X-RateLimit-Remaining"))
+			assert.Equal(t, strconv.Itoa((i+1)*20), rec.Header().Get("X-RateLimit-Reset"))
+		}
+	})
+
+	t.Run("exhausted quota", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+		for range 3 {
+			require.Equal(t, http.StatusOK, ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil).Code)
+		}
+
+		// execute
+		rec := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil)
+
+		// verify
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "20", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, model.ProblemTypeRateLimited, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("tokens are refilled over time", func(t *testing.T) {
+		// prepare
+		ts, _, clock := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+		for range 3 {
+			require.Equal(t, http.StatusOK, ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil).Code)
+		}
+
+		// execute
+		clock.Advance(20 * time.Second)
+		first := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil)
+		second := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil)
+
+		// verify
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	})
+
+	t.Run("clients have separate buckets", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+		for range 3 {
+			require.Equal(t, http.StatusOK, ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil).Code)
+		}
+		_, token := ts.createUser(t)
+
+		// execute
+		otherIP := ts.fromIP("10.0.0.2", http.MethodGet, "/api/v1/health", nil)
+		user := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"Authorization": {"Bearer " + token}})
+
+		// verify
+		assert.Equal(t, http.StatusOK, otherIP.Code)
+		assert.Equal(t, http.StatusOK, user.Code)
+		assert.Equal(t, "5", user.Header().Get("X-RateLimit-Limit"), "logged-in users have their own quota")
+	})
+
+	t.Run("invalid tokens are limited by IP", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+
+		// execute
+		rec := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/projects", http.Header{"Authorization": {"Bearer invalid"}})
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("group quotas", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{
+			Groups: map[string]nethttp.Quota{
+				model.GroupProjectRead: {Limit: 10, Period: time.Minute},
+				model.GroupAdmin:       {Limit: 100, Period: time.Minute},
+			},
+		})
+		_, readerToken := ts.createUser(t, model.GroupProjectRead)
+		_, adminToken := ts.createUser(t, model.GroupProjectRead, model.GroupAdmin)
+
+		// execute
+		readerRec := ts.do(t, http.MethodGet, "/api/v1/health", readerToken, nil)
+		adminRec := ts.do(t, http.MethodGet, "/api/v1/health", adminToken, nil)
+
+		// verify
+		assert.Equal(t, "10", readerRec.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "100", adminRec.Header().Get("X-RateLimit-Limit"), "the highest quota of the groups applies")
+	})
+
+	t.Run("route costs", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{
+			Anonymous: nethttp.Quota{Limit: 10, Period: time.Minute},
+			Costs:     map[string]int{"POST /logins": 4, "GET /health": 100},
+		})
+
+		// execute
+		login := ts.fromIP("10.0.0.1", http.MethodPost, "/api/v1/logins", nil)
+		health := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", nil)
+
+		// verify
+		assert.Equal(t, http.StatusBadRequest, login.Code)
+		assert.Equal(t, "6", login.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, http.StatusTooManyRequests, health.Code, "costs above the limit take the whole bucket")
+		assert.Equal(t, "24", health.Header().Get("Retry-After"))
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{
+			APIKeyQuota: func(key string) (nethttp.Quota, bool) {
+				return nethttp.Quota{Limit: 50, Period: time.Minute}, key == "known-key"
+			},
+		})
+
+		// execute
+		known := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"known-key"}})
+		unknown := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"unknown-key"}})
+
+		// verify
+		assert.Equal(t, "50", known.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "3", unknown.Header().Get("X-RateLimit-Limit"), "unknown keys are limited by IP")
+	})
+
+	t.Run("api keys with invalid quotas are limited by IP", func(t *testing.T) {
+		// prepare
+		ts, _, _ := newRateLimitedServer(t, nethttp.RateLimitConfig{
+			APIKeyQuota: func(key string) (nethttp.Quota, bool) {
+				return nethttp.Quota{Limit: 50}, true
+			},
+		})
+
+		// execute
+		rec := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"known-key"}})
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "20", rec.Header().Get("X-RateLimit-Reset"))
+	})
+
+	t.Run("idle buckets are evicted", func(t *testing.T) {
+		// prepare
+		ts, limiter, clock := newRateLimitedServer(t, nethttp.RateLimitConfig{})
+		for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+			ts.fromIP(ip, http.MethodGet, "/api/v1/health", nil)
+		}
+		require.Equal(t, 3, limiter.Len())
+
+		// execute
+		clock.Advance(time.Minute)
+		ts.fromIP("10.0.0.4", http.MethodGet, "/api/v1/health", nil)
+
+		// verify
+		assert.Equal(t, 1, limiter.Len())
+	})
+	t.Run("api key buckets are kept for their own period", func(t *testing.T) {
+		// prepare
+		ts, limiter, clock := newRateLimitedServer(t, nethttp.RateLimitConfig{
+			APIKeyQuota: func(key string) (nethttp.Quota, bool) {
+				return nethttp.Quota{Limit: 2, Period: time.Hour}, key == "known-key"
+			},
+		})
+		ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"known-key"}})
+		ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"known-key"}})
+
+		// execute
+		clock.Advance(time.Minute)
+		ts.fromIP("10.0.0.2", http.MethodGet, "/api/v1/health", nil)
+		rec := ts.fromIP("10.0.0.1", http.MethodGet, "/api/v1/health", http.Header{"X-API-Key": {"known-key"}})
+
+		// verify
+		assert.Equal(t, 2, limiter.Len())
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "the bucket of the key is not refilled by the eviction")
+	})
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	valid := nethttp.Quota{Limit: 1, Period: time.Minute}
+
+	tests := map[string]nethttp.RateLimitConfig{
+		"zero anonymous limit":   {Anonymous: nethttp.Quota{Period: time.Minute}, Authenticated: valid},
+		"zero anonymous period":  {Anonymous: nethttp.Quota{Limit: 1}, Authenticated: valid},
+		"negative authenticated": {Anonymous: valid, Authenticated: nethttp.Quota{Limit: -1, Period: time.Minute}},
+		"missing authenticated":  {Anonymous: valid},
+		"zero group period":      {Anonymous: valid, Authenticated: valid, Groups: map[string]nethttp.Quota{"admin": {Limit: 10}}},
+		"negative route cost":    {Anonymous: valid, Authenticated: valid, Costs: map[string]int{"GET /health": -1}},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			limiter, err := nethttp.NewRateLimiter(cfg)
+
+			// verify
+			assert.ErrorIs(t, err, nethttp.ErrInvalidRateLimitConfig)
+			assert.Nil(t, limiter)
+		})
+	}
+
+	t.Run("default config", func(t *testing.T) {
+		// execute
+		_, err := nethttp.NewRateLimiter(nethttp.DefaultRateLimitConfig())
+
+		// verify
+		assert.NoError(t, err)
+	})
+}
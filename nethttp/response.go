@@ -0,0 +1,97 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// writeProblem writes an RFC 9457 problem details response about the request
+func writeProblem(w http.ResponseWriter, r *http.Request, p model.Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		service.LoggerFromContext(r.Context()).Error("Failed to write problem response", "err", err)
+	}
+}
+
+// writeError writes a problem response with the given status and detail
+func writeError(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(w, r, model.NewProblem(status, detail))
+}
+
+// writeValidationError writes the invalid fields of a validation or query error, other errors are reported as
+// bad requests
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	if p, ok := model.NewValidationProblem(err); ok {
+		writeProblem(w, r, p)
+		return
+	}
+
+	var queryErr *repo.QueryError
+	if errors.As(err, &queryErr) {
+		writeProblem(w, r, newQueryProblem(queryErr))
+		return
+	}
+
+	writeError(w, r, http.StatusBadRequest, err.Error())
+}
+
+// errorStatuses maps the error kinds of the repos and services to response statuses and the details sent to the
+// clients. Authentication errors come first, as they may wrap the error which caused them.
+var errorStatuses = []struct {
+	err    error
+	status int
+	detail string
+}{
+	{service.ErrUnauthorized, http.StatusUnauthorized, "Authentication failed"},
+	{service.ErrForbidden, http.StatusForbidden, "The operation is not allowed"},
+	{service.ErrValidation, http.StatusBadRequest, "The request is invalid"},
+	{repo.ErrInvalidQuery, http.StatusBadRequest, "The query is invalid"},
+	{repo.ErrNotFound, http.StatusNotFound, "The resource was not found"},
+	{repo.ErrConflict, http.StatusConflict, "The resource conflicts with an existing one"},
+	{repo.ErrPreconditionFailed, http.StatusPreconditionFailed, "The resource was modified"},
+	{patch.ErrInvalidPatch, http.StatusBadRequest, "The patch is invalid"},
+	{patch.ErrPathNotFound, http.StatusConflict, "The patch targets a missing path"},
+	{patch.ErrTestFailed, http.StatusConflict, "A test operation of the patch failed"},
+}
+
+// writeServiceError writes the problem response matching the kind of err. The details of err are only logged, the
+// clients get a fixed detail per kind, or the invalid fields of validation and query errors.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	logger := service.LoggerFromContext(r.Context())
+
+	for _, es := range errorStatuses {
+		if !errors.Is(err, es.err) {
+			continue
+		}
+
+		logger.Info("Request failed", "method", r.Method, "path", r.URL.Path, "status", es.status, "err", err)
+
+		if p, ok := model.NewValidationProblem(err); ok {
+			writeProblem(w, r, p)
+			return
+		}
+
+		var queryErr *repo.QueryError
+		if errors.As(err, &queryErr) {
+			writeProblem(w, r, newQueryProblem(queryErr))
+			return
+		}
+
+		writeError(w, r, es.status, es.detail)
+		return
+	}
+
+	logger.Error("Unexpected error", "method", r.Method, "path", r.URL.Path, "err", err)
+	writeError(w, r, http.StatusInternalServerError, "")
+}
@@ -0,0 +1,79 @@
+package nethttp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+)
+
+// LegacyRoutes describes the deprecation of the routes served without the base path
+type LegacyRoutes struct {
+	// Deprecated is when the legacy routes were deprecated
+	Deprecated time.Time
+	// Sunset is when the legacy routes are going to be removed
+	Sunset time.Time
+}
+
+// Routes returns the routes of the API without the base path and the legacy routes, so that they can be compared
+// with the OpenAPI document
+func Routes() []openapi.Route {
+	s := &Server{mux: http.NewServeMux()}
+	s.routes()
+
+	return s.registered
+}
+
+// handle registers the handler for the route under the base path, and at the root too if legacy routes are
+// enabled. Routes are given without the base path, like "GET /projects".
+func (s *Server) handle(route string, h http.Handler) {
+	h = s.validated(h)
+	s.register(route, h)
+
+	if s.legacyRoutes != nil {
+		s.mux.Handle(route, s.deprecated(h))
+	}
+}
+
+// register registers the handler for the route under the base path only, and records the route for Routes
+func (s *Server) register(route string, h http.Handler) {
+	method, path, _ := strings.Cut(route, " ")
+
+	s.mux.Handle(method+" "+s.basePath+path, h)
+
+	registered := openapi.Route{Method: method, Pattern: path}
+	if a, ok := h.(*authenticated); ok {
+		registered.Authenticated = true
+		registered.Groups = a.groups()
+	}
+	s.registered = append(s.registered, registered)
+}
+
+// deprecated marks the responses of a legacy route deprecated as described by RFC 9745 and RFC 8594, and links
+// to the route replacing it
+func (s *Server) deprecated(h http.Handler) http.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(s.legacyRoutes.Deprecated.Unix(), 10)
+	sunset := s.legacyRoutes.Sunset.UTC().Format(http.TimeFormat)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", deprecation)
+		w.Header().Set("Sunset", sunset)
+		w.Header().Add("Link", `<`+s.basePath+r.URL.Path+`>; rel="successor-version"`)
+
+		h.ServeHTTP(w, r)
+	}
+}
+
+// route returns the route of the request without the base path, like "GET /projects/{projectId}", or an empty
+// string if no route matches the request
+func (s *Server) route(r *http.Request) string {
+	_, pattern := s.mux.Handler(r)
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		return pattern
+	}
+
+	return method + " " + strings.TrimPrefix(path, s.basePath)
+}
@@ -0,0 +1,107 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLegacyRoutes = &nethttp.LegacyRoutes{
+	Deprecated: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	Sunset:     time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+}
+
+func TestRoutes(t *testing.T) {
+	t.Run("served under the base path", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/projects", ts.adminToken, nil)
+		rootRec := ts.do(t, http.MethodGet, "/projects", ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+		assert.Equal(t, http.StatusNotFound, rootRec.Code, "legacy routes are disabled by default")
+	})
+
+	t.Run("legacy routes", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{LegacyRoutes: testLegacyRoutes})
+		projectRec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, map[string]string{"name": "Legacy"})
+		require.Equal(t, http.StatusCreated, projectRec.Code, projectRec.Body.String())
+		id := decode[map[string]any](t, projectRec)["id"].(string)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/projects/"+id, ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "@1792368000", rec.Header().Get("Deprecation"))
+		assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v1/projects/`+id+`>; rel="successor-version"`, rec.Header().Get("Link"))
+		assert.Equal(t, "Legacy", decode[map[string]any](t, rec)["name"])
+	})
+
+	t.Run("legacy routes keep other links", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{LegacyRoutes: testLegacyRoutes})
+		ts.createUser(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/users?limit=1", ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		links := rec.Header().Values("Link")
+		require.Len(t, links, 2)
+		assert.Contains(t, links[0], `rel="successor-version"`)
+		assert.Contains(t, links[1], `rel="next"`)
+	})
+
+	t.Run("served at the root without a base path", func(t *testing.T) {
+		// prepare
+		ts := newTestServerWithConfig(t, nethttp.Config{BasePath: "/", LegacyRoutes: testLegacyRoutes})
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/health", "", nil)
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Deprecation"), "routes at the root are not legacy without a base path")
+	})
+
+	t.Run("legacy routes share the rate limit costs", func(t *testing.T) {
+		// prepare
+		limiter, err := nethttp.NewRateLimiter(nethttp.RateLimitConfig{
+			Anonymous:     nethttp.Quota{Limit: 10, Period: time.Minute},
+			Authenticated: nethttp.Quota{Limit: 10, Period: time.Minute},
+			Costs:         map[string]int{"POST /logins": 4},
+		})
+		require.NoError(t, err)
+		ts := newTestServerWithConfig(t, nethttp.Config{RateLimiter: limiter, LegacyRoutes: testLegacyRoutes})
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/logins", "", nil)
+		legacyRec := ts.do(t, http.MethodPost, "/logins", "", nil)
+
+		// verify
+		assert.Equal(t, "6", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "2", legacyRec.Header().Get("X-RateLimit-Remaining"))
+	})
+}
+
+func TestRoutes_MatchSpec(t *testing.T) {
+	// execute
+	report := testSpec(t).CompareRoutes(nethttp.Routes())
+
+	// verify
+	if !report.Empty() {
+		t.Errorf("the routes differ from the OpenAPI document:\n%s", report)
+	}
+}
@@ -0,0 +1,48 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+// searchHitGroups maps the types of search hits to the group required to read them
+var searchHitGroups = map[string]string{
+	model.SearchHitProject: model.GroupProjectRead,
+	model.SearchHitList:    model.GroupProjectRead,
+	model.SearchHitTodo:    model.GroupProjectRead,
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sq := model.SearchQuery{Q: query.Get("q")}
+
+	if v := query.Get("limit"); v != "" {
+		var err error
+		if sq.Limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Invalid limit, integer expected")
+			return
+		}
+	}
+
+	liu := loggedInUser(r)
+
+	var types []string
+	for hitType, group := range searchHitGroups {
+		if liu.HasGroup(group) {
+			types = append(types, hitType)
+		}
+	}
+
+	hits, err := s.searchService.Search(sq, types)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hits); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
@@ -0,0 +1,98 @@
+package nethttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	_, readerToken := ts.createUser(t, model.GroupProjectRead)
+	_, outsiderToken := ts.createUser(t)
+
+	rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, model.ProjectCreate{Name: "Groceries"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	project := decode[model.Project](t, rec)
+
+	rec = ts.do(t, http.MethodPost, "/api/v1/lists/"+ts.createList(t)+"/todos", ts.adminToken, model.TodoCreate{Title: "Buy milk", Description: "Groceries"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	todo := decode[model.Todo](t, rec)
+
+	t.Run("ranked typed hits", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/search?q=groceries", readerToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		hits := decode[[]struct {
+			Type  string          `json:"type"`
+			ID    string          `json:"id"`
+			Score float64         `json:"score"`
+			Item  json.RawMessage `json:"item"`
+		}](t, rec)
+		require.Len(t, hits, 2)
+		assert.Equal(t, model.SearchHitProject, hits[0].Type)
+		var item model.Project
+		require.NoError(t, json.Unmarshal(hits[0].Item, &item))
+		assert.Equal(t, project, item)
+		assert.Equal(t, model.SearchHitTodo, hits[1].Type)
+		assert.Equal(t, todo.ID, hits[1].ID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("changes are searchable immediately", func(t *testing.T) {
+		// prepare
+		rec := ts.patch(t, "/api/v1/lists/"+todo.ListID+"/todos/"+todo.ID, ts.adminToken, patch.MergePatchMediaType, `{"title":"Buy oat drink"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		// execute
+		milkRec := ts.do(t, http.MethodGet, "/api/v1/search?q=milk", readerToken, nil)
+		oatRec := ts.do(t, http.MethodGet, "/api/v1/search?q=OAT", readerToken, nil)
+
+		// verify
+		assert.Empty(t, decode[[]model.SearchHit](t, milkRec))
+		assert.Len(t, decode[[]model.SearchHit](t, oatRec), 1)
+	})
+
+	t.Run("hits are filtered by access", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/search?q=groceries", outsiderToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Empty(t, decode[[]model.SearchHit](t, rec))
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/search?q=groceries", "", nil)
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		tests := map[string]string{
+			"missing q":         "/api/v1/search",
+			"limit not integer": "/api/v1/search?q=milk&limit=many",
+			"limit too high":    "/api/v1/search?q=milk&limit=1000",
+		}
+
+		for name, path := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, http.MethodGet, path, readerToken, nil)
+
+				// verify
+				assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+				assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			})
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/config"
+)
+
+// NewHTTPServer returns an HTTP server serving h with the given settings, config.Default().Server being suitable
+// for an API with small request and response bodies. Errors of the server itself, like failed TLS handshakes, are
+// logged to logger.
+func NewHTTPServer(h http.Handler, cfg config.ServerConfig, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+}
+
+// ShutdownHook releases a resource once the server stopped accepting requests
+type ShutdownHook struct {
+	Name  string
+	Close func(ctx context.Context) error
+}
+
+// Serve serves requests accepted on ln until ctx is done, then shuts the server down gracefully: it stops
+// accepting connections, waits for in-flight requests to finish and runs the hooks in the given order. Draining and
+// the hooks share the shutdown timeout, connections still active when it is over are closed forcibly. Hooks are run
+// even if serving or draining failed, and the errors of every step are returned.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration, logger *slog.Logger, hooks ...ShutdownHook) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		errs = append(errs, fmt.Errorf("failed to serve, err: %w", err))
+	case <-ctx.Done():
+		logger.Info("Shutting down", "timeout", shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain connections, err: %w", err))
+		if err := srv.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connections, err: %w", err))
+		}
+	}
+
+	for _, hook := range hooks {
+		if err := hook.Close(shutdownCtx); err != nil {
+			logger.Error("Shutdown hook failed", "hook", hook.Name, "err", err)
+			errs = append(errs, fmt.Errorf("shutdown hook failed: %s, err: %w", hook.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ListenAndServe listens on the address of srv and serves requests like Serve
+func ListenAndServe(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, logger *slog.Logger, hooks ...ShutdownHook) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %s, err: %w", srv.Addr, err)
+	}
+
+	logger.Info("Serving API", "addr", ln.Addr().String())
+
+	return Serve(ctx, srv, ln, shutdownTimeout, logger, hooks...)
+}
+
+// Worker runs fn in the background until the returned hook is run, which cancels the context of fn and waits for
+// it to return.
+func Worker(name string, fn func(ctx context.Context)) ShutdownHook {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+
+	return ShutdownHook{
+		Name: name,
+		Close: func(shutdownCtx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-shutdownCtx.Done():
+				return fmt.Errorf("worker did not stop: %s, err: %w", name, shutdownCtx.Err())
+			}
+		},
+	}
+}
@@ -0,0 +1,211 @@
+package nethttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServing serves h on a random local port until the returned cancel function is called.
+// The result of nethttp.Serve is sent on the returned channel.
+func startServing(t *testing.T, h http.Handler, shutdownTimeout time.Duration, hooks ...nethttp.ShutdownHook) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	logger := slog.New(slog.DiscardHandler)
+	srv := nethttp.NewHTTPServer(h, config.Default().Server, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- nethttp.Serve(ctx, srv, ln, shutdownTimeout, logger, hooks...)
+	}()
+
+	return "http://" + ln.Addr().String(), cancel, result
+}
+
+// recordingHook returns a hook which appends its name to calls and returns err
+func recordingHook(mu *sync.Mutex, calls *[]string, name string, err error) nethttp.ShutdownHook {
+	return nethttp.ShutdownHook{
+		Name: name,
+		Close: func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			*calls = append(*calls, name)
+
+			return err
+		},
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	// prepare
+	cfg := config.Default().Server
+
+	// execute
+	srv := nethttp.NewHTTPServer(http.NotFoundHandler(), cfg, slog.New(slog.DiscardHandler))
+
+	// verify
+	assert.Equal(t, cfg.Addr, srv.Addr)
+	assert.Equal(t, cfg.ReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, cfg.ReadTimeout, srv.ReadTimeout)
+	assert.Equal(t, cfg.WriteTimeout, srv.WriteTimeout)
+	assert.Equal(t, cfg.IdleTimeout, srv.IdleTimeout)
+	assert.Equal(t, cfg.MaxHeaderBytes, srv.MaxHeaderBytes)
+	assert.NotNil(t, srv.ErrorLog)
+}
+
+func TestServe(t *testing.T) {
+	t.Run("in-flight requests are drained before the hooks run", func(t *testing.T) {
+		// prepare
+		var mu sync.Mutex
+		var calls []string
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+
+			mu.Lock()
+			calls = append(calls, "request")
+			mu.Unlock()
+
+			_, _ = io.WriteString(w, "done")
+		})
+
+		url, cancel, result := startServing(t, h, 5*time.Second,
+			recordingHook(&mu, &calls, "worker", nil),
+			recordingHook(&mu, &calls, "repo", nil),
+		)
+
+		response := make(chan string, 1)
+		go func() {
+			resp, err := http.Get(url)
+			if err != nil {
+				response <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			response <- string(body)
+		}()
+		<-started
+
+		// execute
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		_, newErr := http.Get(url)
+		close(release)
+
+		// verify
+		assert.Error(t, newErr, "new connections are refused while draining")
+		assert.Equal(t, "done", <-response)
+		require.NoError(t, <-result)
+		assert.Equal(t, []string{"request", "worker", "repo"}, calls)
+	})
+
+	t.Run("hook errors do not stop the other hooks", func(t *testing.T) {
+		// prepare
+		var mu sync.Mutex
+		var calls []string
+		errHook := errors.New("hook failed")
+
+		_, cancel, result := startServing(t, http.NotFoundHandler(), time.Second,
+			recordingHook(&mu, &calls, "first", errHook),
+			recordingHook(&mu, &calls, "second", nil),
+		)
+
+		// execute
+		cancel()
+		err := <-result
+
+		// verify
+		assert.ErrorIs(t, err, errHook)
+		assert.ErrorContains(t, err, "first")
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("drain deadline", func(t *testing.T) {
+		// prepare
+		var mu sync.Mutex
+		var calls []string
+
+		started := make(chan struct{})
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		})
+
+		url, cancel, result := startServing(t, h, 50*time.Millisecond, recordingHook(&mu, &calls, "repo", nil))
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+
+		// execute
+		cancel()
+		err := <-result
+
+		// verify
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, []string{"repo"}, calls, "hooks run even if draining timed out")
+	})
+}
+
+func TestWorker(t *testing.T) {
+	t.Run("stopped on shutdown", func(t *testing.T) {
+		// prepare
+		stopped := false
+		hook := nethttp.Worker("test", func(ctx context.Context) {
+			<-ctx.Done()
+			stopped = true
+		})
+
+		// execute
+		err := hook.Close(context.Background())
+
+		// verify
+		require.NoError(t, err)
+		assert.True(t, stopped)
+	})
+
+	t.Run("worker not stopping in time", func(t *testing.T) {
+		// prepare
+		release := make(chan struct{})
+		defer close(release)
+		hook := nethttp.Worker("stuck", func(context.Context) {
+			<-release
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// execute
+		err := hook.Close(ctx)
+
+		// verify
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "stuck")
+	})
+}
@@ -0,0 +1,167 @@
+package nethttp
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/peteraba/go-frameworks/shared/service"
+)
+
+// Deps are the services the HTTP layer depends on
+type Deps struct {
+	ProjectService *service.ProjectService
+	ListService    *service.ListService
+	TodoService    *service.TodoService
+	UserService    *service.UserService
+	AuditService   *service.AuditService
+	SearchService  *service.SearchService
+}
+
+// Config holds the settings of the HTTP layer
+type Config struct {
+	// Middleware is applied to every request in the given order, after request logging and panic recovery, and
+	// before the rest of the built-in middleware
+	Middleware []Middleware
+	// Logger is used for the access log and is passed to the handlers and services, slog.Default() if nil
+	Logger *slog.Logger
+	// BasePath is the path the API is served under, like "/api/v1", the API is served at the root if it is empty
+	BasePath string
+	// LegacyRoutes keeps serving the API at the root too, marking the responses deprecated. Nil disables them, as
+	// does an empty base path.
+	LegacyRoutes *LegacyRoutes
+	// CORS shares the responses with browser apps served from other origins, nil disables CORS
+	CORS *CORS
+	// RateLimiter limits the rate of every request, nil disables rate limiting
+	RateLimiter *RateLimiter
+	// Idempotency stores the responses of create requests with an Idempotency-Key header to replay them to
+	// retries, nil disables it
+	Idempotency *IdempotencyStore
+	// Compression compresses the responses in the content coding negotiated with the client, nil disables it
+	Compression *CompressionConfig
+	// Spec is the OpenAPI document the requests are validated against, nil disables the validation
+	Spec *openapi.Spec
+}
+
+// Middleware wraps a handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// chain wraps h with the middleware, the first middleware being the outermost one
+func chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+
+	return h
+}
+
+type Server struct {
+	projectService *service.ProjectService
+	listService    *service.ListService
+	todoService    *service.TodoService
+	userService    *service.UserService
+	auditService   *service.AuditService
+	searchService  *service.SearchService
+
+	mux          *http.ServeMux
+	logger       *slog.Logger
+	rateLimiter  *RateLimiter
+	basePath     string
+	legacyRoutes *LegacyRoutes
+	cors         *CORS
+	idempotency  *IdempotencyStore
+	spec         *openapi.Spec
+
+	// registered are the routes of the API, see Routes
+	registered []openapi.Route
+}
+
+// NewServer returns the handler serving the API with the given dependencies
+func NewServer(deps Deps, cfg Config) http.Handler {
+	s := &Server{
+		projectService: deps.ProjectService,
+		listService:    deps.ListService,
+		todoService:    deps.TodoService,
+		userService:    deps.UserService,
+		auditService:   deps.AuditService,
+		searchService:  deps.SearchService,
+		mux:            http.NewServeMux(),
+		logger:         cfg.Logger,
+		basePath:       strings.TrimSuffix(cfg.BasePath, "/"),
+		idempotency:    cfg.Idempotency,
+		spec:           cfg.Spec,
+	}
+	if s.basePath != "" {
+		s.legacyRoutes = cfg.LegacyRoutes
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+
+	s.routes()
+
+	middleware := append([]Middleware{s.logRequests, recoverPanics}, cfg.Middleware...)
+	middleware = append(middleware, withActor)
+	if cfg.CORS != nil {
+		s.cors = cfg.CORS
+		middleware = append(middleware, s.handleCORS)
+	}
+	if cfg.RateLimiter != nil {
+		s.rateLimiter = cfg.RateLimiter
+		middleware = append(middleware, s.rateLimit)
+	}
+	if cfg.Compression != nil {
+		middleware = append(middleware, compress(*cfg.Compression))
+	}
+
+	return chain(s.mux, middleware...)
+}
+
+func (s *Server) routes() {
+	// --- Project Handlers ---
+	s.handle("GET /projects", s.authenticate(s.handleListProjects, inGroup(model.GroupProjectRead)))
+	s.handle("POST /projects", s.authenticate(s.idempotent(s.handleCreateProject), inGroup(model.GroupProjectWrite)))
+	s.handle("GET /projects/{projectId}", s.authenticate(s.handleGetProject, inGroup(model.GroupProjectRead)))
+	s.handle("PUT /projects/{projectId}", s.authenticate(s.handleUpdateProject, inGroup(model.GroupProjectWrite)))
+	s.handle("PATCH /projects/{projectId}", s.authenticate(s.handlePatchProject, inGroup(model.GroupProjectWrite)))
+
+	// --- List Handlers ---
+	s.handle("GET /lists", s.authenticate(s.handleListLists, inGroup(model.GroupProjectRead)))
+	s.handle("POST /lists", s.authenticate(s.idempotent(s.handleCreateList), inGroup(model.GroupProjectWrite)))
+	s.handle("GET /lists/{listId}", s.authenticate(s.handleGetList, inGroup(model.GroupProjectRead)))
+	s.handle("PUT /lists/{listId}", s.authenticate(s.handleUpdateList, inGroup(model.GroupProjectWrite)))
+	s.handle("PATCH /lists/{listId}", s.authenticate(s.handlePatchList, inGroup(model.GroupProjectWrite)))
+
+	// --- Todo Handlers ---
+	s.handle("GET /lists/{listId}/todos", s.authenticate(s.handleListTodos, inGroup(model.GroupProjectRead)))
+	s.handle("POST /lists/{listId}/todos", s.authenticate(s.idempotent(s.handleCreateTodo), inGroup(model.GroupProjectWrite)))
+	s.handle("GET /lists/{listId}/todos/{todoId}", s.authenticate(s.handleGetTodo, inGroup(model.GroupProjectRead)))
+	s.handle("PUT /lists/{listId}/todos/{todoId}", s.authenticate(s.handleUpdateTodo, inGroup(model.GroupProjectWrite)))
+	s.handle("PATCH /lists/{listId}/todos/{todoId}", s.authenticate(s.handlePatchTodo, inGroup(model.GroupProjectWrite)))
+
+	// --- User Handlers ---
+	s.handle("GET /users", s.authenticate(s.handleListUsers, inGroup(model.GroupAdmin)))
+	s.handle("POST /users", s.authenticate(s.idempotent(s.handleCreateUser), inGroup(model.GroupAdmin)))
+	s.handle("GET /users/{userId}", s.authenticate(s.handleGetUser, isSelf("userId"), inGroup(model.GroupAdmin)))
+	s.handle("PUT /users/{userId}", s.authenticate(s.handleUpdateUser, inGroup(model.GroupAdmin)))
+	s.handle("DELETE /users/{userId}", s.authenticate(s.handleDeleteUser, inGroup(model.GroupAdmin)))
+	s.handle("PUT /users/{userId}/passwords", s.authenticate(s.handleUpdateUserPassword, isSelf("userId"), inGroup(model.GroupAdmin)))
+	s.handle("PUT /users/{userId}/status", s.authenticate(s.handleUpdateUserStatus, inGroup(model.GroupAdmin)))
+	s.handle("POST /logins", http.HandlerFunc(s.handleLoginUser))
+	s.handle("GET /health", http.HandlerFunc(s.handleHealth))
+
+	// --- Search Handlers ---
+	// Every logged-in user may search, the hits are filtered by the groups of the user
+	s.handle("GET /search", s.authenticate(s.handleSearch))
+
+	// --- Audit Handlers ---
+	s.handle("GET /audit-events", s.authenticate(s.handleListAuditEvents, inGroup(model.GroupAdmin)))
+
+	// --- Documentation Handlers ---
+	// Only served under the base path, as they were never served at the root
+	s.register("GET /openapi.yaml", http.HandlerFunc(s.handleOpenAPIYAML))
+	s.register("GET /openapi.json", http.HandlerFunc(s.handleOpenAPIJSON))
+	s.register("GET /docs", http.HandlerFunc(s.handleDocs))
+}
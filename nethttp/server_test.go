@@ -0,0 +1,242 @@
+package nethttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/peteraba/go-frameworks/shared/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	model.InitFaker()
+}
+
+type testServer struct {
+	handler    http.Handler
+	deps       nethttp.Deps
+	admin      model.User
+	adminToken string
+}
+
+// newTestServer returns a server backed by in-memory repos, with a logged-in administrator
+func newTestServer(t *testing.T, middleware ...nethttp.Middleware) *testServer {
+	t.Helper()
+
+	return newTestServerWithConfig(t, nethttp.Config{Middleware: middleware})
+}
+
+// newTestServerWithConfig returns a server like newTestServer, with the given configuration
+func newTestServerWithConfig(t testing.TB, cfg nethttp.Config) *testServer {
+	t.Helper()
+
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.DiscardHandler)
+	}
+	if cfg.BasePath == "" {
+		cfg.BasePath = "/api/v1"
+	}
+	if cfg.Spec == nil {
+		cfg.Spec = testSpec(t)
+	}
+
+	auditService := service.NewAuditService(repo.NewInMemoryAuditSink())
+	index := search.NewIndex()
+	projectRepo := repo.NewIndexedProjectRepo(repo.NewInMemoryProjectRepo(), index)
+	listRepo := repo.NewIndexedListRepo(repo.NewInMemoryListRepo(), index)
+	todoRepo := repo.NewIndexedTodoRepo(repo.NewInMemoryTodoRepo(), index)
+	deps := nethttp.Deps{
+		ProjectService: service.NewProjectService(projectRepo),
+		ListService:    service.NewListService(listRepo),
+		TodoService:    service.NewTodoService(todoRepo),
+		UserService:    service.NewUserService(repo.NewInMemoryUserRepo(), auditService),
+		AuditService:   auditService,
+		SearchService:  service.NewSearchService(index, projectRepo, listRepo, todoRepo),
+	}
+
+	ts := &testServer{
+		handler: nethttp.NewServer(deps, cfg),
+		deps:    deps,
+	}
+	ts.admin, ts.adminToken = ts.createUser(t, model.GroupAdmin)
+
+	return ts
+}
+
+// testSpec returns the OpenAPI document the requests of the test servers are validated against
+func testSpec(t testing.TB) *openapi.Spec {
+	t.Helper()
+
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	spec, err := doc.Spec()
+	require.NoError(t, err)
+
+	return spec
+}
+
+// createUser creates a user in the given groups and returns it with a token to authenticate as them
+func (ts *testServer) createUser(t testing.TB, groups ...string) (model.User, string) {
+	t.Helper()
+
+	uc := model.RandomUserCreate()
+	uc.Groups = groups
+
+	user, err := ts.deps.UserService.Create(context.Background(), uc)
+	require.NoError(t, err)
+
+	token, err := ts.deps.UserService.Login(context.Background(), model.UserLogin{Email: uc.Email, Password: uc.Password})
+	require.NoError(t, err)
+
+	return user, token
+}
+
+// createList creates a list to add todo items to, and returns its ID
+func (ts *testServer) createList(t testing.TB) string {
+	t.Helper()
+
+	list, err := ts.deps.ListService.Create(context.Background(), model.RandomListCreate())
+	require.NoError(t, err)
+
+	return list.ID
+}
+
+// do sends a request to the server. Body is sent as is if it is a string, JSON encoded otherwise.
+func (ts *testServer) do(t *testing.T, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return ts.doWithHeader(t, method, path, token, body, nil)
+}
+
+// doWithHeader sends a request to the server like do, with additional request headers
+func (ts *testServer) doWithHeader(t *testing.T, method, path, token string, body any, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		reader = bytes.NewBuffer(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// patch sends a PATCH request with a patch document of the given media type
+func (ts *testServer) patch(t *testing.T, path, token, mediaType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", mediaType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// decode decodes the JSON body of the response
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v), rec.Body.String())
+
+	return v
+}
+
+func TestNewServer(t *testing.T) {
+	t.Run("servers do not share state", func(t *testing.T) {
+		// prepare
+		ts1 := newTestServer(t)
+		ts2 := newTestServer(t)
+
+		// execute
+		rec := ts1.do(t, http.MethodPost, "/api/v1/projects", ts1.adminToken, model.RandomProjectCreate())
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		// verify
+		assert.Len(t, decode[[]model.Project](t, ts1.do(t, http.MethodGet, "/api/v1/projects", ts1.adminToken, nil)), 1)
+		assert.Empty(t, decode[[]model.Project](t, ts2.do(t, http.MethodGet, "/api/v1/projects", ts2.adminToken, nil)))
+	})
+
+	t.Run("configured middleware is applied in order", func(t *testing.T) {
+		// prepare
+		var calls []string
+		record := func(name string) nethttp.Middleware {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+		ts := newTestServer(t, record("first"), record("second"))
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/health", "", nil)
+
+		// verify
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("authentication failures are problems", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, token := ts.createUser(t)
+
+		// execute
+		unauthorized := ts.do(t, http.MethodGet, "/api/v1/projects", "", nil)
+		forbidden := ts.do(t, http.MethodGet, "/api/v1/projects", token, nil)
+
+		// verify
+		assert.Equal(t, "application/problem+json", unauthorized.Header().Get("Content-Type"))
+		assert.Equal(t, model.ProblemTypeUnauthorized, decode[model.Problem](t, unauthorized).Type)
+		assert.NotEmpty(t, unauthorized.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, "application/problem+json", forbidden.Header().Get("Content-Type"))
+		assert.Equal(t, model.ProblemTypeForbidden, decode[model.Problem](t, forbidden).Type)
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/unknown", ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,134 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+)
+
+// todoInList returns the todo item in the path, reporting it as not found unless it belongs to the list in the path
+func (s *Server) todoInList(r *http.Request) (model.Todo, error) {
+	listId, todoId := r.PathValue("listId"), r.PathValue("todoId")
+	if _, err := s.listService.GetByID(r.Context(), listId); err != nil {
+		return model.Todo{}, err
+	}
+
+	todo, err := s.todoService.GetByID(r.Context(), todoId)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	if todo.ListID != listId {
+		return model.Todo{}, fmt.Errorf("id: %s, list: %s, err: %w", todoId, listId, repo.ErrTodoNotFound)
+	}
+
+	return todo, nil
+}
+
+func (s *Server) handleListTodos(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	// Only the items of the list in the path are listed, regardless of the query
+	if query.Filters == nil {
+		query.Filters = map[string]string{}
+	}
+	query.Filters["listId"] = r.PathValue("listId")
+	todos, err := s.todoService.List(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(todos); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleCreateTodo(w http.ResponseWriter, r *http.Request) {
+	listId := r.PathValue("listId")
+	var tc model.TodoCreate
+	if !decodeJSON(w, r, &tc) {
+		return
+	}
+	if _, err := s.listService.GetByID(r.Context(), listId); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	tc.ListID = listId
+	todo, err := s.todoService.Create(r.Context(), tc)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, todo.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(todo); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleGetTodo(w http.ResponseWriter, r *http.Request) {
+	todo, err := s.todoInList(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if notModified(w, r, todo.Version) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(todo); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateTodo(w http.ResponseWriter, r *http.Request) {
+	var tu model.TodoUpdate
+	if !decodeJSON(w, r, &tu) {
+		return
+	}
+	todo, err := s.todoInList(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	todo, err = s.todoService.Update(r.Context(), todo.ID, ifMatch(r), tu)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, todo.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(todo); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handlePatchTodo(w http.ResponseWriter, r *http.Request) {
+	mediaType, body, ok := readPatch(w, r)
+	if !ok {
+		return
+	}
+	todo, err := s.todoInList(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	todo, err = s.todoService.Patch(r.Context(), todo.ID, ifMatch(r), applyPatch[model.Todo](mediaType, body))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, todo.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(todo); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
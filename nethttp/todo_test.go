@@ -0,0 +1,165 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoRoutes(t *testing.T) {
+	t.Run("create, get, list and update todo", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, token := ts.createUser(t, model.GroupProjectRead, model.GroupProjectWrite)
+		listID := ts.createList(t)
+		tc := model.RandomTodoCreate()
+		tu := model.RandomTodoUpdate()
+
+		// execute
+		createRec := ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", token, tc)
+		require.Equal(t, http.StatusCreated, createRec.Code, createRec.Body.String())
+		created := decode[model.Todo](t, createRec)
+
+		getRec := ts.do(t, http.MethodGet, "/api/v1/lists/"+listID+"/todos/"+created.ID, token, nil)
+		listRec := ts.do(t, http.MethodGet, "/api/v1/lists/"+listID+"/todos", token, nil)
+		updateRec := ts.do(t, http.MethodPut, "/api/v1/lists/"+listID+"/todos/"+created.ID, token, tu)
+
+		// verify
+		assert.Equal(t, tc.Title, created.Title)
+		assert.Equal(t, listID, created.ListID, "list ID is taken from the path")
+
+		require.Equal(t, http.StatusOK, getRec.Code)
+		assert.Equal(t, created, decode[model.Todo](t, getRec))
+
+		require.Equal(t, http.StatusOK, listRec.Code)
+		assert.Equal(t, []model.Todo{created}, decode[[]model.Todo](t, listRec))
+
+		require.Equal(t, http.StatusOK, updateRec.Code, updateRec.Body.String())
+		updated := decode[model.Todo](t, updateRec)
+		assert.Equal(t, tu.Title, updated.Title)
+		assert.Equal(t, tu.Completed, updated.Completed)
+	})
+
+	t.Run("get unknown todo", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/lists/"+model.RandomList().ID+"/todos/"+model.RandomTodo().ID, ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("update unknown todo", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/lists/"+model.RandomList().ID+"/todos/"+model.RandomTodo().ID, ts.adminToken, model.RandomTodoUpdate())
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, model.ProblemTypeNotFound, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("todos are only found in their list", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		listID, otherListID := ts.createList(t), ts.createList(t)
+		created := decode[model.Todo](t, ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", ts.adminToken, model.RandomTodoCreate()))
+		unknownListID := model.RandomList().ID
+
+		tests := map[string]struct {
+			method string
+			path   string
+			body   any
+			header http.Header
+		}{
+			"get from other list":    {http.MethodGet, "/api/v1/lists/" + otherListID + "/todos/" + created.ID, nil, nil},
+			"get from unknown list":  {http.MethodGet, "/api/v1/lists/" + unknownListID + "/todos/" + created.ID, nil, nil},
+			"update in other list":   {http.MethodPut, "/api/v1/lists/" + otherListID + "/todos/" + created.ID, model.RandomTodoUpdate(), nil},
+			"update in unknown list": {http.MethodPut, "/api/v1/lists/" + unknownListID + "/todos/" + created.ID, model.RandomTodoUpdate(), nil},
+			"patch in other list":    {http.MethodPatch, "/api/v1/lists/" + otherListID + "/todos/" + created.ID, map[string]any{"completed": true}, http.Header{"Content-Type": {"application/merge-patch+json"}}},
+			"create in unknown list": {http.MethodPost, "/api/v1/lists/" + unknownListID + "/todos", model.RandomTodoCreate(), nil},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.doWithHeader(t, tt.method, tt.path, ts.adminToken, tt.body, tt.header)
+
+				// verify
+				assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+			})
+		}
+
+		unchanged := decode[model.Todo](t, ts.do(t, http.MethodGet, "/api/v1/lists/"+listID+"/todos/"+created.ID, ts.adminToken, nil))
+		assert.Equal(t, created, unchanged)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		listID := ts.createList(t)
+		created := decode[model.Todo](t, ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", ts.adminToken, model.RandomTodoCreate()))
+
+		tests := map[string]struct {
+			method string
+			path   string
+			body   any
+		}{
+			"create with malformed JSON": {http.MethodPost, "/api/v1/lists/" + listID + "/todos", "{"},
+			"create without title":       {http.MethodPost, "/api/v1/lists/" + listID + "/todos", model.TodoCreate{}},
+			"update with malformed JSON": {http.MethodPut, "/api/v1/lists/" + listID + "/todos/" + created.ID, "{"},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, ts.adminToken, tt.body)
+
+				// verify
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			})
+		}
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		_, readToken := ts.createUser(t, model.GroupProjectRead)
+		_, noGroupToken := ts.createUser(t)
+		listID := ts.createList(t)
+		created := decode[model.Todo](t, ts.do(t, http.MethodPost, "/api/v1/lists/"+listID+"/todos", ts.adminToken, model.RandomTodoCreate()))
+		todoPath := "/api/v1/lists/" + listID + "/todos/" + created.ID
+
+		tests := map[string]struct {
+			method string
+			path   string
+			token  string
+			body   any
+			want   int
+		}{
+			"list without token":          {http.MethodGet, "/api/v1/lists/" + listID + "/todos", "", nil, http.StatusUnauthorized},
+			"list without group":          {http.MethodGet, "/api/v1/lists/" + listID + "/todos", noGroupToken, nil, http.StatusForbidden},
+			"list with read group":        {http.MethodGet, "/api/v1/lists/" + listID + "/todos", readToken, nil, http.StatusOK},
+			"get without group":           {http.MethodGet, todoPath, noGroupToken, nil, http.StatusForbidden},
+			"create with read group only": {http.MethodPost, "/api/v1/lists/" + listID + "/todos", readToken, model.RandomTodoCreate(), http.StatusForbidden},
+			"update with read group only": {http.MethodPut, todoPath, readToken, model.RandomTodoUpdate(), http.StatusForbidden},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, tt.token, tt.body)
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code)
+			})
+		}
+	})
+}
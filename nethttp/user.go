@@ -0,0 +1,199 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := model.UserSearch{
+		Name:   query.Get("name"),
+		Email:  query.Get("email"),
+		Group:  query.Get("group"),
+		Status: model.UserStatus(query.Get("status")),
+		Sort:   query.Get("sort"),
+	}
+
+	var err error
+	if v := query.Get("offset"); v != "" {
+		if search.Offset, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Invalid offset, integer expected")
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if search.Limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Invalid limit, integer expected")
+			return
+		}
+	}
+
+	page, err := s.userService.Search(r.Context(), search)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if link := paginationLinks(r, page); link != "" {
+		w.Header().Add("Link", link)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page.Users); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+// paginationLinks returns an RFC 8288 Link header value pointing to the previous and next pages, if any. The
+// pages are as large as the limit of the page, even if the page itself is shorter.
+func paginationLinks(r *http.Request, page model.UserPage) string {
+	offset, limit := page.Offset, page.Limit
+	if limit <= 0 {
+		return ""
+	}
+
+	pageURL := func(offset int) string {
+		u := *r.URL
+		query := u.Query()
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+		u.RawQuery = query.Encode()
+
+		return u.RequestURI()
+	}
+
+	var links []string
+	if offset > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(max(offset-limit, 0))))
+	}
+	if offset+limit < page.Total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(offset+limit)))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var uc model.UserCreate
+	if !decodeJSON(w, r, &uc) {
+		return
+	}
+	user, err := s.userService.Create(r.Context(), uc)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	user, err := s.userService.GetByID(r.Context(), userId)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if notModified(w, r, user.Version) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	var uu model.UserUpdate
+	if !decodeJSON(w, r, &uu) {
+		return
+	}
+	user, err := s.userService.Update(r.Context(), userId, ifMatch(r), uu)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	user, err := s.userService.Delete(r.Context(), userId, ifMatch(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateUserStatus(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	var us model.UserStatusUpdate
+	if !decodeJSON(w, r, &us) {
+		return
+	}
+	user, err := s.userService.UpdateStatus(r.Context(), userId, ifMatch(r), us)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleUpdateUserPassword(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	var up model.UserPasswordUpdate
+	if !decodeJSON(w, r, &up) {
+		return
+	}
+	user, err := s.userService.UpdatePassword(r.Context(), userId, ifMatch(r), up)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
+
+func (s *Server) handleLoginUser(w http.ResponseWriter, r *http.Request) {
+	var ul model.UserLogin
+	if !decodeJSON(w, r, &ul) {
+		return
+	}
+	token, err := s.userService.Login(r.Context(), ul)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"token": token}); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to encode response")
+	}
+}
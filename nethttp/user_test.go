@@ -0,0 +1,230 @@
+package nethttp_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRoutes(t *testing.T) {
+	t.Run("create, get, search and update user", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		uc := model.RandomUserCreate()
+		uu := model.RandomUserUpdate()
+
+		// execute
+		createRec := ts.do(t, http.MethodPost, "/api/v1/users", ts.adminToken, uc)
+		require.Equal(t, http.StatusCreated, createRec.Code, createRec.Body.String())
+		created := decode[model.User](t, createRec)
+
+		getRec := ts.do(t, http.MethodGet, "/api/v1/users/"+created.ID, ts.adminToken, nil)
+		listRec := ts.do(t, http.MethodGet, "/api/v1/users?sort=id&limit=1", ts.adminToken, nil)
+		updateRec := ts.do(t, http.MethodPut, "/api/v1/users/"+created.ID, ts.adminToken, uu)
+
+		// verify
+		assert.Equal(t, uc.Email, created.Email)
+		assert.Equal(t, model.UserStatusActive, created.Status)
+		assert.NotContains(t, createRec.Body.String(), uc.Password)
+
+		require.Equal(t, http.StatusOK, getRec.Code)
+		assert.Equal(t, created, decode[model.User](t, getRec))
+
+		require.Equal(t, http.StatusOK, listRec.Code)
+		assert.Len(t, decode[[]model.User](t, listRec), 1)
+		assert.Equal(t, "2", listRec.Header().Get("X-Total-Count"))
+		assert.Contains(t, listRec.Header().Get("Link"), `rel="next"`)
+
+		require.Equal(t, http.StatusOK, updateRec.Code, updateRec.Body.String())
+		assert.Equal(t, uu.Name, decode[model.User](t, updateRec).Name)
+	})
+
+	t.Run("pagination links use the page size of the repo", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		ts.createUser(t, model.GroupProjectRead)
+		ts.createUser(t, model.GroupProjectRead)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/users?offset=2", ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[[]model.User](t, rec), 1)
+		assert.Equal(t, `</api/v1/users?limit=100&offset=0>; rel="prev"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("create user with taken email", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		uc := model.RandomUserCreate()
+		uc.Email = strings.ToUpper(ts.admin.Email)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/users", ts.adminToken, uc)
+
+		// verify
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, model.ProblemTypeConflict, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("get unknown user", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/users/"+model.RandomUser().ID, ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("delete user schedules deletion and revokes access", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, token := ts.createUser(t, model.GroupProjectRead)
+
+		// execute
+		rec := ts.do(t, http.MethodDelete, "/api/v1/users/"+user.ID, ts.adminToken, nil)
+
+		// verify
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		deleted := decode[model.User](t, rec)
+		assert.Equal(t, model.UserStatusPendingDeletion, deleted.Status)
+		assert.NotNil(t, deleted.DeletionScheduledAt)
+
+		assert.Equal(t, http.StatusUnauthorized, ts.do(t, http.MethodGet, "/api/v1/projects", token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, ts.do(t, http.MethodDelete, "/api/v1/users/"+model.RandomUser().ID, ts.adminToken, nil).Code)
+	})
+
+	t.Run("update user status", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, _ := ts.createUser(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/users/"+user.ID+"/status", ts.adminToken, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, model.UserStatusSuspended, decode[model.User](t, rec).Status)
+		assert.Equal(t, http.StatusBadRequest, ts.do(t, http.MethodPut, "/api/v1/users/"+user.ID+"/status", ts.adminToken, model.UserStatusUpdate{Status: "unknown"}).Code)
+	})
+
+	t.Run("invalid status transition", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, _ := ts.createUser(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/users/"+user.ID+"/status", ts.adminToken, model.UserStatusUpdate{Status: model.UserStatusActive})
+
+		// verify
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, http.StatusNotFound, ts.do(t, http.MethodPut, "/api/v1/users/"+model.RandomUser().ID+"/status", ts.adminToken, model.UserStatusUpdate{Status: model.UserStatusSuspended}).Code)
+	})
+
+	t.Run("update own password and log in with it", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		user, token := ts.createUser(t)
+		password := "correct horse battery staple"
+
+		// execute
+		rec := ts.do(t, http.MethodPut, "/api/v1/users/"+user.ID+"/passwords", token, model.UserPasswordUpdate{Password: password, Password2: password})
+
+		// verify
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		loginRec := ts.do(t, http.MethodPost, "/api/v1/logins", "", model.UserLogin{Email: user.Email, Password: password})
+		require.Equal(t, http.StatusOK, loginRec.Code, loginRec.Body.String())
+		assert.NotEmpty(t, decode[model.LoginResponse](t, loginRec).Token)
+	})
+
+	t.Run("login with wrong password", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/logins", "", model.UserLogin{Email: ts.admin.Email, Password: "wrong password"})
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, http.StatusBadRequest, ts.do(t, http.MethodPost, "/api/v1/logins", "", "{").Code)
+	})
+
+	t.Run("login does not reveal unknown emails", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/logins", "", model.UserLogin{Email: "unknown@example.com", Password: "irrelevant"})
+		wrongPasswordRec := ts.do(t, http.MethodPost, "/api/v1/logins", "", model.UserLogin{Email: ts.admin.Email, Password: "irrelevant"})
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, model.ProblemTypeUnauthorized, decode[model.Problem](t, rec).Type)
+		assert.Equal(t, wrongPasswordRec.Body.String(), rec.Body.String())
+		assert.Equal(t, "Authentication failed", decode[model.Problem](t, rec).Detail)
+	})
+
+	t.Run("login to suspended account", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		uc := model.RandomUserCreate()
+		user, err := ts.deps.UserService.Create(context.Background(), uc)
+		require.NoError(t, err)
+		_, err = ts.deps.UserService.UpdateStatus(context.Background(), user.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+		require.NoError(t, err)
+
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/logins", "", model.UserLogin{Email: uc.Email, Password: uc.Password})
+
+		// verify
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		// prepare
+		ts := newTestServer(t)
+		self, selfToken := ts.createUser(t, model.GroupProjectRead, model.GroupProjectWrite)
+		other, _ := ts.createUser(t)
+		password := model.UserPasswordUpdate{Password: "new password", Password2: "new password"}
+
+		tests := map[string]struct {
+			method string
+			path   string
+			token  string
+			body   any
+			want   int
+		}{
+			"search without token":          {http.MethodGet, "/api/v1/users", "", nil, http.StatusUnauthorized},
+			"search as non-admin":           {http.MethodGet, "/api/v1/users", selfToken, nil, http.StatusForbidden},
+			"create as non-admin":           {http.MethodPost, "/api/v1/users", selfToken, model.RandomUserCreate(), http.StatusForbidden},
+			"get self":                      {http.MethodGet, "/api/v1/users/" + self.ID, selfToken, nil, http.StatusOK},
+			"get other as non-admin":        {http.MethodGet, "/api/v1/users/" + other.ID, selfToken, nil, http.StatusForbidden},
+			"update self as non-admin":      {http.MethodPut, "/api/v1/users/" + self.ID, selfToken, model.UserUpdate{Groups: []string{model.GroupAdmin}}, http.StatusForbidden},
+			"delete other as non-admin":     {http.MethodDelete, "/api/v1/users/" + other.ID, selfToken, nil, http.StatusForbidden},
+			"update status as non-admin":    {http.MethodPut, "/api/v1/users/" + other.ID + "/status", selfToken, model.UserStatusUpdate{Status: model.UserStatusSuspended}, http.StatusForbidden},
+			"update other's password":       {http.MethodPut, "/api/v1/users/" + other.ID + "/passwords", selfToken, password, http.StatusForbidden},
+			"update password as admin":      {http.MethodPut, "/api/v1/users/" + other.ID + "/passwords", ts.adminToken, password, http.StatusOK},
+			"update password without token": {http.MethodPut, "/api/v1/users/" + self.ID + "/passwords", "", password, http.StatusUnauthorized},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// execute
+				rec := ts.do(t, tt.method, tt.path, tt.token, tt.body)
+
+				// verify
+				assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			})
+		}
+	})
+}
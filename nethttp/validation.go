@@ -0,0 +1,168 @@
+package nethttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+)
+
+// validated rejects requests not matching the operation of the OpenAPI document they are sent to with a
+// validation problem. Requests without a documented operation, and bodies of media types which are not
+// documented, are left to the handlers. Authenticated handlers are returned as is, as they validate the requests
+// after authorizing them, so that anonymous clients get 401 rather than the details of invalid requests.
+func (s *Server) validated(h http.Handler) http.Handler {
+	if _, ok := h.(*authenticated); ok || s.spec == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.validRequest(w, r) {
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// validRequest validates the request against the OpenAPI document. If it is invalid, the error response is
+// written and false is returned.
+func (s *Server) validRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.spec == nil {
+		return true
+	}
+
+	path := r.URL.Path
+	if s.basePath != "" && strings.HasPrefix(path, s.basePath+"/") {
+		path = strings.TrimPrefix(path, s.basePath)
+	}
+
+	op, docPath, pathParams, found := s.spec.FindOperation(r.Method, path)
+	if !found {
+		return true
+	}
+
+	var violations []openapi.Violation
+	for _, p := range s.spec.Parameters(docPath, op) {
+		violations = append(violations, validateParameter(r, p, pathParams)...)
+	}
+
+	bodyViolations, ok := validateBody(w, r, op.RequestBody)
+	if !ok {
+		return false
+	}
+	violations = append(violations, bodyViolations...)
+
+	if len(violations) > 0 {
+		writeProblem(w, r, newViolationProblem(violations))
+		return false
+	}
+
+	return true
+}
+
+// validateParameter validates the value of a parameter, repeated query parameters are left to the handlers
+func validateParameter(r *http.Request, p *openapi.Parameter, pathParams map[string]string) []openapi.Violation {
+	var (
+		values []string
+		found  bool
+	)
+
+	switch p.In {
+	case "path":
+		var value string
+		value, found = pathParams[p.Name]
+		values = []string{value}
+	case "query":
+		values, found = r.URL.Query()[p.Name]
+	case "header":
+		values = r.Header.Values(p.Name)
+		found = len(values) > 0
+	case "cookie":
+		var cookie *http.Cookie
+		cookie, err := r.Cookie(p.Name)
+		if found = err == nil; found {
+			values = []string{cookie.Value}
+		}
+	}
+
+	if !found {
+		if p.Required {
+			return []openapi.Violation{{Name: p.Name, Rule: "required", Reason: "is required"}}
+		}
+		return nil
+	}
+
+	return p.Schema.ValidateString(p.Name, values[0])
+}
+
+// validateBody validates JSON bodies of the documented media types. Bodies without a content type and malformed
+// JSON are left to the handlers, which reject them. If the body can not be read, the error response is written and
+// false is returned.
+func validateBody(w http.ResponseWriter, r *http.Request, body *openapi.RequestBody) ([]openapi.Violation, bool) {
+	if body == nil {
+		return nil, true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, true
+	}
+
+	media, ok := body.Content[mediaType]
+	if !ok || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return nil, true
+	}
+
+	content, ok := readBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(content))
+
+	if len(bytes.TrimSpace(content)) == 0 {
+		if body.Required {
+			return []openapi.Violation{{Rule: "required", Reason: "is required"}}, true
+		}
+		return nil, true
+	}
+
+	var value any
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return nil, true
+	}
+
+	return media.Schema.Validate(value), true
+}
+
+// newViolationProblem returns a validation problem listing the violations, the violations of the whole body are
+// reported as the body
+func newViolationProblem(violations []openapi.Violation) model.Problem {
+	p := model.Problem{
+		Type:          model.ProblemTypeValidation,
+		Title:         "Validation failed",
+		Status:        http.StatusBadRequest,
+		Detail:        "The request contains invalid fields, see invalidParams for details",
+		InvalidParams: make([]model.InvalidParam, 0, len(violations)),
+	}
+
+	for _, v := range violations {
+		name := v.Name
+		if name == "" {
+			name = "body"
+		}
+		p.InvalidParams = append(p.InvalidParams, model.InvalidParam{
+			Name:   name,
+			Rule:   v.Rule,
+			Params: v.Params,
+			Reason: v.Reason,
+		})
+	}
+
+	return p
+}
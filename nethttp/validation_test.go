@@ -0,0 +1,140 @@
+package nethttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/nethttp"
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequests(t *testing.T) {
+	// prepare
+	ts := newTestServerWithConfig(t, nethttp.Config{LegacyRoutes: testLegacyRoutes})
+
+	tests := map[string]struct {
+		method     string
+		path       string
+		body       any
+		header     http.Header
+		wantParams []model.InvalidParam
+	}{
+		"path parameter": {
+			method:     http.MethodGet,
+			path:       "/api/v1/projects/42",
+			wantParams: []model.InvalidParam{{Name: "projectId", Rule: "ulid", Reason: "must be a valid ULID"}},
+		},
+		"query parameter": {
+			method:     http.MethodGet,
+			path:       "/api/v1/users?limit=500",
+			wantParams: []model.InvalidParam{{Name: "limit", Rule: "max", Params: []string{"100"}, Reason: "must be at most 100"}},
+		},
+		"required query parameter": {
+			method:     http.MethodGet,
+			path:       "/api/v1/search",
+			wantParams: []model.InvalidParam{{Name: "q", Rule: "required", Reason: "is required"}},
+		},
+		"body": {
+			method: http.MethodPost,
+			path:   "/api/v1/users",
+			body:   map[string]any{"name": "John", "email": "john", "password": "secret", "password2": "secret", "groups": []string{strings.Repeat("g", 27)}},
+			wantParams: []model.InvalidParam{
+				{Name: "email", Rule: "email", Reason: "must be a valid email address"},
+				{Name: "groups[0]", Rule: "max", Params: []string{"26"}, Reason: "must be at most 26 characters long"},
+				{Name: "password", Rule: "min", Params: []string{"8"}, Reason: "must be at least 8 characters long"},
+				{Name: "password2", Rule: "min", Params: []string{"8"}, Reason: "must be at least 8 characters long"},
+			},
+		},
+		"required body": {
+			method:     http.MethodPost,
+			path:       "/api/v1/logins",
+			body:       "",
+			wantParams: []model.InvalidParam{{Name: "body", Rule: "required", Reason: "is required"}},
+		},
+		"body of the wrong type": {
+			method:     http.MethodPost,
+			path:       "/api/v1/projects",
+			body:       "[]",
+			wantParams: []model.InvalidParam{{Name: "body", Rule: "type", Params: []string{"object"}, Reason: "must be an object"}},
+		},
+		"parameters and body": {
+			method: http.MethodPut,
+			path:   "/api/v1/lists/42",
+			body:   map[string]any{"name": 42},
+			wantParams: []model.InvalidParam{
+				{Name: "listId", Rule: "ulid", Reason: "must be a valid ULID"},
+				{Name: "name", Rule: "type", Params: []string{"string"}, Reason: "must be a string"},
+			},
+		},
+		"legacy route": {
+			method:     http.MethodGet,
+			path:       "/projects/42",
+			wantParams: []model.InvalidParam{{Name: "projectId", Rule: "ulid", Reason: "must be a valid ULID"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			rec := ts.doWithHeader(t, tt.method, tt.path, ts.adminToken, tt.body, tt.header)
+
+			// verify
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			problem := decode[model.Problem](t, rec)
+			assert.Equal(t, model.ProblemTypeValidation, problem.Type)
+			assert.Equal(t, tt.wantParams, problem.InvalidParams)
+		})
+	}
+
+	t.Run("valid request reaches the handler", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, map[string]any{"name": "Valid"})
+
+		// verify
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+
+	t.Run("undocumented media types are left to the handlers", func(t *testing.T) {
+		// execute
+		rec := ts.doWithHeader(t, http.MethodPost, "/api/v1/projects", ts.adminToken, `name=x`, http.Header{"Content-Type": {"application/x-www-form-urlencoded"}})
+
+		// verify
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		assert.Equal(t, model.ProblemTypeDefault, decode[model.Problem](t, rec).Type)
+	})
+
+	t.Run("unknown routes are left to the mux", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodGet, "/api/v1/unknown/42", ts.adminToken, nil)
+
+		// verify
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid requests are authenticated first", func(t *testing.T) {
+		// prepare
+		_, readToken := ts.createUser(t, model.GroupProjectRead)
+
+		// execute
+		anonymousRec := ts.do(t, http.MethodPut, "/api/v1/lists/42", "", map[string]any{"name": 42})
+		invalidTokenRec := ts.do(t, http.MethodPut, "/api/v1/lists/42", "invalid", map[string]any{"name": 42})
+		forbiddenRec := ts.do(t, http.MethodPut, "/api/v1/lists/42", readToken, map[string]any{"name": 42})
+
+		// verify
+		assert.Equal(t, http.StatusUnauthorized, anonymousRec.Code)
+		assert.Equal(t, http.StatusUnauthorized, invalidTokenRec.Code)
+		assert.Equal(t, http.StatusForbidden, forbiddenRec.Code)
+	})
+
+	t.Run("too large body", func(t *testing.T) {
+		// execute
+		rec := ts.do(t, http.MethodPost, "/api/v1/projects", ts.adminToken, `{"name":"`+strings.Repeat("a", 1<<20)+`"}`)
+
+		// verify
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
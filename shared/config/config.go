@@ -0,0 +1,265 @@
+// Package config loads the settings of the servers from a YAML file, environment variables and flags.
+//
+// Sources are applied in the following order, later ones overriding earlier ones:
+//
+//  1. defaults
+//  2. the YAML file given by the -config flag or the CONFIG_FILE environment variable
+//  3. environment variables
+//  4. flags
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/service"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// redacted replaces the values of secrets when the configuration is printed
+const redacted = "[REDACTED]"
+
+// Secret is a setting which must not be printed or logged
+type Secret string
+
+// String returns a placeholder instead of the secret, so that it is not leaked by accident
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// MarshalYAML redacts the secret in the printed configuration
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	API    APIConfig    `yaml:"api"`
+	CORS   CORSConfig   `yaml:"cors"`
+	Auth   AuthConfig   `yaml:"auth"`
+	Users  UsersConfig  `yaml:"users"`
+	Audit  AuditConfig  `yaml:"audit"`
+	Limits LimitsConfig `yaml:"limits"`
+	Admin  AdminConfig  `yaml:"admin"`
+}
+
+// ServerConfig holds the settings of the HTTP server and its shutdown
+type ServerConfig struct {
+	// Addr is the TCP address to listen on
+	Addr string `yaml:"addr" validate:"required,hostname_port"`
+	// ReadHeaderTimeout limits the time to read the request headers, protecting against slow clients
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" validate:"gt=0"`
+	// ReadTimeout limits the time to read the whole request, including the body
+	ReadTimeout time.Duration `yaml:"readTimeout" validate:"gt=0"`
+	// WriteTimeout limits the time from the end of reading the request headers to the end of the response
+	WriteTimeout time.Duration `yaml:"writeTimeout" validate:"gt=0"`
+	// IdleTimeout limits the time to wait for the next request on keep-alive connections
+	IdleTimeout time.Duration `yaml:"idleTimeout" validate:"gt=0"`
+	// MaxHeaderBytes limits the size of the request headers, including the request line
+	MaxHeaderBytes int `yaml:"maxHeaderBytes" validate:"min=1024"`
+	// ShutdownTimeout limits the time to drain connections and run the shutdown hooks
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" validate:"gt=0"`
+}
+
+// APIConfig holds the settings of API routing
+type APIConfig struct {
+	// BasePath is the path the API is served under
+	BasePath string `yaml:"basePath" validate:"omitempty,startswith=/,endsnotwith=/"`
+	// LegacyRoutes keeps serving the API at the root too, with deprecation headers
+	LegacyRoutes bool `yaml:"legacyRoutes"`
+	// Deprecated is when the legacy routes were deprecated
+	Deprecated time.Time `yaml:"deprecated"`
+	// Sunset is when the legacy routes are going to be removed
+	Sunset time.Time `yaml:"sunset" validate:"gtfield=Deprecated"`
+	// IdempotencyTTL is how long the responses of create requests with an Idempotency-Key are replayed to retries
+	IdempotencyTTL time.Duration `yaml:"idempotencyTTL" validate:"min=1m,max=720h"`
+}
+
+// CORSConfig holds the settings of cross-origin requests from browsers, they are rejected if no origin is allowed
+type CORSConfig struct {
+	// AllowedOrigins are origins like "https://app.example.com", "https://*.example.com" for every subdomain, or "*"
+	AllowedOrigins []string `yaml:"allowedOrigins" validate:"dive,required"`
+	// AllowedMethods limits the methods allowed, every method of the requested route is allowed if it is empty
+	AllowedMethods []string `yaml:"allowedMethods" validate:"dive,oneof=GET HEAD POST PUT PATCH DELETE"`
+	// AllowedHeaders are the request headers allowed, the ones the API reads if it is empty
+	AllowedHeaders   []string      `yaml:"allowedHeaders" validate:"dive,required"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge" validate:"min=0"`
+}
+
+// AuthConfig holds the settings of tokens and password hashing
+type AuthConfig struct {
+	// JWTKey signs the tokens, a random key is used if it is empty, so tokens do not survive restarts
+	JWTKey      Secret        `yaml:"jwtKey" validate:"omitempty,min=32"`
+	Issuer      string        `yaml:"issuer" validate:"required"`
+	TokenExpiry time.Duration `yaml:"tokenExpiry" validate:"min=1m,max=720h"`
+	Argon2      Argon2Config  `yaml:"argon2"`
+}
+
+// Argon2Config holds the cost parameters of password hashing, changing them invalidates existing passwords
+type Argon2Config struct {
+	Time    uint32 `yaml:"time" validate:"min=1"`
+	Memory  uint32 `yaml:"memory" validate:"min=8192"`
+	Threads uint8  `yaml:"threads" validate:"min=1"`
+	KeyLen  uint32 `yaml:"keyLen" validate:"min=16"`
+	SaltLen uint32 `yaml:"saltLen" validate:"min=16"`
+}
+
+// UsersConfig holds the settings of the user store and lifecycle
+type UsersConfig struct {
+	// File is the JSON file the users are stored in, they are only kept in memory if it is empty
+	File                string        `yaml:"file"`
+	DeletionGracePeriod time.Duration `yaml:"deletionGracePeriod" validate:"min=0"`
+}
+
+// AuditConfig holds the settings of the audit log
+type AuditConfig struct {
+	// File is the JSON lines file the audit events are appended to, they are only kept in memory if it is empty
+	File string `yaml:"file"`
+}
+
+// LimitsConfig holds the maximum number of items returned by list requests
+type LimitsConfig struct {
+	Projects int `yaml:"projects" validate:"min=1,max=10000"`
+	Lists    int `yaml:"lists" validate:"min=1,max=10000"`
+	Todos    int `yaml:"todos" validate:"min=1,max=10000"`
+	Users    int `yaml:"users" validate:"min=1,max=10000"`
+}
+
+// AdminConfig describes the administrator created on first run or by the create-admin command
+type AdminConfig struct {
+	Name  string `yaml:"name" validate:"required"`
+	Email string `yaml:"email" validate:"required,email"`
+	// Password of the administrator, a random one is generated and printed once if neither it nor PasswordFile is set
+	Password     Secret `yaml:"password" validate:"excluded_with=PasswordFile"`
+	PasswordFile string `yaml:"passwordFile"`
+}
+
+// Default returns the configuration used if no other source sets a value. The defaults of the services and repos
+// are used where they have one.
+func Default() Config {
+	userConfig := service.DefaultUserConfig()
+
+	return Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		API: APIConfig{
+			BasePath:     "/api/v1",
+			LegacyRoutes: true,
+			Deprecated:   time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			Sunset:       time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+			// retries of clients which timed out usually happen within minutes, a day covers retries after outages
+			IdempotencyTTL: 24 * time.Hour,
+		},
+		CORS: CORSConfig{
+			MaxAge: 10 * time.Minute,
+		},
+		Auth: AuthConfig{
+			Issuer:      userConfig.TokenIssuer,
+			TokenExpiry: userConfig.TokenExpiry,
+			Argon2: Argon2Config{
+				Time:    userConfig.Argon2.Time,
+				Memory:  userConfig.Argon2.Memory,
+				Threads: userConfig.Argon2.Threads,
+				KeyLen:  userConfig.Argon2.KeyLen,
+				SaltLen: userConfig.Argon2.SaltLen,
+			},
+		},
+		Users: UsersConfig{
+			DeletionGracePeriod: userConfig.DeletionGracePeriod,
+		},
+		Limits: LimitsConfig{
+			Projects: repo.DefaultProjectListLimit,
+			Lists:    repo.DefaultListListLimit,
+			Todos:    repo.DefaultTodoListLimit,
+			Users:    repo.DefaultUserListLimit,
+		},
+		Admin: AdminConfig{
+			Name:  "Administrator",
+			Email: "admin@example.com",
+		},
+	}
+}
+
+// UserConfig returns the settings of the user service
+func (c Config) UserConfig() service.UserConfig {
+	a := c.Auth.Argon2
+
+	return service.UserConfig{
+		JWTSigningKey: []byte(c.Auth.JWTKey),
+		TokenIssuer:   c.Auth.Issuer,
+		TokenExpiry:   c.Auth.TokenExpiry,
+		Argon2: service.Argon2Params{
+			Time:    a.Time,
+			Memory:  a.Memory,
+			Threads: a.Threads,
+			KeyLen:  a.KeyLen,
+			SaltLen: a.SaltLen,
+		},
+		DeletionGracePeriod: c.Users.DeletionGracePeriod,
+	}
+}
+
+// validate reports settings by their YAML paths, so that errors can be matched to the config file
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// Validate reports every invalid setting
+func (c Config) Validate() error {
+	err := validate.Struct(c)
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	msgs := make([]string, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		// The namespace starts with the name of the struct, which is not part of the YAML path
+		_, path, _ := strings.Cut(fe.Namespace(), ".")
+		msgs = append(msgs, fmt.Sprintf("%s must satisfy %s", path, strings.TrimSuffix(fe.Tag()+"="+fe.Param(), "=")))
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(msgs, ", "))
+}
+
+// String returns the configuration as YAML, with secrets redacted
+func (c Config) String() string {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(out)
+}
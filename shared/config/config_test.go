@@ -0,0 +1,129 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	// execute
+	cfg := config.Default()
+
+	// verify
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Empty(t, cfg.Auth.JWTKey)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		modify  func(*config.Config)
+		wantErr string
+	}{
+		"valid jwt key": {
+			modify: func(c *config.Config) { c.Auth.JWTKey = "0123456789abcdef0123456789abcdef" },
+		},
+		"short jwt key": {
+			modify:  func(c *config.Config) { c.Auth.JWTKey = "secret" },
+			wantErr: "auth.jwtKey must satisfy min=32",
+		},
+		"missing address": {
+			modify:  func(c *config.Config) { c.Server.Addr = "" },
+			wantErr: "server.addr must satisfy required",
+		},
+		"malformed address": {
+			modify:  func(c *config.Config) { c.Server.Addr = "localhost" },
+			wantErr: "server.addr must satisfy hostname_port",
+		},
+		"zero timeout": {
+			modify:  func(c *config.Config) { c.Server.WriteTimeout = 0 },
+			wantErr: "server.writeTimeout must satisfy gt",
+		},
+		"weak argon2 parameters": {
+			modify:  func(c *config.Config) { c.Auth.Argon2.Memory = 1024 },
+			wantErr: "auth.argon2.memory must satisfy min=8192",
+		},
+		"unknown cors method": {
+			modify:  func(c *config.Config) { c.CORS.AllowedMethods = []string{"GET", "TRACE"} },
+			wantErr: "cors.allowedMethods[1] must satisfy oneof=GET HEAD POST PUT PATCH DELETE",
+		},
+		"short idempotency ttl": {
+			modify:  func(c *config.Config) { c.API.IdempotencyTTL = time.Second },
+			wantErr: "api.idempotencyTTL must satisfy min=1m",
+		},
+		"malformed admin email": {
+			modify:  func(c *config.Config) { c.Admin.Email = "admin" },
+			wantErr: "admin.email must satisfy email",
+		},
+		"every invalid setting is reported": {
+			modify: func(c *config.Config) {
+				c.Limits.Todos = 0
+				c.Auth.TokenExpiry = time.Second
+			},
+			wantErr: "auth.tokenExpiry must satisfy min=1m, limits.todos must satisfy min=1",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// prepare
+			cfg := config.Default()
+			tt.modify(&cfg)
+
+			// execute
+			err := cfg.Validate()
+
+			// verify
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, config.ErrInvalidConfig)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	// prepare
+	cfg := config.Default()
+	cfg.Auth.JWTKey = "0123456789abcdef0123456789abcdef"
+	cfg.Admin.Password = "correct horse battery staple"
+
+	// execute
+	out := cfg.String()
+
+	// verify
+	assert.NotContains(t, out, "0123456789abcdef")
+	assert.NotContains(t, out, "correct horse battery staple")
+	assert.Contains(t, out, "jwtKey: '[REDACTED]'")
+	assert.Contains(t, out, "tokenExpiry: 1h0m0s")
+	assert.Contains(t, out, "addr: :8080")
+}
+
+func TestSecret(t *testing.T) {
+	assert.Equal(t, "[REDACTED]", config.Secret("secret").String())
+	assert.Equal(t, "", config.Secret("").String())
+}
+
+func TestConfig_UserConfig(t *testing.T) {
+	// prepare
+	cfg := config.Default()
+	cfg.Auth.JWTKey = "0123456789abcdef0123456789abcdef"
+	cfg.Auth.Argon2.Time = 3
+	cfg.Users.DeletionGracePeriod = time.Hour
+
+	// execute
+	uc := cfg.UserConfig()
+
+	// verify
+	assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), uc.JWTSigningKey)
+	assert.Equal(t, cfg.Auth.Issuer, uc.TokenIssuer)
+	assert.Equal(t, cfg.Auth.TokenExpiry, uc.TokenExpiry)
+	assert.Equal(t, uint32(3), uc.Argon2.Time)
+	assert.Equal(t, time.Hour, uc.DeletionGracePeriod)
+}
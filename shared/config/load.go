@@ -0,0 +1,305 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a value which can be set from environment variables and flags
+type setting struct {
+	// env is the name of the environment variable
+	env string
+	// flag is the name of the flag
+	flag  string
+	usage string
+	// get formats the value in cfg
+	get func(cfg *Config) string
+	// set parses and stores the value in cfg
+	set func(cfg *Config, value string) error
+	// isBool allows using the flag without a value
+	isBool bool
+}
+
+func stringSetting(env, flagName, usage string, field func(*Config) *string) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return *field(cfg)
+	}, func(cfg *Config, value string) error {
+		*field(cfg) = value
+		return nil
+	}, false}
+}
+
+func secretSetting(env, flagName, usage string, field func(*Config) *Secret) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return field(cfg).String()
+	}, func(cfg *Config, value string) error {
+		*field(cfg) = Secret(value)
+		return nil
+	}, false}
+}
+
+func durationSetting(env, flagName, usage string, field func(*Config) *time.Duration) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return field(cfg).String()
+	}, func(cfg *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = d
+		return nil
+	}, false}
+}
+
+func boolSetting(env, flagName, usage string, field func(*Config) *bool) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return strconv.FormatBool(*field(cfg))
+	}, func(cfg *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = b
+		return nil
+	}, true}
+}
+
+// listSetting parses comma-separated lists, an empty value is an empty list
+func listSetting(env, flagName, usage string, field func(*Config) *[]string) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return strings.Join(*field(cfg), ",")
+	}, func(cfg *Config, value string) error {
+		var list []string
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(cfg) = list
+		return nil
+	}, false}
+}
+
+// dateSetting parses dates like 2006-01-02, in UTC
+func dateSetting(env, flagName, usage string, field func(*Config) *time.Time) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return field(cfg).Format(time.DateOnly)
+	}, func(cfg *Config, value string) error {
+		d, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = d
+		return nil
+	}, false}
+}
+
+func intSetting(env, flagName, usage string, field func(*Config) *int) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return strconv.Itoa(*field(cfg))
+	}, func(cfg *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = n
+		return nil
+	}, false}
+}
+
+func uintSetting[T uint8 | uint32](env, flagName, usage string, field func(*Config) *T) setting {
+	return setting{env, flagName, usage, func(cfg *Config) string {
+		return strconv.FormatUint(uint64(*field(cfg)), 10)
+	}, func(cfg *Config, value string) error {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if uint64(T(n)) != n {
+			return fmt.Errorf("value out of range: %s", value)
+		}
+		*field(cfg) = T(n)
+		return nil
+	}, false}
+}
+
+// settings lists every setting which can be set from environment variables and flags
+var settings = []setting{
+	stringSetting("ADDR", "addr", "TCP address to listen on", func(c *Config) *string { return &c.Server.Addr }),
+	durationSetting("READ_HEADER_TIMEOUT", "read-header-timeout", "maximum time to read the request headers", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("READ_TIMEOUT", "read-timeout", "maximum time to read the whole request", func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationSetting("WRITE_TIMEOUT", "write-timeout", "maximum time to write the response", func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationSetting("IDLE_TIMEOUT", "idle-timeout", "maximum time to wait for the next request on keep-alive connections", func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	intSetting("MAX_HEADER_BYTES", "max-header-bytes", "maximum size of the request headers", func(c *Config) *int { return &c.Server.MaxHeaderBytes }),
+	durationSetting("SHUTDOWN_TIMEOUT", "shutdown-timeout", "maximum time to drain connections and release resources on shutdown", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+
+	stringSetting("BASE_PATH", "base-path", "path the API is served under, empty for the root", func(c *Config) *string { return &c.API.BasePath }),
+	boolSetting("LEGACY_ROUTES", "legacy-routes", "serve the API at the root too, with deprecation headers", func(c *Config) *bool { return &c.API.LegacyRoutes }),
+	dateSetting("LEGACY_ROUTES_DEPRECATED", "legacy-routes-deprecated", "date the legacy routes were deprecated", func(c *Config) *time.Time { return &c.API.Deprecated }),
+	dateSetting("LEGACY_ROUTES_SUNSET", "legacy-routes-sunset", "date the legacy routes are going to be removed", func(c *Config) *time.Time { return &c.API.Sunset }),
+	durationSetting("IDEMPOTENCY_TTL", "idempotency-ttl", "time the responses of create requests with an Idempotency-Key are replayed to retries", func(c *Config) *time.Duration { return &c.API.IdempotencyTTL }),
+
+	listSetting("CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "comma-separated origins allowed to call the API from browsers", func(c *Config) *[]string { return &c.CORS.AllowedOrigins }),
+	listSetting("CORS_ALLOWED_METHODS", "cors-allowed-methods", "comma-separated methods allowed from other origins, every method if empty", func(c *Config) *[]string { return &c.CORS.AllowedMethods }),
+	listSetting("CORS_ALLOWED_HEADERS", "cors-allowed-headers", "comma-separated request headers allowed from other origins, the ones the API reads if empty", func(c *Config) *[]string { return &c.CORS.AllowedHeaders }),
+	boolSetting("CORS_ALLOW_CREDENTIALS", "cors-allow-credentials", "let browsers send credentials to other origins", func(c *Config) *bool { return &c.CORS.AllowCredentials }),
+	durationSetting("CORS_MAX_AGE", "cors-max-age", "time browsers may cache preflight responses", func(c *Config) *time.Duration { return &c.CORS.MaxAge }),
+
+	secretSetting("JWT_KEY", "jwt-key", "key signing the tokens, at least 32 bytes, random if empty", func(c *Config) *Secret { return &c.Auth.JWTKey }),
+	stringSetting("JWT_ISSUER", "jwt-issuer", "issuer of the tokens", func(c *Config) *string { return &c.Auth.Issuer }),
+	durationSetting("TOKEN_EXPIRY", "token-expiry", "lifetime of the tokens", func(c *Config) *time.Duration { return &c.Auth.TokenExpiry }),
+	uintSetting("ARGON2_TIME", "argon2-time", "number of Argon2 passes", func(c *Config) *uint32 { return &c.Auth.Argon2.Time }),
+	uintSetting("ARGON2_MEMORY", "argon2-memory", "memory used by Argon2 in KiB", func(c *Config) *uint32 { return &c.Auth.Argon2.Memory }),
+	uintSetting("ARGON2_THREADS", "argon2-threads", "number of Argon2 threads", func(c *Config) *uint8 { return &c.Auth.Argon2.Threads }),
+	uintSetting("ARGON2_KEY_LEN", "argon2-key-len", "length of the Argon2 hashes in bytes", func(c *Config) *uint32 { return &c.Auth.Argon2.KeyLen }),
+	uintSetting("ARGON2_SALT_LEN", "argon2-salt-len", "length of the password salts in bytes", func(c *Config) *uint32 { return &c.Auth.Argon2.SaltLen }),
+
+	stringSetting("USERS_FILE", "users-file", "JSON file the users are stored in, in memory only if empty", func(c *Config) *string { return &c.Users.File }),
+	durationSetting("USER_DELETION_GRACE_PERIOD", "user-deletion-grace-period", "time a deleted user can be restored before being anonymized", func(c *Config) *time.Duration { return &c.Users.DeletionGracePeriod }),
+
+	stringSetting("AUDIT_LOG_FILE", "audit-log-file", "JSON lines file the audit events are appended to, in memory only if empty", func(c *Config) *string { return &c.Audit.File }),
+
+	intSetting("PROJECT_LIST_LIMIT", "project-list-limit", "maximum number of projects returned per request", func(c *Config) *int { return &c.Limits.Projects }),
+	intSetting("LIST_LIST_LIMIT", "list-list-limit", "maximum number of lists returned per request", func(c *Config) *int { return &c.Limits.Lists }),
+	intSetting("TODO_LIST_LIMIT", "todo-list-limit", "maximum number of todo items returned per request", func(c *Config) *int { return &c.Limits.Todos }),
+	intSetting("USER_LIST_LIMIT", "user-list-limit", "maximum number of users returned per request", func(c *Config) *int { return &c.Limits.Users }),
+
+	stringSetting("ADMIN_NAME", "admin-name", "name of the administrator", func(c *Config) *string { return &c.Admin.Name }),
+	stringSetting("ADMIN_EMAIL", "admin-email", "email of the administrator", func(c *Config) *string { return &c.Admin.Email }),
+	secretSetting("ADMIN_PASSWORD", "admin-password", "password of the administrator, a random one is generated if neither it nor the password file is set", func(c *Config) *Secret { return &c.Admin.Password }),
+	stringSetting("ADMIN_PASSWORD_FILE", "admin-password-file", "file containing the password of the administrator", func(c *Config) *string { return &c.Admin.PasswordFile }),
+}
+
+// flagValue collects the value of a flag, to be applied after the config file and the environment variables.
+// It starts out as the default value, so that it is shown in the usage.
+type flagValue struct {
+	s     setting
+	value *string
+}
+
+func (v flagValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+
+	return *v.value
+}
+
+// IsBoolFlag allows boolean flags to be given without a value
+func (v flagValue) IsBoolFlag() bool {
+	return v.s.isBool
+}
+
+// Set validates the value right away, so that malformed flags are reported like other flag errors
+func (v flagValue) Set(value string) error {
+	var cfg Config
+	if err := v.s.set(&cfg, value); err != nil {
+		return err
+	}
+	*v.value = value
+
+	return nil
+}
+
+// Loader loads the configuration from the sources registered by RegisterFlags
+type Loader struct {
+	configFile  *string
+	printConfig *bool
+	flags       []flagValue
+	fs          *flag.FlagSet
+}
+
+// RegisterFlags registers the flags of every setting on fs. Load must be called once fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		configFile:  fs.String("config", "", "YAML config file (env: CONFIG_FILE)"),
+		printConfig: fs.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit"),
+		fs:          fs,
+	}
+
+	defaults := Default()
+	for _, s := range settings {
+		value := s.get(&defaults)
+		v := flagValue{s: s, value: &value}
+		fs.Var(v, s.flag, fmt.Sprintf("%s (env: %s)", s.usage, s.env))
+		l.flags = append(l.flags, v)
+	}
+
+	return l
+}
+
+// PrintRequested reports whether the -print-config flag was given
+func (l *Loader) PrintRequested() bool {
+	return *l.printConfig
+}
+
+// Load returns the validated configuration, merging the defaults, the config file, the environment variables
+// looked up by lookupEnv, and the flags, in this order of precedence.
+func (l *Loader) Load(lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	path := *l.configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		value, ok := lookupEnv(s.env)
+		if !ok {
+			continue
+		}
+		if err := s.set(&cfg, value); err != nil {
+			return Config{}, fmt.Errorf("%w: invalid environment variable: %s, err: %w", ErrInvalidConfig, s.env, err)
+		}
+	}
+
+	set := map[string]bool{}
+	l.fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, v := range l.flags {
+		if !set[v.s.flag] {
+			continue
+		}
+		if err := v.s.set(&cfg, *v.value); err != nil {
+			return Config{}, fmt.Errorf("%w: invalid flag: %s, err: %w", ErrInvalidConfig, v.s.flag, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile overrides the settings in cfg with the ones in the YAML file. Unknown settings are rejected, as they
+// are most likely typos.
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %s, err: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: invalid config file: %s, err: %w", ErrInvalidConfig, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package config_test
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// load parses args and loads the configuration with the given environment variables
+func load(t *testing.T, args []string, env map[string]string) (config.Config, error) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	loader := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return config.Config{}, err
+	}
+
+	return loader.Load(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+}
+
+// writeFile writes a config file to a temporary directory and returns its path
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoader_Load(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		// execute
+		cfg, err := load(t, nil, nil)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, config.Default(), cfg)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		// prepare
+		path := writeFile(t, `
+server:
+  addr: ":9000"
+  writeTimeout: 10s
+auth:
+  issuer: file
+limits:
+  todos: 10
+audit:
+  file: /var/log/todo/file.jsonl
+admin:
+  name: File Admin
+  email: file@example.com
+`)
+		env := map[string]string{
+			"CONFIG_FILE":   path,
+			"WRITE_TIMEOUT": "20s",
+			"JWT_ISSUER":    "env",
+			"ADMIN_EMAIL":   "env@example.com",
+		}
+		args := []string{"-jwt-issuer", "flag", "-audit-log-file", "/var/log/todo/flag.jsonl", "-admin-email", "flag@example.com"}
+
+		// execute
+		cfg, err := load(t, args, env)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, ":9000", cfg.Server.Addr, "file overrides defaults")
+		assert.Equal(t, 10, cfg.Limits.Todos, "file overrides defaults")
+		assert.Equal(t, 20*time.Second, cfg.Server.WriteTimeout, "env overrides file")
+		assert.Equal(t, "flag", cfg.Auth.Issuer, "flags override env")
+		assert.Equal(t, "/var/log/todo/flag.jsonl", cfg.Audit.File, "flags override file")
+		assert.Equal(t, "File Admin", cfg.Admin.Name, "file overrides defaults")
+		assert.Equal(t, "flag@example.com", cfg.Admin.Email, "flags override env")
+		assert.Equal(t, config.Default().Server.ReadTimeout, cfg.Server.ReadTimeout, "unset values keep their defaults")
+	})
+
+	t.Run("config flag wins over CONFIG_FILE", func(t *testing.T) {
+		// prepare
+		flagPath := writeFile(t, "server:\n  addr: \":9001\"\n")
+		env := map[string]string{"CONFIG_FILE": filepath.Join(t.TempDir(), "missing.yaml")}
+
+		// execute
+		cfg, err := load(t, []string{"-config", flagPath}, env)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, ":9001", cfg.Server.Addr)
+	})
+
+	t.Run("every type of setting", func(t *testing.T) {
+		// prepare
+		env := map[string]string{
+			"JWT_KEY":                    "0123456789abcdef0123456789abcdef",
+			"ARGON2_THREADS":             "2",
+			"ARGON2_MEMORY":              "32768",
+			"MAX_HEADER_BYTES":           "4096",
+			"USER_DELETION_GRACE_PERIOD": "24h",
+			"USERS_FILE":                 "/var/lib/todo/users.json",
+			"AUDIT_LOG_FILE":             "/var/log/todo/audit.jsonl",
+			"LEGACY_ROUTES_SUNSET":       "2030-01-02",
+			"IDEMPOTENCY_TTL":            "1h",
+			"CORS_ALLOWED_ORIGINS":       "https://app.example.com, https://*.example.org,",
+			"ADMIN_PASSWORD":             "correct horse battery staple",
+		}
+
+		// execute
+		cfg, err := load(t, nil, env)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, config.Secret("0123456789abcdef0123456789abcdef"), cfg.Auth.JWTKey)
+		assert.Equal(t, uint8(2), cfg.Auth.Argon2.Threads)
+		assert.Equal(t, uint32(32768), cfg.Auth.Argon2.Memory)
+		assert.Equal(t, 4096, cfg.Server.MaxHeaderBytes)
+		assert.Equal(t, 24*time.Hour, cfg.Users.DeletionGracePeriod)
+		assert.Equal(t, "/var/lib/todo/users.json", cfg.Users.File)
+		assert.Equal(t, "/var/log/todo/audit.jsonl", cfg.Audit.File)
+		assert.Equal(t, time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC), cfg.API.Sunset)
+		assert.Equal(t, time.Hour, cfg.API.IdempotencyTTL)
+		assert.Equal(t, []string{"https://app.example.com", "https://*.example.org"}, cfg.CORS.AllowedOrigins)
+		assert.Equal(t, config.Secret("correct horse battery staple"), cfg.Admin.Password)
+	})
+
+	t.Run("boolean flags", func(t *testing.T) {
+		// execute
+		disabled, err := load(t, []string{"-legacy-routes=false"}, map[string]string{"LEGACY_ROUTES": "true"})
+		require.NoError(t, err)
+		enabled, err := load(t, []string{"-legacy-routes"}, map[string]string{"LEGACY_ROUTES": "false"})
+		require.NoError(t, err)
+
+		// verify
+		assert.False(t, disabled.API.LegacyRoutes)
+		assert.True(t, enabled.API.LegacyRoutes)
+	})
+
+	t.Run("print-config", func(t *testing.T) {
+		// prepare
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		loader := config.RegisterFlags(fs)
+
+		// execute
+		require.NoError(t, fs.Parse([]string{"-print-config"}))
+
+		// verify
+		assert.True(t, loader.PrintRequested())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]struct {
+			args    []string
+			env     map[string]string
+			file    string
+			wantErr string
+		}{
+			"malformed flag": {
+				args:    []string{"-write-timeout", "soon"},
+				wantErr: `invalid value "soon" for flag -write-timeout`,
+			},
+			"malformed env": {
+				env:     map[string]string{"TODO_LIST_LIMIT": "many"},
+				wantErr: "invalid environment variable: TODO_LIST_LIMIT",
+			},
+			"out of range env": {
+				env:     map[string]string{"ARGON2_THREADS": "256"},
+				wantErr: "value out of range",
+			},
+			"unknown file setting": {
+				file:    "server:\n  adr: \":9000\"\n",
+				wantErr: "field adr not found",
+			},
+			"invalid value": {
+				args:    []string{"-jwt-key", "short"},
+				wantErr: "auth.jwtKey must satisfy min=32",
+			},
+			"sunset before deprecation": {
+				args:    []string{"-legacy-routes-sunset", "2020-01-01"},
+				wantErr: "api.sunset must satisfy gtfield=Deprecated",
+			},
+			"base path with trailing slash": {
+				env:     map[string]string{"BASE_PATH": "/api/"},
+				wantErr: "api.basePath must satisfy endsnotwith=/",
+			},
+			"admin password and password file": {
+				env:     map[string]string{"ADMIN_PASSWORD": "correct horse battery staple"},
+				args:    []string{"-admin-password-file", "/run/secrets/admin-password"},
+				wantErr: "admin.password must satisfy excluded_with=PasswordFile",
+			},
+			"missing file": {
+				env:     map[string]string{"CONFIG_FILE": "/nonexistent/config.yaml"},
+				wantErr: "failed to read config file",
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// prepare
+				env := tt.env
+				if tt.file != "" {
+					env = map[string]string{"CONFIG_FILE": writeFile(t, tt.file)}
+				}
+
+				// execute
+				_, err := load(t, tt.args, env)
+
+				// verify
+				assert.ErrorContains(t, err, tt.wantErr)
+			})
+		}
+	})
+}
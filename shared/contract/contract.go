@@ -0,0 +1,382 @@
+// Package contract checks that an implementation of the API serves what its OpenAPI document promises. Every
+// operation of the document is driven with a request built from the examples of the document, and the status,
+// the content type, the headers and the body of the response are validated against the operation.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+)
+
+// Target serves the requests of the contract tests
+type Target interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// handlerTarget serves the requests with an http.Handler, without a network round trip
+type handlerTarget struct {
+	h http.Handler
+}
+
+// Handler returns a target serving the requests with h
+func Handler(h http.Handler) Target {
+	return handlerTarget{h: h}
+}
+
+func (t handlerTarget) Do(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, req)
+
+	return rec.Result(), nil
+}
+
+// serverTarget sends the requests to a running server, so that implementations which are not built on net/http
+// can be tested too
+type serverTarget struct {
+	baseURL string
+	client  *http.Client
+}
+
+// Server returns a target sending the requests to the server at baseURL, like "http://localhost:8080"
+func Server(baseURL string, client *http.Client) Target {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return serverTarget{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+func (t serverTarget) Do(req *http.Request) (*http.Response, error) {
+	u, err := req.URL.Parse(t.baseURL + req.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+	req.URL = u
+	req.Host = u.Host
+	req.RequestURI = ""
+
+	return t.client.Do(req)
+}
+
+// Fixture holds what can not be derived from the document, like credentials and the IDs of existing resources
+type Fixture struct {
+	// BasePath is the path the API is served under, like "/api/v1"
+	BasePath string
+	// Header is sent with every request, like the Authorization header
+	Header http.Header
+	// PathParams are the values of the path parameters by name, a random ULID is used for missing ones
+	PathParams map[string]string
+	// Bodies override the bodies generated from the examples of the document by operation ID
+	Bodies map[string]any
+	// Skip lists the IDs of the operations which are not driven
+	Skip []string
+}
+
+// Finding is a difference between the document and the implementation
+type Finding struct {
+	// Operation is the ID of the operation, or the method and the path of undocumented routes
+	Operation string
+	Message   string
+}
+
+func (f Finding) String() string {
+	return f.Operation + ": " + f.Message
+}
+
+// probedMethods are tried on every path of the document to find undocumented routes
+var probedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// methodOrder drives the operations which change or remove resources last, so that the other operations find
+// the resources of the fixture
+var methodOrder = map[string]int{"get": 0, "post": 1, "put": 2, "patch": 3, "delete": 4}
+
+// Test drives every operation of the spec against the target, reporting each finding as an error of the subtest
+// of the operation
+func Test(t *testing.T, target Target, spec *openapi.Spec, fixture Fixture) {
+	t.Helper()
+
+	findings := Check(target, spec, fixture)
+
+	for _, op := range operationIDs(findings) {
+		t.Run(op, func(t *testing.T) {
+			for _, f := range findings {
+				if f.Operation == op {
+					t.Error(f.Message)
+				}
+			}
+		})
+	}
+}
+
+func operationIDs(findings []Finding) []string {
+	var ids []string
+	for _, f := range findings {
+		if !slices.Contains(ids, f.Operation) {
+			ids = append(ids, f.Operation)
+		}
+	}
+
+	return ids
+}
+
+// Check drives every operation of the spec against the target and returns the findings: undocumented statuses,
+// content types and routes, and responses not matching their schemas
+func Check(target Target, spec *openapi.Spec, fixture Fixture) []Finding {
+	var findings []Finding
+
+	for _, path := range slices.Sorted(maps.Keys(spec.Paths)) {
+		for _, method := range probedMethods {
+			if _, ok := spec.Paths[path].Operations[strings.ToLower(method)]; ok {
+				continue
+			}
+			findings = append(findings, checkUndocumented(target, fixture, method, path)...)
+		}
+	}
+
+	type operation struct {
+		path, method string
+		op           *openapi.Operation
+	}
+	var ops []operation
+	for path, item := range spec.Paths {
+		for method, op := range item.Operations {
+			if _, ok := methodOrder[method]; ok && !slices.Contains(fixture.Skip, op.OperationID) {
+				ops = append(ops, operation{path, method, op})
+			}
+		}
+	}
+	slices.SortFunc(ops, func(a, b operation) int {
+		if methodOrder[a.method] != methodOrder[b.method] {
+			return methodOrder[a.method] - methodOrder[b.method]
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	for _, o := range ops {
+		name := o.op.OperationID
+		if name == "" {
+			name = strings.ToUpper(o.method) + " " + o.path
+		}
+
+		for _, message := range checkOperation(target, spec, fixture, o.method, o.path, o.op) {
+			findings = append(findings, Finding{Operation: name, Message: message})
+		}
+	}
+
+	return findings
+}
+
+// checkUndocumented reports the method of the path if the target serves it
+func checkUndocumented(target Target, fixture Fixture, method, path string) []Finding {
+	req := httptest.NewRequest(method, fixture.BasePath+fillPath(path, fixture.PathParams), nil)
+	for key, values := range fixture.Header {
+		req.Header[key] = values
+	}
+
+	resp, err := target.Do(req)
+	if err != nil {
+		return []Finding{{Operation: method + " " + path, Message: fmt.Sprintf("request failed: %v", err)}}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil
+	}
+
+	return []Finding{{Operation: method + " " + path, Message: fmt.Sprintf("undocumented route served with status %d", resp.StatusCode)}}
+}
+
+// checkOperation sends the request of the operation and returns the differences of the response
+func checkOperation(target Target, spec *openapi.Spec, fixture Fixture, method, path string, op *openapi.Operation) []string {
+	req, err := newRequest(spec, fixture, method, path, op)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to build request: %v", err)}
+	}
+
+	resp, err := target.Do(req)
+	if err != nil {
+		return []string{fmt.Sprintf("request failed: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to read response: %v", err)}
+	}
+
+	return checkResponse(op, resp, body)
+}
+
+// newRequest builds the request of the operation from the fixture and the examples of the document
+func newRequest(spec *openapi.Spec, fixture Fixture, method, path string, op *openapi.Operation) (*http.Request, error) {
+	query := url.Values{}
+	header := http.Header{}
+	for _, p := range spec.Parameters(path, op) {
+		if !p.Required || p.In == "path" {
+			continue
+		}
+		value := fmt.Sprint(paramExample(p))
+		switch p.In {
+		case "query":
+			query.Set(p.Name, value)
+		case "header":
+			header.Set(p.Name, value)
+		}
+	}
+
+	target := fixture.BasePath + fillPath(path, fixture.PathParams)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var (
+		body        io.Reader
+		contentType string
+	)
+	if op.RequestBody != nil && len(op.RequestBody.Content) > 0 {
+		contentType = requestMediaType(op.RequestBody)
+		value, ok := fixture.Bodies[op.OperationID]
+		if !ok {
+			value = mediaExample(op.RequestBody.Content[contentType])
+		}
+		content, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(content)
+	}
+
+	req := httptest.NewRequest(strings.ToUpper(method), target, body)
+	for key, values := range fixture.Header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// requestMediaType returns the media type the request body is sent as, JSON if documented
+func requestMediaType(body *openapi.RequestBody) string {
+	if _, ok := body.Content["application/json"]; ok {
+		return "application/json"
+	}
+
+	return slices.Sorted(maps.Keys(body.Content))[0]
+}
+
+// fillPath replaces the parameters of the path with their values
+func fillPath(path string, params map[string]string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		value, ok := params[name]
+		if !ok {
+			value = "01K02SD13A5YKWWZFV9AQP7H1X"
+		}
+		segments[i] = value
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// findResponse returns the response documented for the status, like "404", "4XX" or "default"
+func findResponse(op *openapi.Operation, status int) (*openapi.Response, bool) {
+	for _, key := range []string{strconv.Itoa(status), strconv.Itoa(status/100) + "XX", "default"} {
+		if resp, ok := op.Responses[key]; ok {
+			return resp, true
+		}
+	}
+
+	return nil, false
+}
+
+// checkResponse returns the differences between the response and the documented ones
+func checkResponse(op *openapi.Operation, resp *http.Response, body []byte) []string {
+	documented, ok := findResponse(op, resp.StatusCode)
+	if !ok {
+		message := fmt.Sprintf("undocumented status %d", resp.StatusCode)
+		if len(body) > 0 {
+			message += ": " + truncate(body)
+		}
+		return []string{message}
+	}
+
+	var messages []string
+	for _, name := range slices.Sorted(maps.Keys(documented.Headers)) {
+		value := resp.Header.Get(name)
+		if value == "" || documented.Headers[name] == nil {
+			continue
+		}
+		for _, v := range documented.Headers[name].Schema.ValidateString(name, value) {
+			messages = append(messages, fmt.Sprintf("status %d: header %s %s", resp.StatusCode, v.Name, v.Reason))
+		}
+	}
+
+	if len(documented.Content) == 0 {
+		if len(body) > 0 {
+			messages = append(messages, fmt.Sprintf("status %d: undocumented body: %s", resp.StatusCode, truncate(body)))
+		}
+		return messages
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return append(messages, fmt.Sprintf("status %d: invalid content type %q", resp.StatusCode, resp.Header.Get("Content-Type")))
+	}
+	media, ok := documented.Content[mediaType]
+	if !ok {
+		return append(messages, fmt.Sprintf("status %d: undocumented content type %s", resp.StatusCode, mediaType))
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return messages
+	}
+
+	var value any
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return append(messages, fmt.Sprintf("status %d: malformed JSON body: %v", resp.StatusCode, err))
+	}
+
+	for _, v := range media.Schema.Validate(value) {
+		name := v.Name
+		if name == "" {
+			name = "body"
+		}
+		messages = append(messages, fmt.Sprintf("status %d: %s %s", resp.StatusCode, name, v.Reason))
+	}
+
+	return messages
+}
+
+// truncate shortens bodies quoted in the findings
+func truncate(body []byte) string {
+	const limit = 200
+	if len(body) > limit {
+		return string(body[:limit]) + "…"
+	}
+
+	return string(body)
+}
@@ -0,0 +1,189 @@
+package contract_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/contract"
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `openapi: 3.0.3
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - name: q
+          in: query
+          required: true
+          schema:
+            type: string
+            example: milk
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-Total-Count:
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Item'
+    post:
+      operationId: createItem
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+      responses:
+        '201':
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+        '4XX':
+          description: problem
+          content:
+            application/problem+json:
+              schema:
+                type: object
+  /items/{itemId}:
+    delete:
+      operationId: deleteItem
+      responses:
+        '204':
+          description: deleted
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        name:
+          type: string
+          example: Milk
+      required:
+        - name
+`
+
+func parseSpec(t *testing.T) *openapi.Spec {
+	t.Helper()
+
+	doc, err := openapi.Parse([]byte(testSpec))
+	require.NoError(t, err)
+	spec, err := doc.Spec()
+	require.NoError(t, err)
+
+	return spec
+}
+
+// conformingHandler serves what the test document promises
+func conformingHandler(t *testing.T) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/items", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "milk", r.URL.Query().Get("q"), "required parameters are sent with their examples")
+		assert.Equal(t, "secret", r.Header.Get("X-Token"), "the header of the fixture is sent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "1")
+		_, _ = w.Write([]byte(`[{"id":"1","name":"Milk"}]`))
+	})
+	mux.HandleFunc("POST /api/items", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"name": "Milk"}, body, "bodies are generated from the examples, without read-only fields")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"2","name":"Milk"}`))
+	})
+	mux.HandleFunc("DELETE /api/items/{itemId}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "42", r.PathValue("itemId"), "path parameters are taken from the fixture")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+var testFixture = contract.Fixture{
+	BasePath:   "/api",
+	Header:     http.Header{"X-Token": {"secret"}},
+	PathParams: map[string]string{"itemId": "42"},
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("conforming handler", func(t *testing.T) {
+		// execute
+		findings := contract.Check(contract.Handler(conformingHandler(t)), parseSpec(t), testFixture)
+
+		// verify
+		assert.Empty(t, findings)
+	})
+
+	t.Run("running server", func(t *testing.T) {
+		// prepare
+		server := httptest.NewServer(conformingHandler(t))
+		defer server.Close()
+
+		// execute
+		findings := contract.Check(contract.Server(server.URL, nil), parseSpec(t), testFixture)
+
+		// verify
+		assert.Empty(t, findings)
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		// prepare
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/items", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Total-Count", "many")
+			_, _ = w.Write([]byte(`[{"id":1}]`))
+		})
+		mux.HandleFunc("POST /api/items", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		mux.HandleFunc("DELETE /api/items/{itemId}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		mux.HandleFunc("PUT /api/items/{itemId}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		// execute
+		findings := contract.Check(contract.Handler(mux), parseSpec(t), testFixture)
+
+		// verify
+		assert.Equal(t, []contract.Finding{
+			{Operation: "PUT /items/{itemId}", Message: "undocumented route served with status 200"},
+			{Operation: "listItems", Message: "status 200: header X-Total-Count must be an integer"},
+			{Operation: "listItems", Message: "status 200: [0].name is required"},
+			{Operation: "listItems", Message: "status 200: [0].id must be a string"},
+			{Operation: "createItem", Message: "status 400: undocumented content type text/plain"},
+			{Operation: "deleteItem", Message: "undocumented status 200"},
+		}, findings)
+	})
+
+	t.Run("skipped operations", func(t *testing.T) {
+		// prepare
+		fixture := testFixture
+		fixture.Skip = []string{"listItems", "createItem", "deleteItem"}
+
+		// execute
+		findings := contract.Check(contract.Handler(http.NotFoundHandler()), parseSpec(t), fixture)
+
+		// verify
+		assert.Empty(t, findings)
+	})
+}
@@ -0,0 +1,88 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+)
+
+// maxExampleDepth stops generating examples of recursive schemas
+const maxExampleDepth = 8
+
+// mediaExample returns the example of the media type, generating one from its schema if none is given
+func mediaExample(m *openapi.MediaType) any {
+	if m == nil {
+		return nil
+	}
+	if m.Example != nil {
+		return m.Example
+	}
+
+	return example(m.Schema, 0)
+}
+
+// paramExample returns the example of the parameter, generating one from its schema if none is given
+func paramExample(p *openapi.Parameter) any {
+	if p.Example != nil {
+		return p.Example
+	}
+
+	return example(p.Schema, 0)
+}
+
+// example returns the example of the schema, or a value built from the examples of its properties
+func example(s *openapi.Schema, depth int) any {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if s.Example != nil {
+		return s.Example
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	if len(s.OneOf) > 0 {
+		return example(s.OneOf[0], depth+1)
+	}
+	if len(s.AnyOf) > 0 {
+		return example(s.AnyOf[0], depth+1)
+	}
+	if len(s.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, sub := range s.AllOf {
+			if m, ok := example(sub, depth+1).(map[string]any); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch s.Type {
+	case "object":
+		obj := map[string]any{}
+		for name, p := range s.Properties {
+			if !p.ReadOnly {
+				obj[name] = example(p, depth+1)
+			}
+		}
+		return obj
+	case "array":
+		return []any{example(s.Items, depth+1)}
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	case "string":
+		if s.Format == "date-time" {
+			return time.Now().UTC().Format(time.RFC3339)
+		}
+		return "string"
+	}
+
+	return nil
+}
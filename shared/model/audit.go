@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventLogin           AuditEventType = "auth.login"
+	AuditEventLoginFailed     AuditEventType = "auth.login_failed"
+	AuditEventAccessDenied    AuditEventType = "auth.access_denied"
+	AuditEventUserCreated     AuditEventType = "user.created"
+	AuditEventUserDeleted     AuditEventType = "user.deleted"
+	AuditEventPasswordChanged AuditEventType = "user.password_changed"
+	AuditEventGroupsChanged   AuditEventType = "user.groups_changed"
+	AuditEventStatusChanged   AuditEventType = "user.status_changed"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+	AuditOutcomeDenied  AuditOutcome = "denied"
+)
+
+// AuditEvent is a single, immutable entry of the security audit log.
+// Actor is the ID of the user performing the action, empty for anonymous clients.
+type AuditEvent struct {
+	ID        string         `json:"id" validate:"required,max=26" fake:"{ulid}"`
+	Type      AuditEventType `json:"type" validate:"required,max=64" fake:"{randomstring:[auth.login,auth.login_failed,auth.access_denied]}"`
+	Actor     string         `json:"actor,omitempty" validate:"max=255" fake:"{ulid}"`
+	Target    string         `json:"target,omitempty" validate:"max=255" fake:"{ulid}"`
+	IP        string         `json:"ip,omitempty" validate:"omitempty,ip" fake:"{ipv4address}"`
+	Outcome   AuditOutcome   `json:"outcome" validate:"required,oneof=success failure denied" fake:"{randomstring:[success,failure,denied]}"`
+	Reason    string         `json:"reason,omitempty" validate:"max=255" fake:"{sentence:3}"`
+	Timestamp time.Time      `json:"timestamp" validate:"required"`
+}
+
+// AuditEventFilter narrows down the events returned by an audit sink.
+// Zero values are ignored, After is an event ID used for paging through the log.
+type AuditEventFilter struct {
+	Type    AuditEventType
+	Actor   string
+	Target  string
+	Outcome AuditOutcome
+	Since   time.Time
+	Until   time.Time
+	After   string
+	Limit   int
+}
+
+// Matches reports whether the event satisfies every non-zero field of the filter. Limit is ignored.
+func (f AuditEventFilter) Matches(e AuditEvent) bool {
+	if f.Type != "" && f.Type != e.Type {
+		return false
+	}
+	if f.Actor != "" && f.Actor != e.Actor {
+		return false
+	}
+	if f.Target != "" && f.Target != e.Target {
+		return false
+	}
+	if f.Outcome != "" && f.Outcome != e.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.After != "" && e.ID <= f.After {
+		return false
+	}
+
+	return true
+}
+
+// AuditEvent validation methods
+func (e *AuditEvent) Validate() error {
+	return validate.Struct(e)
+}
+
+// Random generation methods for AuditEvent
+func RandomAuditEvent() AuditEvent {
+	var e AuditEvent
+
+	err := gofakeit.Struct(&e)
+	if err != nil {
+		panic(err)
+	}
+
+	e.Timestamp = time.Now().UTC()
+
+	return e
+}
@@ -0,0 +1,22 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomAuditEvent(t *testing.T) {
+	// execute
+	got1 := model.RandomAuditEvent()
+	got2 := model.RandomAuditEvent()
+
+	// verify
+	assert.NotEmpty(t, got1.ID)
+	assert.NotEmpty(t, got1.Type)
+	assert.NotEmpty(t, got1.Outcome)
+	assert.NotEqual(t, got1.ID, got2.ID)
+	assert.NoError(t, got1.Validate())
+	assert.NoError(t, got2.Validate())
+}
@@ -7,6 +7,8 @@ type List struct {
 	ProjectID   string `json:"projectId" validate:"required,max=26" fake:"{ulid}"`
 	Name        string `json:"name" validate:"required,max=64" fake:"{sentence:2}"`
 	Description string `json:"description,omitempty" validate:"max=255" fake:"{sentence:4}"`
+	// Version is incremented on every change of the list, it is used for optimistic concurrency control
+	Version int `json:"version" validate:"min=1" fake:"{number:1,100}"`
 }
 
 type ListCreate struct {
@@ -15,8 +17,9 @@ type ListCreate struct {
 	Description string `json:"description,omitempty" validate:"max=255"`
 }
 
+// ListUpdate replaces every field of a list, omitted fields are cleared
 type ListUpdate struct {
-	Name        string `json:"name,omitempty" validate:"max=64"`
+	Name        string `json:"name" validate:"required,max=64"`
 	Description string `json:"description,omitempty" validate:"max=255"`
 }
 
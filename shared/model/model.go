@@ -1,10 +1,34 @@
 package model
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/go-playground/validator/v10"
 	"github.com/oklog/ulid/v2"
 )
 
+// validate reports fields by their JSON names, so that errors can be matched to the request payload
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	return v
+}
+
 func InitFaker() {
 	gofakeit.AddFuncLookup("ulid", gofakeit.Info{
 		Category:    "custom",
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemTypeBaseURI is the prefix of the problem types defined by this API. The URIs identify the problem types
+// and are kept stable, but they are not meant to be dereferenced.
+const ProblemTypeBaseURI = "https://github.com/peteraba/go-frameworks/problems/"
+
+// Problem types with a dedicated meaning. Problems without one use ProblemTypeDefault, as RFC 9457 recommends.
+const (
+	ProblemTypeDefault            = "about:blank"
+	ProblemTypeValidation         = ProblemTypeBaseURI + "validation"
+	ProblemTypeNotFound           = ProblemTypeBaseURI + "not-found"
+	ProblemTypeConflict           = ProblemTypeBaseURI + "conflict"
+	ProblemTypePreconditionFailed = ProblemTypeBaseURI + "precondition-failed"
+	ProblemTypeUnauthorized       = ProblemTypeBaseURI + "unauthorized"
+	ProblemTypeForbidden          = ProblemTypeBaseURI + "forbidden"
+	ProblemTypeRateLimited        = ProblemTypeBaseURI + "rate-limited"
+)
+
+// problemTypes maps the statuses with a dedicated problem type to the type URI
+var problemTypes = map[int]string{
+	http.StatusNotFound:           ProblemTypeNotFound,
+	http.StatusConflict:           ProblemTypeConflict,
+	http.StatusPreconditionFailed: ProblemTypePreconditionFailed,
+	http.StatusUnauthorized:       ProblemTypeUnauthorized,
+	http.StatusForbidden:          ProblemTypeForbidden,
+	http.StatusTooManyRequests:    ProblemTypeRateLimited,
+}
+
+// Problem is an RFC 9457 problem details object, served as application/problem+json
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+}
+
+// InvalidParam describes a single field of the request which failed validation.
+// Name is the JSON path of the field, Rule is the validation rule it broke and Params are the arguments of the rule.
+type InvalidParam struct {
+	Name   string   `json:"name"`
+	Rule   string   `json:"rule"`
+	Params []string `json:"params,omitempty"`
+	Reason string   `json:"reason"`
+}
+
+// NewProblem returns a problem for the status, using the dedicated problem type of the status if there is one
+func NewProblem(status int, detail string) Problem {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		problemType = ProblemTypeDefault
+	}
+
+	return Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// NewValidationProblem returns a 400 problem listing every invalid field of err.
+// The second return value is false if err does not contain validation errors.
+func NewValidationProblem(err error) (Problem, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return Problem{}, false
+	}
+
+	p := Problem{
+		Type:          ProblemTypeValidation,
+		Title:         "Validation failed",
+		Status:        http.StatusBadRequest,
+		Detail:        "The request contains invalid fields, see invalidParams for details",
+		InvalidParams: make([]InvalidParam, 0, len(validationErrors)),
+	}
+
+	for _, fe := range validationErrors {
+		p.InvalidParams = append(p.InvalidParams, newInvalidParam(fe))
+	}
+
+	return p, true
+}
+
+func newInvalidParam(fe validator.FieldError) InvalidParam {
+	// The namespace starts with the name of the validated struct, which means nothing to API clients
+	_, name, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		name = fe.Field()
+	}
+
+	var params []string
+	if fe.Param() != "" {
+		params = strings.Fields(fe.Param())
+	}
+
+	return InvalidParam{
+		Name:   name,
+		Rule:   fe.Tag(),
+		Params: params,
+		Reason: invalidParamReason(fe),
+	}
+}
+
+// invalidParamReason returns a human-readable description of the broken rule
+func invalidParamReason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "ip":
+		return "must be a valid IP address"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "eqfield":
+		return fmt.Sprintf("must be equal to %s", fe.Param())
+	case "min", "max":
+		bound := "least"
+		if fe.Tag() == "max" {
+			bound = "most"
+		}
+
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be at %s %s characters long", bound, fe.Param())
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("must contain at %s %s items", bound, fe.Param())
+		}
+
+		return fmt.Sprintf("must be at %s %s", bound, fe.Param())
+	}
+
+	return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+}
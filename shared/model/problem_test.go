@@ -0,0 +1,101 @@
+package model_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := map[string]struct {
+		status   int
+		wantType string
+	}{
+		"not found":    {http.StatusNotFound, model.ProblemTypeNotFound},
+		"conflict":     {http.StatusConflict, model.ProblemTypeConflict},
+		"precondition": {http.StatusPreconditionFailed, model.ProblemTypePreconditionFailed},
+		"unauthorized": {http.StatusUnauthorized, model.ProblemTypeUnauthorized},
+		"forbidden":    {http.StatusForbidden, model.ProblemTypeForbidden},
+		"rate limited": {http.StatusTooManyRequests, model.ProblemTypeRateLimited},
+		"bad request":  {http.StatusBadRequest, model.ProblemTypeDefault},
+		"server error": {http.StatusInternalServerError, model.ProblemTypeDefault},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			got := model.NewProblem(tt.status, "detail")
+
+			// verify
+			assert.Equal(t, tt.wantType, got.Type)
+			assert.Equal(t, http.StatusText(tt.status), got.Title)
+			assert.Equal(t, tt.status, got.Status)
+			assert.Equal(t, "detail", got.Detail)
+		})
+	}
+}
+
+func TestNewValidationProblem(t *testing.T) {
+	t.Run("fields are reported by their JSON names", func(t *testing.T) {
+		// prepare
+		tc := model.RandomTodoCreate()
+		tc.Title = ""
+		tc.Description = strings.Repeat("a", 256)
+
+		// execute
+		got, ok := model.NewValidationProblem(tc.Validate())
+
+		// verify
+		require.True(t, ok)
+		assert.Equal(t, model.ProblemTypeValidation, got.Type)
+		assert.Equal(t, http.StatusBadRequest, got.Status)
+		assert.Equal(t, []model.InvalidParam{
+			{Name: "title", Rule: "required", Reason: "is required"},
+			{Name: "description", Rule: "max", Params: []string{"255"}, Reason: "must be at most 255 characters long"},
+		}, got.InvalidParams)
+	})
+
+	t.Run("rule parameters are split", func(t *testing.T) {
+		// prepare
+		us := model.UserSearch{Sort: "unknown", Limit: 101}
+
+		// execute
+		got, ok := model.NewValidationProblem(us.Validate())
+
+		// verify
+		require.True(t, ok)
+		require.Len(t, got.InvalidParams, 2)
+		assert.Equal(t, "sort", got.InvalidParams[0].Name)
+		assert.Equal(t, "oneof", got.InvalidParams[0].Rule)
+		assert.Equal(t, []string{"id", "-id", "name", "-name", "email", "-email", "status", "-status"}, got.InvalidParams[0].Params)
+		assert.Equal(t, "limit", got.InvalidParams[1].Name)
+		assert.Equal(t, "must be at most 100", got.InvalidParams[1].Reason)
+	})
+
+	t.Run("nested fields include their index", func(t *testing.T) {
+		// prepare
+		uc := model.RandomUserCreate()
+		uc.Groups = []string{"ok", strings.Repeat("g", 27)}
+
+		// execute
+		got, ok := model.NewValidationProblem(uc.Validate())
+
+		// verify
+		require.True(t, ok)
+		require.Len(t, got.InvalidParams, 1)
+		assert.Equal(t, "groups[1]", got.InvalidParams[0].Name)
+	})
+
+	t.Run("other errors are not validation problems", func(t *testing.T) {
+		// execute
+		_, ok := model.NewValidationProblem(errors.New("boom"))
+
+		// verify
+		assert.False(t, ok)
+	})
+}
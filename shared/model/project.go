@@ -5,7 +5,9 @@ import "github.com/brianvoe/gofakeit/v7"
 type Project struct {
 	ID          string `json:"id" validate:"required,max=26" fake:"{ulid}"`
 	Name        string `json:"name" validate:"required,max=64" fake:"{sentence:2}"`
-	Description string `json:"description,omitempty" validate:"max=64" fake:"{sentence:4}"`
+	Description string `json:"description,omitempty" validate:"max=255" fake:"{sentence:4}"`
+	// Version is incremented on every change of the project, it is used for optimistic concurrency control
+	Version int `json:"version" validate:"min=1" fake:"{number:1,100}"`
 }
 
 type ProjectCreate struct {
@@ -13,8 +15,9 @@ type ProjectCreate struct {
 	Description string `json:"description,omitempty" validate:"max=255"`
 }
 
+// ProjectUpdate replaces every field of a project, omitted fields are cleared
 type ProjectUpdate struct {
-	Name        string `json:"name,omitempty" validate:"max=64"`
+	Name        string `json:"name" validate:"required,max=64"`
 	Description string `json:"description,omitempty" validate:"max=255"`
 }
 
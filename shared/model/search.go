@@ -0,0 +1,27 @@
+package model
+
+// Types of the search hits
+const (
+	SearchHitProject = "project"
+	SearchHitList    = "list"
+	SearchHitTodo    = "todo"
+)
+
+// SearchQuery is a full-text search across projects, lists and todo items. Every word of Q must be found in a
+// hit, case and diacritics are ignored.
+type SearchQuery struct {
+	Q     string `json:"q" validate:"required,max=255"`
+	Limit int    `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// SearchHit is a single result of a search. Item is the project, list or todo item found, depending on Type.
+type SearchHit struct {
+	Type  string  `json:"type"`
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+	Item  any     `json:"item"`
+}
+
+func (sq *SearchQuery) Validate() error {
+	return validate.Struct(sq)
+}
@@ -1,9 +1,6 @@
 package model
 
-import (
-	"github.com/brianvoe/gofakeit/v7"
-	"github.com/go-playground/validator/v10"
-)
+import "github.com/brianvoe/gofakeit/v7"
 
 type Todo struct {
 	ID          string `json:"id" validate:"required,max=26" fake:"{ulid}"`
@@ -11,6 +8,8 @@ type Todo struct {
 	Title       string `json:"title" validate:"required,max=64" fake:"{sentence:3}"`
 	Description string `json:"description,omitempty" validate:"max=255" fake:"{paragraph:1}"`
 	Completed   bool   `json:"completed"`
+	// Version is incremented on every change of the todo item, it is used for optimistic concurrency control
+	Version int `json:"version" validate:"min=1" fake:"{number:1,100}"`
 }
 
 type TodoCreate struct {
@@ -20,14 +19,13 @@ type TodoCreate struct {
 	Completed   bool   `json:"completed"`
 }
 
+// TodoUpdate replaces every field of a todo item, omitted fields are cleared
 type TodoUpdate struct {
-	Title       string `json:"title,omitempty" validate:"max=64"`
+	Title       string `json:"title" validate:"required,max=64"`
 	Description string `json:"description,omitempty" validate:"max=255"`
-	Completed   *bool  `json:"completed,omitempty"`
+	Completed   bool   `json:"completed"`
 }
 
-var validate = validator.New()
-
 // Todo validation methods
 func (t *Todo) Validate() error {
 	return validate.Struct(t)
@@ -66,11 +64,10 @@ func RandomTodoCreate() TodoCreate {
 
 func RandomTodoUpdate() TodoUpdate {
 	t := RandomTodo()
-	completed := t.Completed
 
 	return TodoUpdate{
 		Title:       t.Title,
 		Description: t.Description,
-		Completed:   &completed,
+		Completed:   t.Completed,
 	}
 }
@@ -1,17 +1,57 @@
 package model
 
 import (
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/text/cases"
+)
+
+// Authorization groups known by the API.
+const (
+	GroupAdmin        = "admin"
+	GroupProjectRead  = "project.read"
+	GroupProjectWrite = "project.write"
 )
 
+// UserStatus is the lifecycle state of a user account
+type UserStatus string
+
+const (
+	UserStatusActive          UserStatus = "active"
+	UserStatusSuspended       UserStatus = "suspended"
+	UserStatusPendingDeletion UserStatus = "pending-deletion"
+	UserStatusDeleted         UserStatus = "deleted"
+)
+
+// userStatusTransitions lists the states each state can move to. Deleted is final.
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	UserStatusActive:          {UserStatusSuspended, UserStatusPendingDeletion},
+	UserStatusSuspended:       {UserStatusActive, UserStatusPendingDeletion},
+	UserStatusPendingDeletion: {UserStatusActive, UserStatusDeleted},
+}
+
+// CanTransitionTo reports whether an account in this state may be moved to the next one
+func (s UserStatus) CanTransitionTo(next UserStatus) bool {
+	return slices.Contains(userStatusTransitions[s], next)
+}
+
 type User struct {
-	ID           string   `json:"id" validate:"required,max=26" fake:"{ulid}"`
-	Name         string   `json:"name" validate:"required,max=64" fake:"{firstname} {lastname}"`
-	Email        string   `json:"email" validate:"required,email" fake:"{email}"`
-	Groups       []string `json:"groups" validate:"dive,max=26"`
-	PasswordHash []byte   `json:"-"`
-	PasswordSalt []byte   `json:"-"`
+	ID                  string     `json:"id" validate:"required,max=26" fake:"{ulid}"`
+	Name                string     `json:"name" validate:"required,max=64" fake:"{firstname} {lastname}"`
+	Email               string     `json:"email" validate:"required,email" fake:"{email}"`
+	Groups              []string   `json:"groups" validate:"dive,max=26"`
+	Status              UserStatus `json:"status" validate:"required,oneof=active suspended pending-deletion deleted" fake:"{randomstring:[active,suspended]}"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty" fake:"skip"`
+	// Version is incremented on every change of the user, it is used for optimistic concurrency control
+	Version int `json:"version" validate:"min=1" fake:"{number:1,100}"`
+	// SessionEpoch is embedded into issued tokens, incrementing it invalidates every token issued before
+	SessionEpoch int    `json:"-" fake:"skip"`
+	PasswordHash []byte `json:"-"`
+	PasswordSalt []byte `json:"-"`
 }
 
 type UserCreate struct {
@@ -24,12 +64,12 @@ type UserCreate struct {
 
 type UserUpdate struct {
 	Name   string   `json:"name,omitempty" validate:"max=64"`
-	Email  string   `json:"email,omitempty" validate:"email"`
+	Email  string   `json:"email,omitempty" validate:"omitempty,email"`
 	Groups []string `json:"groups,omitempty" validate:"dive,max=26"`
 }
 
 type UserLogin struct {
-	Email    string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"required,email,max=254"`
 	Password string `json:"password" validate:"required,min=8"`
 }
 
@@ -37,21 +77,69 @@ type LoginResponse struct {
 	Token string `json:"token" validate:"required"`
 }
 
+// UserSearch describes a filtered, sorted and paginated listing of users. Zero values are ignored.
+// Name and email are matched case-insensitively by prefix. Sort is a field name, prefixed with "-" for
+// descending order. Users are sorted by ID, in other words by creation time, by default.
+type UserSearch struct {
+	Name   string     `json:"name,omitempty" validate:"max=64"`
+	Email  string     `json:"email,omitempty" validate:"max=255"`
+	Group  string     `json:"group,omitempty" validate:"max=26"`
+	Status UserStatus `json:"status,omitempty" validate:"omitempty,oneof=active suspended pending-deletion deleted"`
+	Sort   string     `json:"sort,omitempty" validate:"omitempty,oneof=id -id name -name email -email status -status"`
+	Offset int        `json:"offset,omitempty" validate:"min=0"`
+	Limit  int        `json:"limit,omitempty" validate:"min=0,max=100"`
+}
+
+// UserPage is a page of the users matching a search
+type UserPage struct {
+	Users []User `json:"users"`
+	// Total is the number of users matching the search, across all pages
+	Total int `json:"total"`
+	// Offset is the number of matching users before the page
+	Offset int `json:"offset"`
+	// Limit is the size of the pages, the one requested capped by the repo, even if the page is shorter
+	Limit int `json:"limit"`
+}
+
+type UserStatusUpdate struct {
+	Status UserStatus `json:"status" validate:"required,oneof=active suspended pending-deletion"`
+}
+
 type UserPasswordUpdate struct {
 	Password  string `json:"password" validate:"required,min=8"`
 	Password2 string `json:"password2" validate:"required,min=8,eqfield=Password"`
 }
 
 type LoggedInUser struct {
-	ID     string
-	Name   string
-	Groups []string
+	ID           string
+	Name         string
+	Groups       []string
+	SessionEpoch int `json:",omitempty"`
 	jwt.RegisteredClaims
 }
 
 // Ensure we implement [jwt.ClaimsValidator] at compile time so we know our custom Validate method is used.
 var _ jwt.ClaimsValidator = (*LoggedInUser)(nil)
 
+// HasGroup reports whether the logged-in user is a member of the given group.
+// Members of the admin group are considered members of every group.
+func (liu *LoggedInUser) HasGroup(group string) bool {
+	for _, g := range liu.Groups {
+		if g == group || g == GroupAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizeEmail returns the canonical form of an email address used for comparing and indexing emails.
+// Surrounding whitespace is removed and the address is case-folded.
+func NormalizeEmail(email string) string {
+	// Casers are stateful and must not be shared between goroutines
+	return cases.Fold().String(strings.TrimSpace(email))
+}
+
 // User validation methods
 func (u *User) Validate() error {
 	return validate.Struct(u)
@@ -73,6 +161,14 @@ func (lr *LoginResponse) Validate() error {
 	return validate.Struct(lr)
 }
 
+func (us *UserSearch) Validate() error {
+	return validate.Struct(us)
+}
+
+func (usu *UserStatusUpdate) Validate() error {
+	return validate.Struct(usu)
+}
+
 func (upu *UserPasswordUpdate) Validate() error {
 	return validate.Struct(upu)
 }
@@ -124,6 +220,14 @@ func RandomLoginResponse() LoginResponse {
 	return LoginResponse{Token: token}
 }
 
+func RandomUserStatusUpdate() UserStatusUpdate {
+	return UserStatusUpdate{Status: UserStatus(gofakeit.RandomString([]string{
+		string(UserStatusActive),
+		string(UserStatusSuspended),
+		string(UserStatusPendingDeletion),
+	}))}
+}
+
 func RandomUserPasswordUpdate() UserPasswordUpdate {
 	p1 := gofakeit.Password(true, true, true, true, true, 12)
 
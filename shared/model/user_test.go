@@ -82,3 +82,21 @@ func TestRandomUserPasswordUpdate(t *testing.T) {
 	assert.NoError(t, got1.Validate())
 	assert.NoError(t, got2.Validate())
 }
+
+func TestRandomUserStatusUpdate(t *testing.T) {
+	// execute
+	got := model.RandomUserStatusUpdate()
+
+	// verify
+	assert.NotEmpty(t, got.Status)
+	assert.NoError(t, got.Validate())
+}
+
+func TestUserStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, model.UserStatusActive.CanTransitionTo(model.UserStatusSuspended))
+	assert.True(t, model.UserStatusSuspended.CanTransitionTo(model.UserStatusActive))
+	assert.True(t, model.UserStatusPendingDeletion.CanTransitionTo(model.UserStatusActive))
+	assert.True(t, model.UserStatusPendingDeletion.CanTransitionTo(model.UserStatusDeleted))
+	assert.False(t, model.UserStatusActive.CanTransitionTo(model.UserStatusDeleted))
+	assert.False(t, model.UserStatusDeleted.CanTransitionTo(model.UserStatusActive))
+}
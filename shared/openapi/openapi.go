@@ -0,0 +1,163 @@
+// Package openapi embeds the OpenAPI document describing the API, and a page rendering it
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidDocument = errors.New("invalid OpenAPI document")
+
+//go:embed todo-openapi.yaml
+var spec []byte
+
+// DocsPage is a self-contained HTML page rendering the document served next to it as openapi.json, with a form
+// sending requests to the API
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// Document is an OpenAPI document, kept as a YAML node tree to preserve the order and the formatting of the keys
+type Document struct {
+	root *yaml.Node
+}
+
+// load parses the embedded document once, it is only invalid if the build is broken
+var load = sync.OnceValues(func() (*Document, error) {
+	return Parse(spec)
+})
+
+// Load returns the embedded document
+func Load() (*Document, error) {
+	return load()
+}
+
+// Parse parses a YAML OpenAPI document
+func Parse(content []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("%w, err: %w", ErrInvalidDocument, err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: the document is not a mapping", ErrInvalidDocument)
+	}
+
+	return &Document{root: root.Content[0]}, nil
+}
+
+// WithServerURL returns a copy of the document listing url as its only server. The document is not modified, so
+// that it can be shared between requests.
+func (d *Document) WithServerURL(url string) *Document {
+	servers := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "url"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: url},
+		},
+	}}}
+
+	root := *d.root
+	root.Content = make([]*yaml.Node, 0, len(d.root.Content)+2)
+	replaced := false
+	for i := 0; i+1 < len(d.root.Content); i += 2 {
+		key, value := d.root.Content[i], d.root.Content[i+1]
+		if key.Value == "servers" {
+			value, replaced = servers, true
+		}
+		root.Content = append(root.Content, key, value)
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "servers"}, servers)
+	}
+
+	return &Document{root: &root}
+}
+
+// YAML encodes the document as YAML
+func (d *Document) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, fmt.Errorf("failed to encode document, err: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode document, err: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// JSON encodes the document as JSON, keeping the order of the keys
+func (d *Document) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := writeJSON(&buf, d.root); err != nil {
+		return nil, fmt.Errorf("failed to encode document, err: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeJSON writes the JSON representation of the node to buf
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(buf, n.Content[0])
+
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	default:
+		var value any
+		if err := n.Decode(&value); err != nil {
+			return err
+		}
+		scalar, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("unsupported value at line %d: %s, err: %w", n.Line, n.Value, err)
+		}
+		buf.Write(scalar)
+	}
+
+	return nil
+}
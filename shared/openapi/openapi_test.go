@@ -0,0 +1,130 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocument = `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: http://localhost:8080
+paths:
+  /b:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              example:
+                count: 3
+                ratio: 0.5
+                active: true
+                deleted: null
+                createdAt: 2025-07-14T10:00:00Z
+  /a: {}
+`
+
+func TestLoad(t *testing.T) {
+	// execute
+	doc, err := openapi.Load()
+
+	// verify
+	require.NoError(t, err)
+	content, err := doc.JSON()
+	require.NoError(t, err)
+	assert.True(t, json.Valid(content))
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"malformed": "openapi: [",
+		"not a map": "- openapi",
+		"empty":     "",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			_, err := openapi.Parse([]byte(content))
+
+			// verify
+			assert.ErrorIs(t, err, openapi.ErrInvalidDocument)
+		})
+	}
+}
+
+func TestDocument_WithServerURL(t *testing.T) {
+	t.Run("replaces the servers", func(t *testing.T) {
+		// prepare
+		doc, err := openapi.Parse([]byte(testDocument))
+		require.NoError(t, err)
+
+		// execute
+		content, err := doc.WithServerURL("https://api.example.com/v1").YAML()
+
+		// verify
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "servers:\n  - url: https://api.example.com/v1\n")
+		assert.NotContains(t, string(content), "localhost")
+	})
+
+	t.Run("does not modify the document", func(t *testing.T) {
+		// prepare
+		doc, err := openapi.Parse([]byte(testDocument))
+		require.NoError(t, err)
+
+		// execute
+		_ = doc.WithServerURL("https://api.example.com/v1")
+
+		// verify
+		content, err := doc.YAML()
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "url: http://localhost:8080")
+	})
+
+	t.Run("adds missing servers", func(t *testing.T) {
+		// prepare
+		doc, err := openapi.Parse([]byte("openapi: 3.0.3\n"))
+		require.NoError(t, err)
+
+		// execute
+		content, err := doc.WithServerURL("http://example.com").JSON()
+
+		// verify
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"openapi":"3.0.3","servers":[{"url":"http://example.com"}]}`, string(content))
+	})
+}
+
+func TestDocument_JSON(t *testing.T) {
+	// prepare
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	// execute
+	content, err := doc.JSON()
+
+	// verify
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.3",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"servers": [{"url": "http://localhost:8080"}],
+		"paths": {
+			"/b": {"get": {"responses": {"200": {"description": "ok", "content": {"application/json": {"example": {
+				"count": 3, "ratio": 0.5, "active": true, "deleted": null, "createdAt": "2025-07-14T10:00:00Z"
+			}}}}}}},
+			"/a": {}
+		}
+	}`, string(content))
+	assert.Less(t, bytes.Index(content, []byte(`"/b"`)), bytes.Index(content, []byte(`"/a"`)), "the order of the keys is kept")
+}
@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Route is a route registered by an implementation of the API
+type Route struct {
+	Method string
+	// Pattern is the path without the base path, with the parameters between braces, like "/projects/{id}"
+	Pattern string
+	// Authenticated is true for the routes requiring a bearer token
+	Authenticated bool
+	// Groups are the groups authorized to use the route, any logged-in user may use it if empty. Other ways of
+	// authorizing requests, like users accessing their own resources, are not compared.
+	Groups []string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Pattern
+}
+
+// RouteReport lists the differences between the routes of an implementation and the operations of the document
+type RouteReport struct {
+	// Missing are the operations without a route
+	Missing []string
+	// Extra are the routes without an operation
+	Extra []string
+	// Mismatched are the routes differing from their operation
+	Mismatched []string
+}
+
+// Empty reports whether the routes match the document
+func (r RouteReport) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+func (r RouteReport) String() string {
+	var b strings.Builder
+
+	for _, section := range []struct {
+		title   string
+		entries []string
+	}{
+		{"missing routes, documented but not registered", r.Missing},
+		{"extra routes, registered but not documented", r.Extra},
+		{"mismatched routes", r.Mismatched},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", section.title)
+		for _, entry := range section.entries {
+			fmt.Fprintf(&b, "  %s\n", entry)
+		}
+	}
+
+	return b.String()
+}
+
+// CompareRoutes compares the routes of an implementation with the operations of the document. Routes match
+// operations by method and path, whatever the names of their path parameters are, and matching ones are compared
+// by the names of the path parameters, the authentication and the authorized groups. The groups of an operation are
+// listed by its x-groups extension, as the bearer tokens of the API carry no scopes.
+func (s *Spec) CompareRoutes(routes []Route) RouteReport {
+	var report RouteReport
+
+	registered := map[string]Route{}
+	for _, r := range routes {
+		registered[r.Method+" "+shape(r.Pattern)] = r
+	}
+
+	documented := map[string]bool{}
+	for _, path := range slices.Sorted(maps.Keys(s.Paths)) {
+		for _, method := range slices.Sorted(maps.Keys(s.Paths[path].Operations)) {
+			op := s.Paths[path].Operations[method]
+			method = strings.ToUpper(method)
+			key := method + " " + shape(path)
+			documented[key] = true
+
+			name := method + " " + path
+			if op.OperationID != "" {
+				name += " (" + op.OperationID + ")"
+			}
+
+			r, ok := registered[key]
+			if !ok {
+				report.Missing = append(report.Missing, name)
+				continue
+			}
+
+			for _, message := range s.compareRoute(r, path, op) {
+				report.Mismatched = append(report.Mismatched, name+": "+message)
+			}
+		}
+	}
+
+	for _, r := range routes {
+		if !documented[r.Method+" "+shape(r.Pattern)] {
+			report.Extra = append(report.Extra, r.String())
+		}
+	}
+	slices.Sort(report.Extra)
+
+	return report
+}
+
+// shape replaces the names of the path parameters with empty braces, so that paths can be compared whatever the
+// names of their parameters are
+func shape(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isParam(segment) {
+			segments[i] = "{}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// compareRoute returns the differences between a route and the operation documented for it
+func (s *Spec) compareRoute(r Route, path string, op *Operation) []string {
+	var messages []string
+
+	documented, registered := strings.Split(path, "/"), strings.Split(r.Pattern, "/")
+	for i, segment := range documented {
+		if isParam(segment) && segment != registered[i] {
+			messages = append(messages, fmt.Sprintf("path parameter %s is documented as %s", registered[i], segment))
+		}
+	}
+
+	security := op.Security
+	if security == nil {
+		security = s.Security
+	}
+	public := len(security) == 0 || slices.ContainsFunc(security, func(req SecurityRequirement) bool {
+		return len(req) == 0
+	})
+
+	switch {
+	case r.Authenticated && public:
+		messages = append(messages, "requires a bearer token, but is documented as public")
+	case !r.Authenticated && !public:
+		messages = append(messages, "is public, but is documented as requiring authentication")
+	}
+	if !r.Authenticated {
+		return messages
+	}
+
+	for _, req := range security {
+		for _, name := range slices.Sorted(maps.Keys(req)) {
+			scheme, ok := s.Components.SecuritySchemes[name]
+			switch {
+			case !ok || scheme == nil:
+				messages = append(messages, "documents the unknown security scheme "+name)
+			case scheme.Type != "http" || !strings.EqualFold(scheme.Scheme, "bearer"):
+				messages = append(messages, fmt.Sprintf("documents the security scheme %s of type %s, but only bearer tokens are accepted", name, scheme.Type))
+			}
+		}
+	}
+
+	if !slices.Equal(slices.Sorted(slices.Values(r.Groups)), slices.Sorted(slices.Values(op.Groups))) {
+		messages = append(messages, fmt.Sprintf("authorizes %s, but documents %s", describeGroups(r.Groups), describeGroups(op.Groups)))
+	}
+
+	return messages
+}
+
+func describeGroups(groups []string) string {
+	if len(groups) == 0 {
+		return "any logged-in user"
+	}
+
+	return "the groups " + strings.Join(groups, ", ")
+}
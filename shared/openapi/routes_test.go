@@ -0,0 +1,151 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecuredSpec = `openapi: 3.0.3
+security:
+  - BearerAuth: []
+paths:
+  /items:
+    get:
+      operationId: listItems
+      x-groups:
+        - item.read
+    post:
+      operationId: createItem
+      security:
+        - ApiKeyAuth: []
+        - OAuth2: [item.write]
+  /items/{itemId}:
+    get:
+      operationId: getItem
+      security:
+        - {}
+        - BearerAuth: []
+    delete:
+      operationId: deleteItem
+      security:
+        - Unknown: []
+  /health:
+    get:
+      operationId: health
+      security: []
+components:
+  securitySchemes:
+    BearerAuth:
+      type: http
+      scheme: bearer
+    ApiKeyAuth:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    OAuth2:
+      type: oauth2
+`
+
+func TestSpec_CompareRoutes(t *testing.T) {
+	spec := parseSpec(t, testSecuredSpec)
+
+	tests := map[string]struct {
+		routes []openapi.Route
+		want   openapi.RouteReport
+	}{
+		"matching routes": {
+			routes: []openapi.Route{
+				{Method: "GET", Pattern: "/items", Authenticated: true, Groups: []string{"item.read"}},
+				{Method: "GET", Pattern: "/items/{itemId}"},
+				{Method: "GET", Pattern: "/health"},
+			},
+			want: openapi.RouteReport{
+				Missing: []string{"DELETE /items/{itemId} (deleteItem)", "POST /items (createItem)"},
+			},
+		},
+		"optional authentication": {
+			routes: []openapi.Route{
+				{Method: "GET", Pattern: "/items/{itemId}", Authenticated: true},
+			},
+			want: openapi.RouteReport{
+				Missing: []string{
+					"GET /health (health)",
+					"GET /items (listItems)",
+					"POST /items (createItem)",
+					"DELETE /items/{itemId} (deleteItem)",
+				},
+				Mismatched: []string{"GET /items/{itemId} (getItem): requires a bearer token, but is documented as public"},
+			},
+		},
+		"differences": {
+			routes: []openapi.Route{
+				{Method: "GET", Pattern: "/items", Groups: []string{"item.read"}},
+				{Method: "POST", Pattern: "/items", Authenticated: true, Groups: []string{"item.write"}},
+				{Method: "DELETE", Pattern: "/items/{id}", Authenticated: true},
+				{Method: "GET", Pattern: "/health", Authenticated: true},
+				{Method: "PUT", Pattern: "/items/{id}"},
+				{Method: "GET", Pattern: "/ping"},
+			},
+			want: openapi.RouteReport{
+				Missing: []string{"GET /items/{itemId} (getItem)"},
+				Extra:   []string{"GET /ping", "PUT /items/{id}"},
+				Mismatched: []string{
+					"GET /health (health): requires a bearer token, but is documented as public",
+					"GET /items (listItems): is public, but is documented as requiring authentication",
+					"POST /items (createItem): documents the security scheme ApiKeyAuth of type apiKey, but only bearer tokens are accepted",
+					"POST /items (createItem): documents the security scheme OAuth2 of type oauth2, but only bearer tokens are accepted",
+					"POST /items (createItem): authorizes the groups item.write, but documents any logged-in user",
+					"DELETE /items/{itemId} (deleteItem): path parameter {id} is documented as {itemId}",
+					"DELETE /items/{itemId} (deleteItem): documents the unknown security scheme Unknown",
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			report := spec.CompareRoutes(tt.routes)
+
+			// verify
+			assert.ElementsMatch(t, tt.want.Missing, report.Missing)
+			assert.Equal(t, tt.want.Extra, report.Extra)
+			assert.ElementsMatch(t, tt.want.Mismatched, report.Mismatched)
+		})
+	}
+}
+
+func TestRouteReport_String(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		// prepare
+		report := openapi.RouteReport{}
+
+		// execute
+		s := report.String()
+
+		// verify
+		assert.True(t, report.Empty())
+		assert.Empty(t, s)
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		// prepare
+		report := openapi.RouteReport{
+			Missing:    []string{"GET /items (listItems)"},
+			Mismatched: []string{"GET /health (health): requires a bearer token, but is documented as public"},
+		}
+
+		// execute
+		s := report.String()
+
+		// verify
+		assert.False(t, report.Empty())
+		assert.Equal(t, `missing routes, documented but not registered:
+  GET /items (listItems)
+mismatched routes:
+  GET /health (health): requires a bearer token, but is documented as public
+`, s)
+	})
+}
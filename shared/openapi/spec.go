@@ -0,0 +1,394 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the part of an OpenAPI document describing the operations, with every reference resolved
+type Spec struct {
+	Paths      map[string]*PathItem  `yaml:"paths"`
+	Components Components            `yaml:"components"`
+	Security   []SecurityRequirement `yaml:"security"`
+
+	// templates are the paths, the most specific ones first
+	templates []template
+}
+
+// Components are the reusable objects of the document
+type Components struct {
+	Schemas         map[string]*Schema         `yaml:"schemas"`
+	Parameters      map[string]*Parameter      `yaml:"parameters"`
+	RequestBodies   map[string]*RequestBody    `yaml:"requestBodies"`
+	Responses       map[string]*Response       `yaml:"responses"`
+	Headers         map[string]*Header         `yaml:"headers"`
+	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes"`
+}
+
+// PathItem holds the operations of a path, keyed by the lowercase method
+type PathItem struct {
+	Summary     string                `yaml:"summary"`
+	Description string                `yaml:"description"`
+	Parameters  []*Parameter          `yaml:"parameters"`
+	Operations  map[string]*Operation `yaml:",inline"`
+}
+
+// Operation is a single method of a path
+type Operation struct {
+	OperationID string                `yaml:"operationId"`
+	Tags        []string              `yaml:"tags"`
+	Parameters  []*Parameter          `yaml:"parameters"`
+	RequestBody *RequestBody          `yaml:"requestBody"`
+	Responses   map[string]*Response  `yaml:"responses"`
+	Security    []SecurityRequirement `yaml:"security"`
+	// Groups are the groups authorized to use the operation, listed by the x-groups extension
+	Groups []string `yaml:"x-groups"`
+}
+
+// SecurityRequirement maps the names of security schemes to the scopes they require
+type SecurityRequirement map[string][]string
+
+// SecurityScheme is a way of authenticating requests
+type SecurityScheme struct {
+	Ref    string `yaml:"$ref"`
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+	In     string `yaml:"in"`
+	Name   string `yaml:"name"`
+}
+
+// Parameter is a path, query, header or cookie parameter of an operation
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+	Example  any     `yaml:"example"`
+}
+
+// RequestBody describes the accepted bodies of an operation by media type
+type RequestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Ref     string                `yaml:"$ref"`
+	Headers map[string]*Header    `yaml:"headers"`
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+// Header is a response header
+type Header struct {
+	Ref    string  `yaml:"$ref"`
+	Schema *Schema `yaml:"schema"`
+}
+
+// MediaType describes a body of a given media type
+type MediaType struct {
+	Schema  *Schema `yaml:"schema"`
+	Example any     `yaml:"example"`
+}
+
+// Schema is the subset of the OpenAPI 3.0 schema object the validation and the contract tests support
+type Schema struct {
+	Ref                  string                `yaml:"$ref"`
+	Type                 string                `yaml:"type"`
+	Format               string                `yaml:"format"`
+	Pattern              string                `yaml:"pattern"`
+	Enum                 []any                 `yaml:"enum"`
+	Nullable             bool                  `yaml:"nullable"`
+	ReadOnly             bool                  `yaml:"readOnly"`
+	Example              any                   `yaml:"example"`
+	MinLength            *int                  `yaml:"minLength"`
+	MaxLength            *int                  `yaml:"maxLength"`
+	Minimum              *float64              `yaml:"minimum"`
+	Maximum              *float64              `yaml:"maximum"`
+	MinItems             *int                  `yaml:"minItems"`
+	MaxItems             *int                  `yaml:"maxItems"`
+	Items                *Schema               `yaml:"items"`
+	Properties           map[string]*Schema    `yaml:"properties"`
+	Required             []string              `yaml:"required"`
+	AdditionalProperties *AdditionalProperties `yaml:"additionalProperties"`
+	AllOf                []*Schema             `yaml:"allOf"`
+	OneOf                []*Schema             `yaml:"oneOf"`
+	AnyOf                []*Schema             `yaml:"anyOf"`
+
+	// pattern is the compiled Pattern
+	pattern *regexp.Regexp
+}
+
+// AdditionalProperties is either a boolean or a schema the properties not listed in the schema must match
+type AdditionalProperties struct {
+	Forbidden bool
+	Schema    *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		var allowed bool
+		if err := n.Decode(&allowed); err != nil {
+			return err
+		}
+		a.Forbidden = !allowed
+
+		return nil
+	}
+
+	return n.Decode(&a.Schema)
+}
+
+// template is a path of the document split into segments, parameters are the segments between braces
+type template struct {
+	path     string
+	segments []string
+}
+
+// Spec decodes the operations of the document and resolves the references between them
+func (d *Document) Spec() (*Spec, error) {
+	var s Spec
+	if err := d.root.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%w, err: %w", ErrInvalidDocument, err)
+	}
+
+	if err := s.resolve(); err != nil {
+		return nil, err
+	}
+
+	for path := range s.Paths {
+		s.templates = append(s.templates, template{path: path, segments: strings.Split(strings.Trim(path, "/"), "/")})
+	}
+	// literal segments win over parameters, like in http.ServeMux
+	slices.SortFunc(s.templates, func(a, b template) int {
+		for i := 0; i < len(a.segments) && i < len(b.segments); i++ {
+			if pa, pb := isParam(a.segments[i]), isParam(b.segments[i]); pa != pb {
+				if pa {
+					return 1
+				}
+				return -1
+			}
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	return &s, nil
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// FindOperation returns the operation serving the method and path, with the values of the path parameters, and
+// the path of the document it was found at
+func (s *Spec) FindOperation(method, path string) (*Operation, string, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, t := range s.templates {
+		params, ok := t.match(segments)
+		if !ok {
+			continue
+		}
+
+		op, ok := s.Paths[t.path].Operations[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+
+		return op, t.path, params, true
+	}
+
+	return nil, "", nil, false
+}
+
+func (t template) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(t.segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range t.segments {
+		if isParam(segment) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// Parameters returns the parameters of the operation, including the ones shared by the path
+func (s *Spec) Parameters(path string, op *Operation) []*Parameter {
+	var params []*Parameter
+
+	for _, p := range s.Paths[path].Parameters {
+		overridden := slices.ContainsFunc(op.Parameters, func(o *Parameter) bool {
+			return o.Name == p.Name && o.In == p.In
+		})
+		if !overridden {
+			params = append(params, p)
+		}
+	}
+
+	return append(params, op.Parameters...)
+}
+
+// resolve replaces every reference with the component it points to
+func (s *Spec) resolve() error {
+	r := resolver{spec: s, schemas: map[*Schema]bool{}}
+
+	for name, schema := range s.Components.Schemas {
+		r.schema(&schema)
+		s.Components.Schemas[name] = schema
+	}
+	for name, p := range s.Components.Parameters {
+		s.Components.Parameters[name] = r.parameter(p)
+	}
+	for name, h := range s.Components.Headers {
+		s.Components.Headers[name] = r.header(h)
+	}
+	for name, resp := range s.Components.Responses {
+		s.Components.Responses[name] = r.response(resp)
+	}
+	for name, b := range s.Components.RequestBodies {
+		s.Components.RequestBodies[name] = r.requestBody(b)
+	}
+
+	for _, item := range s.Paths {
+		for i, p := range item.Parameters {
+			item.Parameters[i] = r.parameter(p)
+		}
+		for _, op := range item.Operations {
+			for i, p := range op.Parameters {
+				op.Parameters[i] = r.parameter(p)
+			}
+			op.RequestBody = r.requestBody(op.RequestBody)
+			for status, resp := range op.Responses {
+				op.Responses[status] = r.response(resp)
+			}
+		}
+	}
+
+	return r.err
+}
+
+// resolver resolves references, keeping the first unknown reference as the error
+type resolver struct {
+	spec    *Spec
+	schemas map[*Schema]bool
+	err     error
+}
+
+// lookup returns the component the reference points to
+func lookup[T any](r *resolver, ref, kind string, components map[string]*T) *T {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if c, found := components[name]; ok && found {
+		return c
+	}
+
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: unknown reference: %s", ErrInvalidDocument, ref)
+	}
+
+	return nil
+}
+
+func (r *resolver) schema(s **Schema) {
+	for *s != nil && (*s).Ref != "" {
+		*s = lookup(r, (*s).Ref, "schemas", r.spec.Components.Schemas)
+	}
+	if *s == nil || r.schemas[*s] {
+		return
+	}
+	r.schemas[*s] = true
+
+	schema := *s
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil && r.err == nil {
+			r.err = fmt.Errorf("%w: invalid pattern: %s, err: %w", ErrInvalidDocument, schema.Pattern, err)
+		}
+		schema.pattern = re
+	}
+	r.schema(&schema.Items)
+	for name := range schema.Properties {
+		p := schema.Properties[name]
+		r.schema(&p)
+		schema.Properties[name] = p
+	}
+	if schema.AdditionalProperties != nil {
+		r.schema(&schema.AdditionalProperties.Schema)
+	}
+	for _, list := range [][]*Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for i := range list {
+			r.schema(&list[i])
+		}
+	}
+}
+
+func (r *resolver) parameter(p *Parameter) *Parameter {
+	if p != nil && p.Ref != "" {
+		p = lookup(r, p.Ref, "parameters", r.spec.Components.Parameters)
+	}
+	if p != nil {
+		r.schema(&p.Schema)
+	}
+
+	return p
+}
+
+func (r *resolver) header(h *Header) *Header {
+	if h != nil && h.Ref != "" {
+		h = lookup(r, h.Ref, "headers", r.spec.Components.Headers)
+	}
+	if h != nil {
+		r.schema(&h.Schema)
+	}
+
+	return h
+}
+
+func (r *resolver) content(content map[string]*MediaType) {
+	for _, m := range content {
+		if m != nil {
+			r.schema(&m.Schema)
+		}
+	}
+}
+
+func (r *resolver) requestBody(b *RequestBody) *RequestBody {
+	if b != nil && b.Ref != "" {
+		b = lookup(r, b.Ref, "requestBodies", r.spec.Components.RequestBodies)
+	}
+	if b != nil {
+		r.content(b.Content)
+	}
+
+	return b
+}
+
+func (r *resolver) response(resp *Response) *Response {
+	if resp != nil && resp.Ref != "" {
+		resp = lookup(r, resp.Ref, "responses", r.spec.Components.Responses)
+	}
+	if resp != nil {
+		r.content(resp.Content)
+		for name, h := range resp.Headers {
+			resp.Headers[name] = r.header(h)
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,177 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `openapi: 3.0.3
+paths:
+  /items:
+    get:
+      operationId: listItems
+  /items/{itemId}:
+    parameters:
+      - name: itemId
+        in: path
+        required: true
+        schema:
+          type: string
+      - $ref: '#/components/parameters/Verbose'
+    get:
+      operationId: getItem
+      parameters:
+        - name: itemId
+          in: path
+          required: true
+          schema:
+            $ref: '#/components/schemas/ID'
+    put:
+      operationId: updateItem
+      requestBody:
+        $ref: '#/components/requestBodies/Item'
+  /items/latest:
+    get:
+      operationId: getLatestItem
+components:
+  parameters:
+    Verbose:
+      name: verbose
+      in: query
+      schema:
+        type: boolean
+  requestBodies:
+    Item:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Item'
+  schemas:
+    ID:
+      type: string
+      format: ulid
+    Item:
+      type: object
+      properties:
+        id:
+          $ref: '#/components/schemas/ID'
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Item'
+`
+
+func parseSpec(t *testing.T, content string) *openapi.Spec {
+	t.Helper()
+
+	doc, err := openapi.Parse([]byte(content))
+	require.NoError(t, err)
+	spec, err := doc.Spec()
+	require.NoError(t, err)
+
+	return spec
+}
+
+func TestDocument_Spec(t *testing.T) {
+	t.Run("embedded document", func(t *testing.T) {
+		// prepare
+		doc, err := openapi.Load()
+		require.NoError(t, err)
+
+		// execute
+		spec, err := doc.Spec()
+
+		// verify
+		require.NoError(t, err)
+		assert.Contains(t, spec.Paths, "/projects")
+	})
+
+	t.Run("references are resolved", func(t *testing.T) {
+		// execute
+		spec := parseSpec(t, testSpec)
+
+		// verify
+		body := spec.Paths["/items/{itemId}"].Operations["put"].RequestBody
+		require.NotNil(t, body)
+		item := body.Content["application/json"].Schema
+		assert.Equal(t, "object", item.Type)
+		assert.Equal(t, "ulid", item.Properties["id"].Format)
+		assert.Same(t, item, item.Properties["children"].Items, "recursive schemas are shared")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]string{
+			"unknown reference": "paths:\n  /a:\n    get:\n      parameters:\n        - $ref: '#/components/parameters/Missing'\n",
+			"invalid pattern":   "components:\n  schemas:\n    A:\n      type: string\n      pattern: '['\n",
+			"invalid structure": "paths: [1, 2]\n",
+		}
+
+		for name, content := range tests {
+			t.Run(name, func(t *testing.T) {
+				// prepare
+				doc, err := openapi.Parse([]byte(content))
+				require.NoError(t, err)
+
+				// execute
+				_, err = doc.Spec()
+
+				// verify
+				assert.ErrorIs(t, err, openapi.ErrInvalidDocument)
+			})
+		}
+	})
+}
+
+func TestSpec_FindOperation(t *testing.T) {
+	// prepare
+	spec := parseSpec(t, testSpec)
+
+	tests := map[string]struct {
+		method     string
+		path       string
+		wantID     string
+		wantParams map[string]string
+	}{
+		"literal path":                {"GET", "/items", "listItems", map[string]string{}},
+		"path parameter":              {"GET", "/items/01K02SD13A5YKWWZFV9AQP7H1X", "getItem", map[string]string{"itemId": "01K02SD13A5YKWWZFV9AQP7H1X"}},
+		"literal wins over parameter": {"GET", "/items/latest", "getLatestItem", map[string]string{}},
+		"other method":                {"PUT", "/items/1", "updateItem", map[string]string{"itemId": "1"}},
+		"unknown method":              {"DELETE", "/items/1", "", nil},
+		"unknown path":                {"GET", "/items/1/children", "", nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			op, _, params, found := spec.FindOperation(tt.method, tt.path)
+
+			// verify
+			if tt.wantID == "" {
+				assert.False(t, found)
+				return
+			}
+			require.True(t, found)
+			assert.Equal(t, tt.wantID, op.OperationID)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
+
+func TestSpec_Parameters(t *testing.T) {
+	// prepare
+	spec := parseSpec(t, testSpec)
+	op, path, _, found := spec.FindOperation("GET", "/items/1")
+	require.True(t, found)
+
+	// execute
+	params := spec.Parameters(path, op)
+
+	// verify
+	require.Len(t, params, 2)
+	assert.Equal(t, "verbose", params[0].Name, "the parameters of the path are included")
+	assert.Equal(t, "itemId", params[1].Name)
+	assert.Equal(t, "ulid", params[1].Schema.Format, "the operation overrides the parameters of the path")
+}
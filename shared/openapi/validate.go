@@ -0,0 +1,264 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Violation is a value breaking a rule of a schema. Rules shared with the validate tags of the models are named
+// like the tags, so that clients see the same rules whichever layer rejects a request, other rules are named
+// after the keyword of the schema.
+type Violation struct {
+	// Name is the JSON path of the value, empty for the validated value itself
+	Name   string
+	Rule   string
+	Params []string
+	Reason string
+}
+
+// Validate validates a value decoded from JSON, numbers may be json.Number or float64
+func (s *Schema) Validate(value any) []Violation {
+	var violations []Violation
+	s.validate("", value, &violations)
+
+	return violations
+}
+
+// ValidateString validates the value of a path, query or header parameter, which is converted to the type of the
+// schema first
+func (s *Schema) ValidateString(name, value string) []Violation {
+	var violations []Violation
+	s.validate(name, s.parseString(value), &violations)
+
+	return violations
+}
+
+// parseString converts the value to the type of the schema, values which can not be converted are kept as strings
+// to be reported as type violations
+func (s *Schema) parseString(value string) any {
+	if s == nil {
+		return value
+	}
+
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.Number(value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+			return b
+		}
+	}
+
+	return value
+}
+
+func (s *Schema) validate(name string, value any, violations *[]Violation) {
+	if s == nil {
+		return
+	}
+
+	add := func(rule, reason string, params ...string) {
+		*violations = append(*violations, Violation{Name: name, Rule: rule, Params: params, Reason: reason})
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(name, value, violations)
+	}
+	if len(s.OneOf) > 0 && countMatches(s.OneOf, value) != 1 {
+		add("oneOf", "must match exactly one of the allowed schemas")
+	}
+	if len(s.AnyOf) > 0 && countMatches(s.AnyOf, value) == 0 {
+		add("anyOf", "must match at least one of the allowed schemas")
+	}
+
+	if value == nil {
+		if s.Type != "" && !s.Nullable {
+			add("type", typeReason(s.Type), s.Type)
+		}
+		return
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		add("type", typeReason(s.Type), s.Type)
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		params := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			params = append(params, fmt.Sprint(e))
+		}
+		add("oneof", "must be one of: "+strings.Join(params, ", "), params...)
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(v, add)
+	case json.Number, float64:
+		s.validateNumber(toFloat(v), add)
+	case []any:
+		s.validateArray(name, v, violations, add)
+	case map[string]any:
+		s.validateObject(name, v, violations)
+	}
+}
+
+func (s *Schema) validateString(v string, add func(rule, reason string, params ...string)) {
+	length := utf8.RuneCountInString(v)
+	if s.MinLength != nil && length < *s.MinLength {
+		add("min", fmt.Sprintf("must be at least %d characters long", *s.MinLength), strconv.Itoa(*s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		add("max", fmt.Sprintf("must be at most %d characters long", *s.MaxLength), strconv.Itoa(*s.MaxLength))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		add("pattern", "must match the pattern "+s.Pattern, s.Pattern)
+	}
+
+	switch s.Format {
+	case "email":
+		if addr, err := mail.ParseAddress(v); err != nil || addr.Address != v {
+			add("email", "must be a valid email address")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			add("datetime", "must be an RFC 3339 date-time", time.RFC3339)
+		}
+	case "ulid":
+		if _, err := ulid.ParseStrict(v); err != nil {
+			add("ulid", "must be a valid ULID")
+		}
+	case "uri":
+		if u, err := url.Parse(v); err != nil || !u.IsAbs() {
+			add("uri", "must be an absolute URI")
+		}
+	}
+}
+
+func (s *Schema) validateNumber(v float64, add func(rule, reason string, params ...string)) {
+	if s.Minimum != nil && v < *s.Minimum {
+		add("min", "must be at least "+formatFloat(*s.Minimum), formatFloat(*s.Minimum))
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		add("max", "must be at most "+formatFloat(*s.Maximum), formatFloat(*s.Maximum))
+	}
+}
+
+func (s *Schema) validateArray(name string, v []any, violations *[]Violation, add func(rule, reason string, params ...string)) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		add("min", fmt.Sprintf("must contain at least %d items", *s.MinItems), strconv.Itoa(*s.MinItems))
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		add("max", fmt.Sprintf("must contain at most %d items", *s.MaxItems), strconv.Itoa(*s.MaxItems))
+	}
+
+	for i, item := range v {
+		s.Items.validate(fmt.Sprintf("%s[%d]", name, i), item, violations)
+	}
+}
+
+func (s *Schema) validateObject(name string, v map[string]any, violations *[]Violation) {
+	for _, field := range s.Required {
+		if _, ok := v[field]; !ok {
+			*violations = append(*violations, Violation{Name: join(name, field), Rule: "required", Reason: "is required"})
+		}
+	}
+
+	// sorted, so that the violations are reported in a stable order
+	for _, field := range slices.Sorted(maps.Keys(v)) {
+		if p, ok := s.Properties[field]; ok {
+			p.validate(join(name, field), v[field], violations)
+			continue
+		}
+
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if s.AdditionalProperties.Forbidden {
+			*violations = append(*violations, Violation{Name: join(name, field), Rule: "additionalProperties", Reason: "is not allowed"})
+			continue
+		}
+		s.AdditionalProperties.Schema.validate(join(name, field), v[field], violations)
+	}
+}
+
+// countMatches returns the number of schemas the value is valid against
+func countMatches(schemas []*Schema, value any) int {
+	n := 0
+	for _, sub := range schemas {
+		var violations []Violation
+		if sub.validate("", value, &violations); len(violations) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func hasType(value any, typ string) bool {
+	switch v := value.(type) {
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case json.Number, float64:
+		f := toFloat(v)
+		return typ == "number" || (typ == "integer" && f == math.Trunc(f) && !math.IsInf(f, 0))
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+
+	return false
+}
+
+func typeReason(typ string) string {
+	switch typ {
+	case "integer", "array", "object":
+		return "must be an " + typ
+	}
+
+	return "must be a " + typ
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	case float64:
+		return n
+	}
+
+	return math.NaN()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func join(name, field string) string {
+	if name == "" {
+		return field
+	}
+
+	return name + "." + field
+}
@@ -0,0 +1,212 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validationSpec = `paths: {}
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        name:
+          type: string
+          minLength: 1
+          maxLength: 5
+          pattern: '^[a-z]*$'
+        email:
+          type: string
+          format: email
+        id:
+          type: string
+          format: ulid
+        at:
+          type: string
+          format: date-time
+        link:
+          type: string
+          format: uri
+        count:
+          type: integer
+          minimum: 1
+          maximum: 10
+        ratio:
+          type: number
+        done:
+          type: boolean
+        note:
+          type: string
+          nullable: true
+        status:
+          type: string
+          enum: [open, closed]
+        tags:
+          type: array
+          minItems: 1
+          maxItems: 2
+          items:
+            type: string
+            maxLength: 3
+        meta:
+          type: object
+          additionalProperties:
+            type: integer
+        value: {}
+        choice:
+          oneOf:
+            - type: string
+            - type: integer
+      required:
+        - name
+      additionalProperties: false
+`
+
+func TestSchema_Validate(t *testing.T) {
+	// prepare
+	schema := parseSpec(t, validationSpec).Components.Schemas["Item"]
+
+	tests := map[string]struct {
+		body string
+		want []openapi.Violation
+	}{
+		"valid": {
+			body: `{"name":"milk","email":"john@example.com","id":"01K02SD13A5YKWWZFV9AQP7H1X","at":"2025-07-14T10:00:00Z",
+				"link":"https://example.com","count":3,"ratio":0.5,"done":true,"note":null,"status":"open","tags":["a"],
+				"meta":{"a":1},"value":[1,"a"],"choice":"a"}`,
+		},
+		"integral number": {body: `{"name":"a","count":2.0}`},
+		"required": {
+			body: `{}`,
+			want: []openapi.Violation{{Name: "name", Rule: "required", Reason: "is required"}},
+		},
+		"string rules": {
+			body: `{"name":"ABCDEF"}`,
+			want: []openapi.Violation{
+				{Name: "name", Rule: "max", Params: []string{"5"}, Reason: "must be at most 5 characters long"},
+				{Name: "name", Rule: "pattern", Params: []string{"^[a-z]*$"}, Reason: "must match the pattern ^[a-z]*$"},
+			},
+		},
+		"empty string": {
+			body: `{"name":""}`,
+			want: []openapi.Violation{{Name: "name", Rule: "min", Params: []string{"1"}, Reason: "must be at least 1 characters long"}},
+		},
+		"formats": {
+			body: `{"name":"a","email":"John <john@example.com>","id":"not-a-ulid","at":"yesterday","link":"/relative"}`,
+			want: []openapi.Violation{
+				{Name: "at", Rule: "datetime", Params: []string{"2006-01-02T15:04:05Z07:00"}, Reason: "must be an RFC 3339 date-time"},
+				{Name: "email", Rule: "email", Reason: "must be a valid email address"},
+				{Name: "id", Rule: "ulid", Reason: "must be a valid ULID"},
+				{Name: "link", Rule: "uri", Reason: "must be an absolute URI"},
+			},
+		},
+		"numbers": {
+			body: `{"name":"a","count":11}`,
+			want: []openapi.Violation{{Name: "count", Rule: "max", Params: []string{"10"}, Reason: "must be at most 10"}},
+		},
+		"types": {
+			body: `{"name":1,"count":1.5,"ratio":"1","done":"yes","tags":"a","meta":[]}`,
+			want: []openapi.Violation{
+				{Name: "count", Rule: "type", Params: []string{"integer"}, Reason: "must be an integer"},
+				{Name: "done", Rule: "type", Params: []string{"boolean"}, Reason: "must be a boolean"},
+				{Name: "meta", Rule: "type", Params: []string{"object"}, Reason: "must be an object"},
+				{Name: "name", Rule: "type", Params: []string{"string"}, Reason: "must be a string"},
+				{Name: "ratio", Rule: "type", Params: []string{"number"}, Reason: "must be a number"},
+				{Name: "tags", Rule: "type", Params: []string{"array"}, Reason: "must be an array"},
+			},
+		},
+		"null": {
+			body: `{"name":null}`,
+			want: []openapi.Violation{{Name: "name", Rule: "type", Params: []string{"string"}, Reason: "must be a string"}},
+		},
+		"enum": {
+			body: `{"name":"a","status":"done"}`,
+			want: []openapi.Violation{{Name: "status", Rule: "oneof", Params: []string{"open", "closed"}, Reason: "must be one of: open, closed"}},
+		},
+		"arrays": {
+			body: `{"name":"a","tags":["a","b","long"]}`,
+			want: []openapi.Violation{
+				{Name: "tags", Rule: "max", Params: []string{"2"}, Reason: "must contain at most 2 items"},
+				{Name: "tags[2]", Rule: "max", Params: []string{"3"}, Reason: "must be at most 3 characters long"},
+			},
+		},
+		"additional properties": {
+			body: `{"name":"a","owner":"me","meta":{"a":"b"}}`,
+			want: []openapi.Violation{
+				{Name: "meta.a", Rule: "type", Params: []string{"integer"}, Reason: "must be an integer"},
+				{Name: "owner", Rule: "additionalProperties", Reason: "is not allowed"},
+			},
+		},
+		"one of": {
+			body: `{"name":"a","choice":true}`,
+			want: []openapi.Violation{{Name: "choice", Rule: "oneOf", Reason: "must match exactly one of the allowed schemas"}},
+		},
+		"not an object": {
+			body: `[]`,
+			want: []openapi.Violation{{Rule: "type", Params: []string{"object"}, Reason: "must be an object"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// prepare
+			var value any
+			d := json.NewDecoder(strings.NewReader(tt.body))
+			d.UseNumber()
+			require.NoError(t, d.Decode(&value))
+
+			// execute
+			violations := schema.Validate(value)
+
+			// verify
+			assert.Equal(t, tt.want, violations)
+		})
+	}
+}
+
+func TestSchema_ValidateString(t *testing.T) {
+	// prepare
+	schemas := parseSpec(t, validationSpec).Components.Schemas["Item"].Properties
+
+	tests := map[string]struct {
+		schema string
+		value  string
+		want   []openapi.Violation
+	}{
+		"integer": {schema: "count", value: "3"},
+		"boolean": {schema: "done", value: "false"},
+		"number":  {schema: "ratio", value: "0.5"},
+		"string":  {schema: "name", value: "abc"},
+		"not an integer": {
+			schema: "count",
+			value:  "three",
+			want:   []openapi.Violation{{Name: "param", Rule: "type", Params: []string{"integer"}, Reason: "must be an integer"}},
+		},
+		"not a boolean": {
+			schema: "done",
+			value:  "1",
+			want:   []openapi.Violation{{Name: "param", Rule: "type", Params: []string{"boolean"}, Reason: "must be a boolean"}},
+		},
+		"out of range": {
+			schema: "count",
+			value:  "0",
+			want:   []openapi.Violation{{Name: "param", Rule: "min", Params: []string{"1"}, Reason: "must be at least 1"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			violations := schemas[tt.schema].ValidateString("param", tt.value)
+
+			// verify
+			assert.Equal(t, tt.want, violations)
+		})
+	}
+}
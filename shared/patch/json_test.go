@@ -0,0 +1,89 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPatch(t *testing.T) {
+	// Test cases mostly from RFC 6902, Appendix A
+	tests := map[string]struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		"add object member":   {`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		"add array element":   {`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		"append to array":     {`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		"add nested member":   {`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		"add null value":      {`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"foo":"bar","baz":null}`},
+		"remove object":       {`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		"remove array item":   {`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		"replace value":       {`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		"replace document":    {`{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`},
+		"move value":          {`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		"move array element":  {`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		"copy value":          {`{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`, `{"foo":{"bar":1},"baz":{"bar":2}}`},
+		"test success":        {`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		"escaped pointer":     {`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+		"empty patch":         {`{"foo":"bar"}`, `[]`, `{"foo":"bar"}`},
+		"operations in order": {`{}`, `[{"op":"add","path":"/a","value":[]},{"op":"add","path":"/a/0","value":1}]`, `{"a":[1]}`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			got, err := patch.JSONPatch([]byte(tt.doc), []byte(tt.patch))
+
+			// verify
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestJSONPatch_Errors(t *testing.T) {
+	tests := map[string]struct {
+		doc   string
+		patch string
+		want  error
+	}{
+		"not an array":             {`{}`, `{"op":"add","path":"/a","value":1}`, patch.ErrInvalidPatch},
+		"unknown operation":        {`{}`, `[{"op":"merge","path":"/a","value":1}]`, patch.ErrInvalidPatch},
+		"missing value":            {`{}`, `[{"op":"add","path":"/a"}]`, patch.ErrInvalidPatch},
+		"relative pointer":         {`{}`, `[{"op":"add","path":"a","value":1}]`, patch.ErrInvalidPatch},
+		"move into own child":      {`{"a":{}}`, `[{"op":"move","from":"/a","path":"/a/b"}]`, patch.ErrInvalidPatch},
+		"remove missing member":    {`{"a":1}`, `[{"op":"remove","path":"/b"}]`, patch.ErrPathNotFound},
+		"replace missing member":   {`{"a":1}`, `[{"op":"replace","path":"/b","value":1}]`, patch.ErrPathNotFound},
+		"add to missing parent":    {`{"q":{"bar":2}}`, `[{"op":"add","path":"/a/b","value":1}]`, patch.ErrPathNotFound},
+		"add out of array bounds":  {`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/3","value":"qux"}]`, patch.ErrPathNotFound},
+		"leading zero array index": {`{"foo":["bar","baz"]}`, `[{"op":"remove","path":"/foo/01"}]`, patch.ErrInvalidPatch},
+		"test mismatch":            {`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, patch.ErrTestFailed},
+		"test number as string":    {`{"/":9}`, `[{"op":"test","path":"/~1","value":"9"}]`, patch.ErrTestFailed},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			_, err := patch.JSONPatch([]byte(tt.doc), []byte(tt.patch))
+
+			// verify
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("failed patch is not applied partially", func(t *testing.T) {
+		// prepare
+		doc := []byte(`{"a":1}`)
+
+		// execute
+		_, err := patch.JSONPatch(doc, []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":3}]`))
+
+		// verify
+		assert.ErrorIs(t, err, patch.ErrTestFailed)
+		assert.JSONEq(t, `{"a":1}`, string(doc))
+	})
+}
@@ -0,0 +1,53 @@
+package patch_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7396, Appendix A
+	tests := map[string]struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		"replace member":          {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		"add member":              {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		"remove member":           {`{"a":"b"}`, `{"a":null}`, `{}`},
+		"remove one of many":      {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		"replace array by string": {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		"replace string by array": {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		"merge nested object":     {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		"arrays are replaced":     {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		"array document":          {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		"object to array":         {`{"a":"b"}`, `["c"]`, `["c"]`},
+		"null patch":              {`{"a":"foo"}`, `null`, `null`},
+		"string patch":            {`{"a":"foo"}`, `"bar"`, `"bar"`},
+		"null in new member":      {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		"array to object":         {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		"deep nulls":              {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			got, err := patch.MergePatch([]byte(tt.doc), []byte(tt.patch))
+
+			// verify
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("malformed patch", func(t *testing.T) {
+		// execute
+		_, err := patch.MergePatch([]byte(`{}`), []byte(`{"a":`))
+
+		// verify
+		assert.ErrorIs(t, err, patch.ErrInvalidPatch)
+	})
+}
@@ -0,0 +1,50 @@
+// Package patch applies partial updates to JSON documents, either as JSON merge patches (RFC 7396)
+// or as JSON patches (RFC 6902).
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Media types of the supported patch formats
+const (
+	MergePatchMediaType = "application/merge-patch+json"
+	JSONPatchMediaType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch means that the patch document is malformed
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPathNotFound means that an operation refers to a location which does not exist in the document
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTestFailed means that a test operation did not match the document
+	ErrTestFailed = errors.New("test failed")
+)
+
+// decode unmarshals a JSON document, rejecting trailing data
+func decode(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if d.More() {
+		return errors.New("unexpected data after the JSON document")
+	}
+
+	return nil
+}
+
+// Apply applies a patch of the given media type to the document
+func Apply(mediaType string, doc, patch []byte) ([]byte, error) {
+	switch mediaType {
+	case MergePatchMediaType:
+		return MergePatch(doc, patch)
+	case JSONPatchMediaType:
+		return JSONPatch(doc, patch)
+	}
+
+	return nil, fmt.Errorf("unsupported media type: %s, err: %w", mediaType, ErrInvalidPatch)
+}
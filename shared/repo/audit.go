@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/peteraba/go-frameworks/shared/model"
+)
+
+const (
+	defaultAuditQueryLimit = 100
+	maxAuditQueryLimit     = 1000
+)
+
+// AuditSink is an append-only store of audit events. Events can never be updated or deleted.
+type AuditSink interface {
+	Append(event model.AuditEvent) (model.AuditEvent, error)
+	Query(filter model.AuditEventFilter) ([]model.AuditEvent, error)
+}
+
+// prepareAuditEvent fills in the ID and timestamp of an event about to be appended
+func prepareAuditEvent(event model.AuditEvent) model.AuditEvent {
+	if event.ID == "" {
+		event.ID = ulid.Make().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+
+	return event
+}
+
+func auditQueryLimit(filter model.AuditEventFilter) int {
+	if filter.Limit <= 0 {
+		return defaultAuditQueryLimit
+	}
+	if filter.Limit > maxAuditQueryLimit {
+		return maxAuditQueryLimit
+	}
+
+	return filter.Limit
+}
+
+type InMemoryAuditSink struct {
+	mu     sync.RWMutex
+	events []model.AuditEvent
+}
+
+func NewInMemoryAuditSink() *InMemoryAuditSink {
+	return &InMemoryAuditSink{}
+}
+
+func (s *InMemoryAuditSink) Append(event model.AuditEvent) (model.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event = prepareAuditEvent(event)
+
+	s.events = append(s.events, event)
+
+	return event, nil
+}
+
+func (s *InMemoryAuditSink) Query(filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit := auditQueryLimit(filter)
+
+	events := make([]model.AuditEvent, 0)
+	for _, event := range s.events {
+		if len(events) >= limit {
+			break
+		}
+
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// JSONLinesAuditSink appends audit events to a file, one JSON document per line.
+type JSONLinesAuditSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func NewJSONLinesAuditSink(path string) (*JSONLinesAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %s, err: %w", path, err)
+	}
+
+	return &JSONLinesAuditSink{path: path, file: file}, nil
+}
+
+func (s *JSONLinesAuditSink) Append(event model.AuditEvent) (model.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event = prepareAuditEvent(event)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return model.AuditEvent{}, fmt.Errorf("failed to encode audit event, err: %w", err)
+	}
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return model.AuditEvent{}, fmt.Errorf("failed to write audit event, err: %w", err)
+	}
+
+	return event, nil
+}
+
+func (s *JSONLinesAuditSink) Query(filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %s, err: %w", s.path, err)
+	}
+	defer file.Close()
+
+	limit := auditQueryLimit(filter)
+
+	events := make([]model.AuditEvent, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() && len(events) < limit {
+		var event model.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode audit event, err: %w", err)
+		}
+
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %s, err: %w", s.path, err)
+	}
+
+	return events, nil
+}
+
+func (s *JSONLinesAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
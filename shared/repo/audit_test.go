@@ -0,0 +1,110 @@
+package repo_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONLinesAuditSink(t *testing.T) repo.AuditSink {
+	t.Helper()
+
+	s, err := repo.NewJSONLinesAuditSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, s.Close())
+	})
+
+	return s
+}
+
+func TestAuditSinks(t *testing.T) {
+	sinks := map[string]func(t *testing.T) repo.AuditSink{
+		"in-memory": func(t *testing.T) repo.AuditSink {
+			return repo.NewInMemoryAuditSink()
+		},
+		"json-lines": newJSONLinesAuditSink,
+	}
+
+	for name, factory := range sinks {
+		t.Run(name, func(t *testing.T) {
+			t.Run("append fills in id and timestamp", func(t *testing.T) {
+				// prepare
+				s := factory(t)
+				eventStub := model.AuditEvent{
+					Type:    model.AuditEventLogin,
+					Actor:   "user-1",
+					Outcome: model.AuditOutcomeSuccess,
+				}
+
+				// execute
+				event, err := s.Append(eventStub)
+
+				// verify
+				require.NoError(t, err)
+				assert.NotEmpty(t, event.ID)
+				assert.False(t, event.Timestamp.IsZero())
+				assert.NoError(t, event.Validate())
+			})
+
+			t.Run("query filters events", func(t *testing.T) {
+				// prepare
+				s := factory(t)
+				now := time.Now().UTC()
+				e1, err := s.Append(model.AuditEvent{Type: model.AuditEventLogin, Actor: "user-1", Outcome: model.AuditOutcomeSuccess, Timestamp: now.Add(-time.Hour)})
+				require.NoError(t, err)
+				e2, err := s.Append(model.AuditEvent{Type: model.AuditEventLoginFailed, Actor: "user-1", Outcome: model.AuditOutcomeFailure, Timestamp: now})
+				require.NoError(t, err)
+				e3, err := s.Append(model.AuditEvent{Type: model.AuditEventAccessDenied, Actor: "user-2", Outcome: model.AuditOutcomeDenied, Timestamp: now})
+				require.NoError(t, err)
+
+				// execute
+				all, err := s.Query(model.AuditEventFilter{})
+				require.NoError(t, err)
+				byActor, err := s.Query(model.AuditEventFilter{Actor: "user-1"})
+				require.NoError(t, err)
+				byOutcome, err := s.Query(model.AuditEventFilter{Outcome: model.AuditOutcomeDenied})
+				require.NoError(t, err)
+				since, err := s.Query(model.AuditEventFilter{Since: now.Add(-time.Minute)})
+				require.NoError(t, err)
+				after, err := s.Query(model.AuditEventFilter{After: e1.ID, Limit: 1})
+				require.NoError(t, err)
+
+				// verify
+				assert.Equal(t, []model.AuditEvent{e1, e2, e3}, all)
+				assert.Equal(t, []model.AuditEvent{e1, e2}, byActor)
+				assert.Equal(t, []model.AuditEvent{e3}, byOutcome)
+				assert.Equal(t, []model.AuditEvent{e2, e3}, since)
+				assert.Equal(t, []model.AuditEvent{e2}, after)
+			})
+		})
+	}
+}
+
+func TestJSONLinesAuditSink_Persistence(t *testing.T) {
+	// prepare
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	s1, err := repo.NewJSONLinesAuditSink(path)
+	require.NoError(t, err)
+	eventStub, err := s1.Append(model.RandomAuditEvent())
+	require.NoError(t, err)
+	require.NoError(t, s1.Close())
+
+	// execute
+	s2, err := repo.NewJSONLinesAuditSink(path)
+	require.NoError(t, err)
+	defer s2.Close()
+	events, err := s2.Query(model.AuditEventFilter{})
+
+	// verify
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, eventStub.ID, events[0].ID)
+	assert.True(t, eventStub.Timestamp.Equal(events[0].Timestamp))
+}
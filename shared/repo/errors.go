@@ -0,0 +1,16 @@
+package repo
+
+import "errors"
+
+// Error kinds returned by the repos. Errors about specific entities wrap one of these,
+// so callers can decide how to react without knowing every entity.
+var (
+	// ErrNotFound means that the requested entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means that the change would conflict with the current state of the data
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed means that the entity has changed since the caller last read it
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrInvalidQuery means that a query refers to unknown fields or contains malformed values, see QueryError
+	ErrInvalidQuery = errors.New("invalid query")
+)
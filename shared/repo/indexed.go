@@ -0,0 +1,180 @@
+package repo
+
+import (
+	"sync"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/search"
+)
+
+// Weights of the indexed fields, matches in names and titles rank higher than the ones in descriptions
+const (
+	titleWeight       = 2
+	descriptionWeight = 1
+)
+
+// IndexedProjectRepo keeps a search index up to date with every change of the projects of the wrapped repo.
+// Reads are passed to the wrapped repo as is.
+type IndexedProjectRepo struct {
+	ProjectRepo
+	index *search.Index
+	// mu serializes the changes, so that the index receives them in the order they were made
+	mu sync.Mutex
+}
+
+func NewIndexedProjectRepo(r ProjectRepo, index *search.Index) *IndexedProjectRepo {
+	return &IndexedProjectRepo{ProjectRepo: r, index: index}
+}
+
+func (r *IndexedProjectRepo) put(project model.Project) {
+	r.index.Put(search.Key{Type: model.SearchHitProject, ID: project.ID},
+		search.Field{Text: project.Name, Weight: titleWeight},
+		search.Field{Text: project.Description, Weight: descriptionWeight},
+	)
+}
+
+func (r *IndexedProjectRepo) Create(pc model.ProjectCreate) (model.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, err := r.ProjectRepo.Create(pc)
+	if err == nil {
+		r.put(project)
+	}
+
+	return project, err
+}
+
+func (r *IndexedProjectRepo) Update(id string, precondition Precondition, update model.ProjectUpdate) (model.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, err := r.ProjectRepo.Update(id, precondition, update)
+	if err == nil {
+		r.put(project)
+	}
+
+	return project, err
+}
+
+func (r *IndexedProjectRepo) Delete(id string, precondition Precondition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.ProjectRepo.Delete(id, precondition)
+	if err == nil {
+		r.index.Remove(search.Key{Type: model.SearchHitProject, ID: id})
+	}
+
+	return err
+}
+
+// IndexedListRepo keeps a search index up to date with every change of the lists of the wrapped repo
+type IndexedListRepo struct {
+	ListRepo
+	index *search.Index
+	// mu serializes the changes, so that the index receives them in the order they were made
+	mu sync.Mutex
+}
+
+func NewIndexedListRepo(r ListRepo, index *search.Index) *IndexedListRepo {
+	return &IndexedListRepo{ListRepo: r, index: index}
+}
+
+func (r *IndexedListRepo) put(list model.List) {
+	r.index.Put(search.Key{Type: model.SearchHitList, ID: list.ID},
+		search.Field{Text: list.Name, Weight: titleWeight},
+		search.Field{Text: list.Description, Weight: descriptionWeight},
+	)
+}
+
+func (r *IndexedListRepo) Create(lc model.ListCreate) (model.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.ListRepo.Create(lc)
+	if err == nil {
+		r.put(list)
+	}
+
+	return list, err
+}
+
+func (r *IndexedListRepo) Update(id string, precondition Precondition, update model.ListUpdate) (model.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.ListRepo.Update(id, precondition, update)
+	if err == nil {
+		r.put(list)
+	}
+
+	return list, err
+}
+
+func (r *IndexedListRepo) Delete(id string, precondition Precondition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.ListRepo.Delete(id, precondition)
+	if err == nil {
+		r.index.Remove(search.Key{Type: model.SearchHitList, ID: id})
+	}
+
+	return err
+}
+
+// IndexedTodoRepo keeps a search index up to date with every change of the todo items of the wrapped repo
+type IndexedTodoRepo struct {
+	TodoRepo
+	index *search.Index
+	// mu serializes the changes, so that the index receives them in the order they were made
+	mu sync.Mutex
+}
+
+func NewIndexedTodoRepo(r TodoRepo, index *search.Index) *IndexedTodoRepo {
+	return &IndexedTodoRepo{TodoRepo: r, index: index}
+}
+
+func (r *IndexedTodoRepo) put(todo model.Todo) {
+	r.index.Put(search.Key{Type: model.SearchHitTodo, ID: todo.ID},
+		search.Field{Text: todo.Title, Weight: titleWeight},
+		search.Field{Text: todo.Description, Weight: descriptionWeight},
+	)
+}
+
+func (r *IndexedTodoRepo) Create(tc model.TodoCreate) (model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, err := r.TodoRepo.Create(tc)
+	if err == nil {
+		r.put(todo)
+	}
+
+	return todo, err
+}
+
+func (r *IndexedTodoRepo) Update(id string, precondition Precondition, update model.TodoUpdate) (model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, err := r.TodoRepo.Update(id, precondition, update)
+	if err == nil {
+		r.put(todo)
+	}
+
+	return todo, err
+}
+
+func (r *IndexedTodoRepo) Delete(id string, precondition Precondition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.TodoRepo.Delete(id, precondition)
+	if err == nil {
+		r.index.Remove(search.Key{Type: model.SearchHitTodo, ID: id})
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedTodoRepo(t *testing.T) {
+	// prepare
+	index := search.NewIndex()
+	r := repo.NewIndexedTodoRepo(repo.NewInMemoryTodoRepo(), index)
+
+	todo, err := r.Create(model.TodoCreate{ListID: "list-1", Title: "Buy milk", Description: "Bio, discounted"})
+	require.NoError(t, err)
+	key := search.Key{Type: model.SearchHitTodo, ID: todo.ID}
+
+	t.Run("created items are indexed", func(t *testing.T) {
+		// execute
+		results := index.Search("discounted milk", nil, 10)
+
+		// verify
+		require.Len(t, results, 1)
+		assert.Equal(t, key, results[0].Key)
+	})
+
+	t.Run("updated items are reindexed", func(t *testing.T) {
+		// execute
+		_, err := r.Update(todo.ID, repo.AnyVersion, model.TodoUpdate{Title: "Buy bread"})
+
+		// verify
+		require.NoError(t, err)
+		assert.Empty(t, index.Search("milk", nil, 10))
+		assert.Len(t, index.Search("bread", nil, 10), 1)
+	})
+
+	t.Run("failed changes do not touch the index", func(t *testing.T) {
+		// execute
+		_, err := r.Update(todo.ID, repo.Version(0), model.TodoUpdate{Title: "Buy cheese"})
+
+		// verify
+		require.ErrorIs(t, err, repo.ErrPreconditionFailed)
+		assert.Empty(t, index.Search("cheese", nil, 10))
+		assert.Len(t, index.Search("bread", nil, 10), 1)
+	})
+
+	t.Run("deleted items are removed", func(t *testing.T) {
+		// execute
+		err := r.Delete(todo.ID, repo.AnyVersion)
+
+		// verify
+		require.NoError(t, err)
+		assert.Zero(t, index.Len())
+	})
+}
+
+func TestIndexedProjectRepo(t *testing.T) {
+	// prepare
+	index := search.NewIndex()
+	r := repo.NewIndexedProjectRepo(repo.NewInMemoryProjectRepo(), index)
+
+	// execute
+	project, err := r.Create(model.ProjectCreate{Name: "Shopping"})
+	require.NoError(t, err)
+	_, err = r.Update(project.ID, repo.AnyVersion, model.ProjectUpdate{Name: "Groceries"})
+	require.NoError(t, err)
+
+	// verify
+	assert.Empty(t, index.Search("shopping", nil, 10))
+	results := index.Search("groceries", nil, 10)
+	require.Len(t, results, 1)
+	assert.Equal(t, search.Key{Type: model.SearchHitProject, ID: project.ID}, results[0].Key)
+
+	require.NoError(t, r.Delete(project.ID, repo.AnyVersion))
+	assert.Zero(t, index.Len())
+}
+
+func TestIndexedListRepo(t *testing.T) {
+	// prepare
+	index := search.NewIndex()
+	r := repo.NewIndexedListRepo(repo.NewInMemoryListRepo(), index)
+
+	// execute
+	list, err := r.Create(model.ListCreate{ProjectID: "project-1", Name: "Weekend", Description: "Errands"})
+	require.NoError(t, err)
+	_, err = r.Update(list.ID, repo.AnyVersion, model.ListUpdate{Name: "Weekdays"})
+	require.NoError(t, err)
+
+	// verify
+	assert.Empty(t, index.Search("errands", nil, 10))
+	assert.Len(t, index.Search("weekdays", nil, 10), 1)
+
+	require.NoError(t, r.Delete(list.ID, repo.AnyVersion))
+	assert.Zero(t, index.Len())
+}
@@ -1,34 +1,42 @@
 package repo
 
 import (
-	"errors"
-	"sort"
+	"fmt"
 	"sync"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/peteraba/go-frameworks/shared/model"
 )
 
-const maxListListLength = 100
+// DefaultListListLimit is the maximum number of lists returned by a single list request by default
+const DefaultListListLimit = 100
 
 type ListRepo interface {
 	Create(list model.ListCreate) (model.List, error)
 	GetByID(id string) (model.List, error)
-	Update(id string, update model.ListUpdate) (model.List, error)
-	Delete(id string) error
-	List() ([]model.List, error)
+	Update(id string, precondition Precondition, update model.ListUpdate) (model.List, error)
+	Delete(id string, precondition Precondition) error
+	List(query Query) ([]model.List, error)
 }
 
+// ErrListNotFound
+var ErrListNotFound = fmt.Errorf("list %w", ErrNotFound)
+
 type InMemoryListRepo struct {
 	mu    sync.RWMutex
 	lists map[string]model.List
-	ids   []string
-	dirty bool
+	limit int
 }
 
 func NewInMemoryListRepo() *InMemoryListRepo {
+	return NewInMemoryListRepoWithLimit(DefaultListListLimit)
+}
+
+// NewInMemoryListRepoWithLimit returns a repo returning at most limit lists per list request
+func NewInMemoryListRepoWithLimit(limit int) *InMemoryListRepo {
 	return &InMemoryListRepo{
 		lists: make(map[string]model.List),
+		limit: limit,
 	}
 }
 
@@ -41,15 +49,14 @@ func (r *InMemoryListRepo) Create(list model.ListCreate) (model.List, error) {
 		ProjectID:   list.ProjectID,
 		Name:        list.Name,
 		Description: list.Description,
+		Version:     1,
 	}
 
 	if _, exists := r.lists[listModel.ID]; exists {
-		return model.List{}, errors.New("list already exists")
+		return model.List{}, fmt.Errorf("already exists: %s, err: %w", listModel.ID, ErrConflict)
 	}
 
 	r.lists[listModel.ID] = listModel
-	r.ids = append(r.ids, listModel.ID)
-	r.dirty = false
 
 	return listModel, nil
 }
@@ -69,39 +76,47 @@ func (r *InMemoryListRepo) GetByID(id string) (model.List, error) {
 
 	list, exists := r.lists[id]
 	if !exists {
-		return model.List{}, errors.New("list not found")
+		return model.List{}, fmt.Errorf("not found: %s, err: %w", id, ErrListNotFound)
 	}
 
 	return list, nil
 }
 
-func (r *InMemoryListRepo) Update(id string, update model.ListUpdate) (model.List, error) {
+// Update replaces the list if the precondition holds for its current version, and increments the version
+func (r *InMemoryListRepo) Update(id string, precondition Precondition, update model.ListUpdate) (model.List, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	list, exists := r.lists[id]
 	if !exists {
-		return model.List{}, errors.New("list not found")
+		return model.List{}, fmt.Errorf("not found: %s, err: %w", id, ErrListNotFound)
 	}
 
-	if update.Name != "" {
-		list.Name = update.Name
-	}
-	if update.Description != "" {
-		list.Description = update.Description
+	if err := precondition.Check(id, list.Version); err != nil {
+		return model.List{}, err
 	}
 
+	list.Name = update.Name
+	list.Description = update.Description
+	list.Version++
+
 	r.lists[id] = list
 
 	return list, nil
 }
 
-func (r *InMemoryListRepo) Delete(id string) error {
+// Delete removes the list if the precondition holds for its current version
+func (r *InMemoryListRepo) Delete(id string, precondition Precondition) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.lists[id]; !exists {
-		return errors.New("list not found")
+	list, exists := r.lists[id]
+	if !exists {
+		return fmt.Errorf("not found: %s, err: %w", id, ErrListNotFound)
+	}
+
+	if err := precondition.Check(id, list.Version); err != nil {
+		return err
 	}
 
 	delete(r.lists, id)
@@ -109,35 +124,19 @@ func (r *InMemoryListRepo) Delete(id string) error {
 	return nil
 }
 
-func (r *InMemoryListRepo) List() ([]model.List, error) {
+// listFields are the fields of a list which can be used in queries, name and description are searched
+var listFields = fields[model.List]{
+	"id":          stringField(func(l model.List) string { return l.ID }, false),
+	"projectId":   stringField(func(l model.List) string { return l.ProjectID }, false),
+	"name":        stringField(func(l model.List) string { return l.Name }, true),
+	"description": stringField(func(l model.List) string { return l.Description }, true),
+	"version":     intField(func(l model.List) int { return l.Version }),
+}
+
+// List returns the lists matching the query, a QueryError is returned if the query is invalid
+func (r *InMemoryListRepo) List(query Query) ([]model.List, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.dirty {
-		keys := make([]string, 0, len(r.lists))
-		for k := range r.lists {
-			keys = append(keys, k)
-		}
-
-		sort.Strings(keys)
-
-		r.dirty = false
-		r.ids = keys
-	}
-
-	l := len(r.lists)
-	if l > maxListListLength {
-		l = maxListListLength
-	}
-
-	lists := make([]model.List, 0, l)
-	for i, key := range r.ids {
-		if i >= l {
-			break
-		}
-
-		lists = append(lists, r.lists[key])
-	}
-
-	return lists, nil
+	return find(r.lists, listFields, query, r.limit)
 }
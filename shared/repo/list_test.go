@@ -55,7 +55,8 @@ func TestInMemoryListRepo_GetByID(t *testing.T) {
 		_, err := r.GetByID("non-existing-id")
 
 		assert.Error(t, err)
-		assert.Equal(t, "list not found", err.Error())
+		assert.ErrorIs(t, err, repo.ErrListNotFound)
+		assert.ErrorIs(t, err, repo.ErrNotFound)
 	})
 }
 
@@ -71,7 +72,7 @@ func TestInMemoryListRepo_Update(t *testing.T) {
 		listUpdateStub := model.RandomListUpdate()
 
 		// execute
-		updated, err := r.Update(createdListStub.ID, listUpdateStub)
+		updated, err := r.Update(createdListStub.ID, repo.AnyVersion, listUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -87,6 +88,36 @@ func TestInMemoryListRepo_Update(t *testing.T) {
 		assert.Equal(t, listUpdateStub.Description, retrieved.Description)
 	})
 
+	t.Run("version is incremented", func(t *testing.T) {
+		// prepare
+		listCreateStub := model.RandomListCreate()
+		listStub, err := r.Create(listCreateStub)
+		require.NoError(t, err)
+
+		// execute
+		updated, err := r.Update(listStub.ID, repo.Version(listStub.Version), model.ListUpdate{Name: "Updated Name"})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, 1, listStub.Version)
+		assert.Equal(t, 2, updated.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		// prepare
+		listCreateStub := model.RandomListCreate()
+		listStub, err := r.Create(listCreateStub)
+		require.NoError(t, err)
+		_, err = r.Update(listStub.ID, repo.Version(listStub.Version), model.ListUpdate{Name: "Updated Name"})
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.Update(listStub.ID, repo.Version(listStub.Version), model.ListUpdate{Name: "Updated Name"})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
 	t.Run("non-existing list", func(t *testing.T) {
 		// prepare
 		listUpdateStub := model.ListUpdate{
@@ -94,11 +125,11 @@ func TestInMemoryListRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		_, err := r.Update("non-existing-id", listUpdateStub)
+		_, err := r.Update("non-existing-id", repo.AnyVersion, listUpdateStub)
 
 		// verify
 		assert.Error(t, err)
-		assert.Equal(t, "list not found", err.Error())
+		assert.ErrorIs(t, err, repo.ErrListNotFound)
 	})
 }
 
@@ -113,7 +144,7 @@ func TestInMemoryListRepo_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// execute
-		err = r.Delete(listStub.ID)
+		err = r.Delete(listStub.ID, repo.AnyVersion)
 
 		// verify
 		assert.NoError(t, err)
@@ -121,13 +152,27 @@ func TestInMemoryListRepo_Delete(t *testing.T) {
 		assert.False(t, r.Has(listStub.ID))
 	})
 
+	t.Run("stale version", func(t *testing.T) {
+		// prepare
+		listCreateStub := model.RandomListCreate()
+		listStub, err := r.Create(listCreateStub)
+		require.NoError(t, err)
+
+		// execute
+		err = r.Delete(listStub.ID, repo.Version(listStub.Version+1))
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+		assert.True(t, r.Has(listStub.ID))
+	})
+
 	t.Run("non-existing list", func(t *testing.T) {
 		// execute
-		err := r.Delete("non-existing-id")
+		err := r.Delete("non-existing-id", repo.AnyVersion)
 
 		// verify
 		assert.Error(t, err)
-		assert.Equal(t, "list not found", err.Error())
+		assert.ErrorIs(t, err, repo.ErrListNotFound)
 	})
 }
 
@@ -136,7 +181,7 @@ func TestInMemoryListRepo_List(t *testing.T) {
 
 	t.Run("empty repo", func(t *testing.T) {
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -155,7 +200,7 @@ func TestInMemoryListRepo_List(t *testing.T) {
 		require.NoError(t, err)
 
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -173,7 +218,7 @@ func TestInMemoryListRepo_List(t *testing.T) {
 		}
 
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -201,7 +246,7 @@ func TestInMemoryListRepo_Concurrency(t *testing.T) {
 		// goroutine 2: Read lists
 		go func() {
 			for range 100 {
-				_, err := r.List()
+				_, err := r.List(repo.Query{})
 				require.NoError(t, err)
 			}
 			done <- true
@@ -211,7 +256,7 @@ func TestInMemoryListRepo_Concurrency(t *testing.T) {
 		<-done
 
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify that no panic occurred and data is consistent
 		assert.NoError(t, err)
@@ -1,38 +1,42 @@
 package repo
 
 import (
-	"errors"
 	"fmt"
-	"sort"
 	"sync"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/peteraba/go-frameworks/shared/model"
 )
 
-const maxProjectListLength = 100
+// DefaultProjectListLimit is the maximum number of projects returned by a single list request by default
+const DefaultProjectListLimit = 100
 
 type ProjectRepo interface {
 	Create(project model.ProjectCreate) (model.Project, error)
 	GetByID(id string) (model.Project, error)
-	Update(id string, update model.ProjectUpdate) (model.Project, error)
-	Delete(id string) error
-	List() ([]model.Project, error)
+	Update(id string, precondition Precondition, update model.ProjectUpdate) (model.Project, error)
+	Delete(id string, precondition Precondition) error
+	List(query Query) ([]model.Project, error)
 }
 
 // ErrProjectNotFound
-var ErrProjectNotFound = errors.New("project not found")
+var ErrProjectNotFound = fmt.Errorf("project %w", ErrNotFound)
 
 type InMemoryProjectRepo struct {
 	mu       sync.RWMutex
 	projects map[string]model.Project
-	ids      []string
-	dirty    bool
+	limit    int
 }
 
 func NewInMemoryProjectRepo() *InMemoryProjectRepo {
+	return NewInMemoryProjectRepoWithLimit(DefaultProjectListLimit)
+}
+
+// NewInMemoryProjectRepoWithLimit returns a repo returning at most limit projects per list request
+func NewInMemoryProjectRepoWithLimit(limit int) *InMemoryProjectRepo {
 	return &InMemoryProjectRepo{
 		projects: make(map[string]model.Project),
+		limit:    limit,
 	}
 }
 
@@ -44,15 +48,14 @@ func (r *InMemoryProjectRepo) Create(project model.ProjectCreate) (model.Project
 		ID:          ulid.Make().String(),
 		Name:        project.Name,
 		Description: project.Description,
+		Version:     1,
 	}
 
 	if _, exists := r.projects[p.ID]; exists {
-		return model.Project{}, fmt.Errorf("not found: %s, err: %w", p.ID, ErrProjectNotFound)
+		return model.Project{}, fmt.Errorf("already exists: %s, err: %w", p.ID, ErrConflict)
 	}
 
 	r.projects[p.ID] = p
-	r.ids = append(r.ids, p.ID)
-	r.dirty = true
 
 	return p, nil
 }
@@ -69,7 +72,8 @@ func (r *InMemoryProjectRepo) GetByID(id string) (model.Project, error) {
 	return project, nil
 }
 
-func (r *InMemoryProjectRepo) Update(id string, update model.ProjectUpdate) (model.Project, error) {
+// Update replaces the project if the precondition holds for its current version, and increments the version
+func (r *InMemoryProjectRepo) Update(id string, precondition Precondition, update model.ProjectUpdate) (model.Project, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -78,62 +82,52 @@ func (r *InMemoryProjectRepo) Update(id string, update model.ProjectUpdate) (mod
 		return model.Project{}, fmt.Errorf("not found: %s, err: %w", id, ErrProjectNotFound)
 	}
 
-	if update.Name != "" {
-		project.Name = update.Name
-	}
-	if update.Description != "" {
-		project.Description = update.Description
+	if err := precondition.Check(id, project.Version); err != nil {
+		return model.Project{}, err
 	}
 
+	project.Name = update.Name
+	project.Description = update.Description
+	project.Version++
+
 	r.projects[id] = project
 
 	return project, nil
 }
 
-func (r *InMemoryProjectRepo) Delete(id string) error {
+// Delete removes the project if the precondition holds for its current version
+func (r *InMemoryProjectRepo) Delete(id string, precondition Precondition) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.projects[id]; !exists {
+	project, exists := r.projects[id]
+	if !exists {
 		return fmt.Errorf("not found: %s, err: %w", id, ErrProjectNotFound)
 	}
 
+	if err := precondition.Check(id, project.Version); err != nil {
+		return err
+	}
+
 	delete(r.projects, id)
 
 	return nil
 }
 
-func (r *InMemoryProjectRepo) List() ([]model.Project, error) {
+// projectFields are the fields of a project which can be used in queries, name and description are searched
+var projectFields = fields[model.Project]{
+	"id":          stringField(func(p model.Project) string { return p.ID }, false),
+	"name":        stringField(func(p model.Project) string { return p.Name }, true),
+	"description": stringField(func(p model.Project) string { return p.Description }, true),
+	"version":     intField(func(p model.Project) int { return p.Version }),
+}
+
+// List returns the projects matching the query, a QueryError is returned if the query is invalid
+func (r *InMemoryProjectRepo) List(query Query) ([]model.Project, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.dirty {
-		keys := make([]string, 0, len(r.projects))
-		for k := range r.projects {
-			keys = append(keys, k)
-		}
-
-		sort.Strings(keys)
-
-		r.dirty = false
-		r.ids = keys
-	}
-
-	l := len(r.projects)
-	if l > maxProjectListLength {
-		l = maxProjectListLength
-	}
-
-	projects := make([]model.Project, 0, l)
-	for i, key := range r.ids {
-		if i >= l {
-			break
-		}
-
-		projects = append(projects, r.projects[key])
-	}
-
-	return projects, nil
+	return find(r.projects, projectFields, query, r.limit)
 }
 
 func (r *InMemoryProjectRepo) Has(id string) bool {
@@ -51,6 +51,7 @@ func TestInMemoryProjectRepo_GetByID(t *testing.T) {
 		// verify
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, repo.ErrProjectNotFound)
+		assert.ErrorIs(t, err, repo.ErrNotFound)
 	})
 }
 
@@ -68,7 +69,7 @@ func TestInMemoryProjectRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		updated, err := r.Update(projectStub.ID, projectUpdateStub)
+		updated, err := r.Update(projectStub.ID, repo.AnyVersion, projectUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -77,7 +78,7 @@ func TestInMemoryProjectRepo_Update(t *testing.T) {
 		assert.Equal(t, projectStub.ID, updated.ID)
 	})
 
-	t.Run("partial update", func(t *testing.T) {
+	t.Run("omitted fields are cleared", func(t *testing.T) {
 		// prepare
 		projectCreateStub := model.RandomProjectCreate()
 		projectStub, err := r.Create(projectCreateStub)
@@ -87,40 +88,54 @@ func TestInMemoryProjectRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		updated, err := r.Update(projectStub.ID, projectUpdateStub)
+		updated, err := r.Update(projectStub.ID, repo.AnyVersion, projectUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
 		assert.Equal(t, "Only Name Updated", updated.Name)
-		assert.Equal(t, projectStub.Description, updated.Description)
+		assert.Empty(t, updated.Description)
 	})
 
-	t.Run("non-existing project", func(t *testing.T) {
+	t.Run("version is incremented", func(t *testing.T) {
 		// prepare
-		projectUpdateStub := model.ProjectUpdate{Name: "Updated Name"}
+		projectCreateStub := model.RandomProjectCreate()
+		projectStub, err := r.Create(projectCreateStub)
+		require.NoError(t, err)
 
 		// execute
-		_, err := r.Update("non-existing-id", projectUpdateStub)
+		updated, err := r.Update(projectStub.ID, repo.Version(projectStub.Version), model.ProjectUpdate{Name: "Updated Name"})
 
 		// verify
-		assert.Error(t, err)
-		assert.ErrorIs(t, err, repo.ErrProjectNotFound)
+		require.NoError(t, err)
+		assert.Equal(t, 1, projectStub.Version)
+		assert.Equal(t, 2, updated.Version)
 	})
 
-	t.Run("empty update fields are ignored", func(t *testing.T) {
+	t.Run("stale version", func(t *testing.T) {
 		// prepare
 		projectCreateStub := model.RandomProjectCreate()
 		projectStub, err := r.Create(projectCreateStub)
 		require.NoError(t, err)
-		projectUpdateStub := model.ProjectUpdate{}
+		_, err = r.Update(projectStub.ID, repo.Version(projectStub.Version), model.ProjectUpdate{Name: "Updated Name"})
+		require.NoError(t, err)
 
 		// execute
-		updated, err := r.Update(projectStub.ID, projectUpdateStub)
+		_, err = r.Update(projectStub.ID, repo.Version(projectStub.Version), model.ProjectUpdate{Name: "Updated Name"})
 
 		// verify
-		assert.NoError(t, err)
-		assert.Equal(t, projectStub.Name, updated.Name)
-		assert.Equal(t, projectStub.Description, updated.Description)
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("non-existing project", func(t *testing.T) {
+		// prepare
+		projectUpdateStub := model.ProjectUpdate{Name: "Updated Name"}
+
+		// execute
+		_, err := r.Update("non-existing-id", repo.AnyVersion, projectUpdateStub)
+
+		// verify
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, repo.ErrProjectNotFound)
 	})
 }
 
@@ -134,15 +149,29 @@ func TestInMemoryProjectRepo_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// execute
-		err = r.Delete(projectStub.ID)
+		err = r.Delete(projectStub.ID, repo.AnyVersion)
 
 		// verify
 		assert.NoError(t, err)
 	})
 
+	t.Run("stale version", func(t *testing.T) {
+		// prepare
+		projectCreateStub := model.RandomProjectCreate()
+		projectStub, err := r.Create(projectCreateStub)
+		require.NoError(t, err)
+
+		// execute
+		err = r.Delete(projectStub.ID, repo.Version(projectStub.Version+1))
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+		assert.True(t, r.Has(projectStub.ID))
+	})
+
 	t.Run("non-existing project", func(t *testing.T) {
 		// execute
-		err := r.Delete("non-existing-id")
+		err := r.Delete("non-existing-id", repo.AnyVersion)
 
 		// verify
 		assert.Error(t, err)
@@ -155,7 +184,7 @@ func TestInMemoryProjectRepo_List(t *testing.T) {
 
 	t.Run("empty repo", func(t *testing.T) {
 		// execute
-		projects, err := r.List()
+		projects, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -171,7 +200,7 @@ func TestInMemoryProjectRepo_List(t *testing.T) {
 		projectStub2, _ := r.Create(projectCreateStub2)
 
 		// execute
-		projects, err := r.List()
+		projects, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -189,10 +218,26 @@ func TestInMemoryProjectRepo_List(t *testing.T) {
 		}
 
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
 		assert.Equal(t, 100, len(lists))
 	})
+
+	t.Run("with a custom limit", func(t *testing.T) {
+		// prepare
+		r := repo.NewInMemoryProjectRepoWithLimit(3)
+		for range 5 {
+			_, err := r.Create(model.RandomProjectCreate())
+			require.NoError(t, err)
+		}
+
+		// execute
+		projects, err := r.List(repo.Query{})
+
+		// verify
+		assert.NoError(t, err)
+		assert.Len(t, projects, 3)
+	})
 }
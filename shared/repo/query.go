@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// Query filters, searches and sorts the items of a collection. Zero values are ignored.
+type Query struct {
+	// Filters map field names to the value the field must be equal to, e.g. "completed" to "true"
+	Filters map[string]string
+	// Search is matched case-insensitively as a substring of the text fields of the items
+	Search string
+	// Sort lists the field names to sort by, each prefixed with "-" for descending order.
+	// Items are sorted by ID, in other words by creation time, by default and to break ties.
+	Sort []string
+}
+
+// QueryError describes why a query is invalid. Param is the query parameter at fault, Rule is the rule it broke
+// and Params are the arguments of the rule, like the allowed field names.
+type QueryError struct {
+	Param  string
+	Rule   string
+	Params []string
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query parameter: %s, reason: %s", e.Param, e.Reason)
+}
+
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// field describes how a field of T can be used in queries
+type field[T any] struct {
+	// value returns the value of the field in the format used by filters
+	value func(T) string
+	// parse normalizes a filter value, so that it can be compared to value
+	parse func(string) (string, error)
+	// compare orders two items by the field
+	compare func(a, b T) int
+	// searchable fields are matched against the search text
+	searchable bool
+}
+
+func stringField[T any](get func(T) string, searchable bool) field[T] {
+	return field[T]{
+		value:      get,
+		parse:      func(s string) (string, error) { return s, nil },
+		compare:    func(a, b T) int { return strings.Compare(get(a), get(b)) },
+		searchable: searchable,
+	}
+}
+
+func boolField[T any](get func(T) bool) field[T] {
+	return field[T]{
+		value: func(item T) string { return strconv.FormatBool(get(item)) },
+		parse: func(s string) (string, error) {
+			b, err := strconv.ParseBool(s)
+
+			return strconv.FormatBool(b), err
+		},
+		compare: func(a, b T) int {
+			switch {
+			case get(a) == get(b):
+				return 0
+			case get(a):
+				return 1
+			default:
+				return -1
+			}
+		},
+	}
+}
+
+func intField[T any](get func(T) int) field[T] {
+	return field[T]{
+		value: func(item T) string { return strconv.Itoa(get(item)) },
+		parse: func(s string) (string, error) {
+			i, err := strconv.Atoi(s)
+
+			return strconv.Itoa(i), err
+		},
+		compare: func(a, b T) int { return cmp.Compare(get(a), get(b)) },
+	}
+}
+
+// fields maps the field names known by queries to their descriptions. Every entity must have an "id" field.
+type fields[T any] map[string]field[T]
+
+func (fs fields[T]) names() []string {
+	return slices.Sorted(maps.Keys(fs))
+}
+
+// validate returns a QueryError about the first problem of the query, and a query with normalized filter values
+func (fs fields[T]) validate(q Query) (Query, error) {
+	filters := make(map[string]string, len(q.Filters))
+	for name, value := range q.Filters {
+		f, ok := fs[name]
+		if !ok {
+			return Query{}, &QueryError{
+				Param:  name,
+				Rule:   "oneof",
+				Params: fs.names(),
+				Reason: fmt.Sprintf("unknown field, must be one of: %s", strings.Join(fs.names(), ", ")),
+			}
+		}
+
+		parsed, err := f.parse(value)
+		if err != nil {
+			return Query{}, &QueryError{
+				Param:  name,
+				Rule:   "type",
+				Reason: fmt.Sprintf("invalid value: %q", value),
+			}
+		}
+
+		filters[name] = parsed
+	}
+
+	for _, s := range q.Sort {
+		if _, ok := fs[strings.TrimPrefix(s, "-")]; !ok {
+			return Query{}, &QueryError{
+				Param:  "sort",
+				Rule:   "oneof",
+				Params: fs.names(),
+				Reason: fmt.Sprintf("unknown field: %q, must be one of: %s, optionally prefixed with -", s, strings.Join(fs.names(), ", ")),
+			}
+		}
+	}
+
+	q.Filters = filters
+
+	return q, nil
+}
+
+// matches reports whether the item passes the filters of the query and contains the folded search text
+func (fs fields[T]) matches(item T, q Query, folder cases.Caser, search string) bool {
+	for name, value := range q.Filters {
+		if fs[name].value(item) != value {
+			return false
+		}
+	}
+
+	if search == "" {
+		return true
+	}
+
+	for _, f := range fs {
+		if f.searchable && strings.Contains(folder.String(f.value(item)), search) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compare returns a comparison function for the sort order of the query, ties are broken by ID
+func (fs fields[T]) compare(sort []string) func(a, b T) int {
+	return func(a, b T) int {
+		for _, s := range sort {
+			name, descending := strings.CutPrefix(s, "-")
+
+			c := fs[name].compare(a, b)
+			if descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+
+		return fs["id"].compare(a, b)
+	}
+}
+
+// find returns at most limit items matching the query, in the order requested by it
+func find[T any](items map[string]T, fs fields[T], q Query, limit int) ([]T, error) {
+	q, err := fs.validate(q)
+	if err != nil {
+		return nil, err
+	}
+
+	// Casers are stateful and must not be shared between goroutines
+	folder := cases.Fold()
+	search := folder.String(q.Search)
+
+	matches := make([]T, 0)
+	for _, item := range items {
+		if fs.matches(item, q, folder, search) {
+			matches = append(matches, item)
+		}
+	}
+
+	slices.SortFunc(matches, fs.compare(q.Sort))
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
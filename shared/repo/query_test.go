@@ -0,0 +1,147 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	// prepare
+	r := repo.NewInMemoryTodoRepo()
+
+	todoCreates := []model.TodoCreate{
+		{ListID: "list-1", Title: "Buy milk", Description: "Bio, discounted", Completed: true},
+		{ListID: "list-1", Title: "Call mom", Description: "About the MILK delivery"},
+		{ListID: "list-2", Title: "Buy bread", Completed: true},
+		{ListID: "list-2", Title: "Årsmöte", Description: "Prepare the agenda"},
+	}
+	for _, tc := range todoCreates {
+		_, err := r.Create(tc)
+		require.NoError(t, err)
+	}
+
+	tests := map[string]struct {
+		query repo.Query
+		want  []string
+	}{
+		"empty query sorts by creation": {
+			query: repo.Query{},
+			want:  []string{"Buy milk", "Call mom", "Buy bread", "Årsmöte"},
+		},
+		"boolean filter": {
+			query: repo.Query{Filters: map[string]string{"completed": "true"}},
+			want:  []string{"Buy milk", "Buy bread"},
+		},
+		"boolean filter is normalized": {
+			query: repo.Query{Filters: map[string]string{"completed": "0"}},
+			want:  []string{"Call mom", "Årsmöte"},
+		},
+		"multiple filters": {
+			query: repo.Query{Filters: map[string]string{"listId": "list-2", "completed": "false"}},
+			want:  []string{"Årsmöte"},
+		},
+		"search matches title and description case-insensitively": {
+			query: repo.Query{Search: "milk"},
+			want:  []string{"Buy milk", "Call mom"},
+		},
+		"search folds unicode": {
+			query: repo.Query{Search: "ÅRSMÖ"},
+			want:  []string{"Årsmöte"},
+		},
+		"search and filter": {
+			query: repo.Query{Search: "buy", Filters: map[string]string{"listId": "list-1"}},
+			want:  []string{"Buy milk"},
+		},
+		"descending sort": {
+			query: repo.Query{Sort: []string{"-title"}},
+			want:  []string{"Årsmöte", "Call mom", "Buy milk", "Buy bread"},
+		},
+		"multiple sort fields": {
+			query: repo.Query{Sort: []string{"-completed", "title"}},
+			want:  []string{"Buy bread", "Buy milk", "Call mom", "Årsmöte"},
+		},
+		"ties are broken by id": {
+			query: repo.Query{Sort: []string{"listId"}},
+			want:  []string{"Buy milk", "Call mom", "Buy bread", "Årsmöte"},
+		},
+		"no match": {
+			query: repo.Query{Search: "nothing like this"},
+			want:  []string{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			todos, err := r.List(tt.query)
+
+			// verify
+			require.NoError(t, err)
+			titles := make([]string, 0, len(todos))
+			for _, todo := range todos {
+				titles = append(titles, todo.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+}
+
+func TestQuery_Invalid(t *testing.T) {
+	r := repo.NewInMemoryProjectRepo()
+
+	tests := map[string]struct {
+		query     repo.Query
+		wantParam string
+		wantRule  string
+	}{
+		"unknown filter field": {
+			query:     repo.Query{Filters: map[string]string{"colour": "red"}},
+			wantParam: "colour",
+			wantRule:  "oneof",
+		},
+		"unknown sort field": {
+			query:     repo.Query{Sort: []string{"name", "-colour"}},
+			wantParam: "sort",
+			wantRule:  "oneof",
+		},
+		"empty sort field": {
+			query:     repo.Query{Sort: []string{""}},
+			wantParam: "sort",
+			wantRule:  "oneof",
+		},
+		"malformed value": {
+			query:     repo.Query{Filters: map[string]string{"version": "latest"}},
+			wantParam: "version",
+			wantRule:  "type",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			_, err := r.List(tt.query)
+
+			// verify
+			require.ErrorIs(t, err, repo.ErrInvalidQuery)
+
+			var queryErr *repo.QueryError
+			require.ErrorAs(t, err, &queryErr)
+			assert.Equal(t, tt.wantParam, queryErr.Param)
+			assert.Equal(t, tt.wantRule, queryErr.Rule)
+		})
+	}
+
+	t.Run("allowed fields are listed", func(t *testing.T) {
+		// execute
+		_, err := r.List(repo.Query{Filters: map[string]string{"colour": "red"}})
+
+		// verify
+		var queryErr *repo.QueryError
+		require.ErrorAs(t, err, &queryErr)
+		assert.Equal(t, []string{"description", "id", "name", "version"}, queryErr.Params)
+	})
+}
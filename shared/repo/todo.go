@@ -1,38 +1,42 @@
 package repo
 
 import (
-	"errors"
 	"fmt"
-	"sort"
 	"sync"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/peteraba/go-frameworks/shared/model"
 )
 
-const maxTodoListLength = 1000
+// DefaultTodoListLimit is the maximum number of todo items returned by a single list request by default
+const DefaultTodoListLimit = 1000
 
 type TodoRepo interface {
 	Create(todo model.TodoCreate) (model.Todo, error)
 	GetByID(id string) (model.Todo, error)
-	Update(id string, update model.TodoUpdate) (model.Todo, error)
-	Delete(id string) error
-	List() ([]model.Todo, error)
+	Update(id string, precondition Precondition, update model.TodoUpdate) (model.Todo, error)
+	Delete(id string, precondition Precondition) error
+	List(query Query) ([]model.Todo, error)
 }
 
 // ErrTodoNotFound
-var ErrTodoNotFound = errors.New("todo item not found")
+var ErrTodoNotFound = fmt.Errorf("todo item %w", ErrNotFound)
 
 type InMemoryTodoRepo struct {
 	mu    sync.RWMutex
 	todos map[string]model.Todo
-	ids   []string
-	dirty bool
+	limit int
 }
 
 func NewInMemoryTodoRepo() *InMemoryTodoRepo {
+	return NewInMemoryTodoRepoWithLimit(DefaultTodoListLimit)
+}
+
+// NewInMemoryTodoRepoWithLimit returns a repo returning at most limit todo items per list request
+func NewInMemoryTodoRepoWithLimit(limit int) *InMemoryTodoRepo {
 	return &InMemoryTodoRepo{
 		todos: make(map[string]model.Todo),
+		limit: limit,
 	}
 }
 
@@ -46,10 +50,10 @@ func (r *InMemoryTodoRepo) Create(todo model.TodoCreate) (model.Todo, error) {
 		Title:       todo.Title,
 		Description: todo.Description,
 		Completed:   todo.Completed,
+		Version:     1,
 	}
 
 	r.todos[t.ID] = t
-	r.ids = append(r.ids, t.ID)
 
 	return t, nil
 }
@@ -75,7 +79,8 @@ func (r *InMemoryTodoRepo) GetByID(id string) (model.Todo, error) {
 	return todo, nil
 }
 
-func (r *InMemoryTodoRepo) Update(id string, update model.TodoUpdate) (model.Todo, error) {
+// Update replaces the todo if the precondition holds for its current version, and increments the version
+func (r *InMemoryTodoRepo) Update(id string, precondition Precondition, update model.TodoUpdate) (model.Todo, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -84,63 +89,53 @@ func (r *InMemoryTodoRepo) Update(id string, update model.TodoUpdate) (model.Tod
 		return model.Todo{}, fmt.Errorf("not found: %s, err: %w", id, ErrTodoNotFound)
 	}
 
-	if update.Title != "" {
-		todo.Title = update.Title
-	}
-	if update.Description != "" {
-		todo.Description = update.Description
-	}
-	if update.Completed != nil {
-		todo.Completed = *update.Completed
+	if err := precondition.Check(id, todo.Version); err != nil {
+		return model.Todo{}, err
 	}
 
+	todo.Title = update.Title
+	todo.Description = update.Description
+	todo.Completed = update.Completed
+	todo.Version++
+
 	r.todos[id] = todo
 
 	return todo, nil
 }
 
-func (r *InMemoryTodoRepo) Delete(id string) error {
+// Delete removes the todo if the precondition holds for its current version
+func (r *InMemoryTodoRepo) Delete(id string, precondition Precondition) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.todos[id]; !exists {
+	todo, exists := r.todos[id]
+	if !exists {
 		return fmt.Errorf("not found: %s, err: %w", id, ErrTodoNotFound)
 	}
 
+	if err := precondition.Check(id, todo.Version); err != nil {
+		return err
+	}
+
 	delete(r.todos, id)
 
 	return nil
 }
 
-func (r *InMemoryTodoRepo) List() ([]model.Todo, error) {
+// todoFields are the fields of a todo item which can be used in queries, title and description are searched
+var todoFields = fields[model.Todo]{
+	"id":          stringField(func(t model.Todo) string { return t.ID }, false),
+	"listId":      stringField(func(t model.Todo) string { return t.ListID }, false),
+	"title":       stringField(func(t model.Todo) string { return t.Title }, true),
+	"description": stringField(func(t model.Todo) string { return t.Description }, true),
+	"completed":   boolField(func(t model.Todo) bool { return t.Completed }),
+	"version":     intField(func(t model.Todo) int { return t.Version }),
+}
+
+// List returns the todo items matching the query, a QueryError is returned if the query is invalid
+func (r *InMemoryTodoRepo) List(query Query) ([]model.Todo, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.dirty {
-		keys := make([]string, 0, len(r.todos))
-		for k := range r.todos {
-			keys = append(keys, k)
-		}
-
-		sort.Strings(keys)
-
-		r.dirty = false
-		r.ids = keys
-	}
-
-	l := len(r.todos)
-	if l > maxTodoListLength {
-		l = maxTodoListLength
-	}
-
-	todos := make([]model.Todo, 0, l)
-	for i, key := range r.ids {
-		if i >= l {
-			break
-		}
-
-		todos = append(todos, r.todos[key])
-	}
-
-	return todos, nil
+	return find(r.todos, todoFields, query, r.limit)
 }
@@ -54,6 +54,7 @@ func TestInMemoryTodoRepo_GetByID(t *testing.T) {
 		// verify
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, repo.ErrTodoNotFound)
+		assert.ErrorIs(t, err, repo.ErrNotFound)
 	})
 }
 
@@ -69,11 +70,11 @@ func TestInMemoryTodoRepo_Update(t *testing.T) {
 		todoUpdateStub := model.TodoUpdate{
 			Title:       "Updated Title",
 			Description: "Updated Description",
-			Completed:   func() *bool { b := true; return &b }(),
+			Completed:   true,
 		}
 
 		// execute
-		updated, err := r.Update(todoStub.ID, todoUpdateStub)
+		updated, err := r.Update(todoStub.ID, repo.AnyVersion, todoUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -82,7 +83,7 @@ func TestInMemoryTodoRepo_Update(t *testing.T) {
 		assert.True(t, updated.Completed)
 	})
 
-	t.Run("partial update", func(t *testing.T) {
+	t.Run("omitted fields are cleared", func(t *testing.T) {
 		// prepare
 		todoCreateStub := model.RandomTodoCreate()
 		todoCreateStub.ListID = "list-1"
@@ -93,42 +94,55 @@ func TestInMemoryTodoRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		updated, err := r.Update(todoStub.ID, todoUpdateStub)
+		updated, err := r.Update(todoStub.ID, repo.AnyVersion, todoUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
 		assert.Equal(t, "Only Title Updated", updated.Title)
-		assert.Equal(t, todoStub.Description, updated.Description)
+		assert.Empty(t, updated.Description)
+		assert.False(t, updated.Completed)
 	})
 
-	t.Run("non-existing todo", func(t *testing.T) {
+	t.Run("version is incremented", func(t *testing.T) {
 		// prepare
-		todoUpdateStub := model.TodoUpdate{Title: "Updated Title"}
+		todoCreateStub := model.RandomTodoCreate()
+		todoStub, err := r.Create(todoCreateStub)
+		require.NoError(t, err)
 
 		// execute
-		_, err := r.Update("non-existing-id", todoUpdateStub)
+		updated, err := r.Update(todoStub.ID, repo.Version(todoStub.Version), model.TodoUpdate{Title: "Updated Title"})
 
 		// verify
-		assert.Error(t, err)
-		assert.ErrorIs(t, err, repo.ErrTodoNotFound)
+		require.NoError(t, err)
+		assert.Equal(t, 1, todoStub.Version)
+		assert.Equal(t, 2, updated.Version)
 	})
 
-	t.Run("empty update fields are ignored", func(t *testing.T) {
+	t.Run("stale version", func(t *testing.T) {
 		// prepare
 		todoCreateStub := model.RandomTodoCreate()
-		todoCreateStub.ListID = "list-1"
 		todoStub, err := r.Create(todoCreateStub)
 		require.NoError(t, err)
-		todoUpdateStub := model.TodoUpdate{}
+		_, err = r.Update(todoStub.ID, repo.Version(todoStub.Version), model.TodoUpdate{Title: "Updated Title"})
+		require.NoError(t, err)
 
 		// execute
-		updated, err := r.Update(todoStub.ID, todoUpdateStub)
+		_, err = r.Update(todoStub.ID, repo.Version(todoStub.Version), model.TodoUpdate{Title: "Updated Title"})
 
 		// verify
-		assert.NoError(t, err)
-		assert.Equal(t, todoStub.Title, updated.Title)
-		assert.Equal(t, todoStub.Description, updated.Description)
-		assert.Equal(t, todoStub.Completed, updated.Completed)
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("non-existing todo", func(t *testing.T) {
+		// prepare
+		todoUpdateStub := model.TodoUpdate{Title: "Updated Title"}
+
+		// execute
+		_, err := r.Update("non-existing-id", repo.AnyVersion, todoUpdateStub)
+
+		// verify
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, repo.ErrTodoNotFound)
 	})
 }
 
@@ -143,15 +157,29 @@ func TestInMemoryTodoRepo_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// execute
-		err = r.Delete(todoStub.ID)
+		err = r.Delete(todoStub.ID, repo.AnyVersion)
 
 		// verify
 		assert.NoError(t, err)
 	})
 
+	t.Run("stale version", func(t *testing.T) {
+		// prepare
+		todoCreateStub := model.RandomTodoCreate()
+		todoStub, err := r.Create(todoCreateStub)
+		require.NoError(t, err)
+
+		// execute
+		err = r.Delete(todoStub.ID, repo.Version(todoStub.Version+1))
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+		assert.True(t, r.Has(todoStub.ID))
+	})
+
 	t.Run("non-existing todo", func(t *testing.T) {
 		// execute
-		err := r.Delete("non-existing-id")
+		err := r.Delete("non-existing-id", repo.AnyVersion)
 
 		// verify
 		assert.Error(t, err)
@@ -164,7 +192,7 @@ func TestInMemoryTodoRepo_List(t *testing.T) {
 
 	t.Run("empty repo", func(t *testing.T) {
 		// execute
-		todos, err := r.List()
+		todos, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -182,7 +210,7 @@ func TestInMemoryTodoRepo_List(t *testing.T) {
 		todoStub2, _ := r.Create(todoCreateStub2)
 
 		// execute
-		todos, err := r.List()
+		todos, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
@@ -200,7 +228,7 @@ func TestInMemoryTodoRepo_List(t *testing.T) {
 		}
 
 		// execute
-		lists, err := r.List()
+		lists, err := r.List(repo.Query{})
 
 		// verify
 		assert.NoError(t, err)
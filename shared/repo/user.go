@@ -1,41 +1,71 @@
 package repo
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/peteraba/go-frameworks/shared/model"
+	"golang.org/x/text/cases"
 )
 
-const maxUserListLength = 100
+// DefaultUserListLimit is the maximum number of users returned by a single list request by default
+const DefaultUserListLimit = 100
 
 type UserRepo interface {
 	Create(user model.UserCreate, passwordHash, passwordSalt []byte) (model.User, error)
 	GetByID(id string) (model.User, error)
 	GetByEmail(email string) (model.User, error)
-	Update(id string, update model.UserUpdate) (model.User, error)
+	Update(id string, precondition Precondition, update model.UserUpdate) (model.User, error)
+	UpdatePassword(id string, precondition Precondition, passwordHash, passwordSalt []byte) (model.User, error)
 	Delete(id string) error
 	List() ([]model.User, error)
+	Search(search model.UserSearch) (model.UserPage, error)
+	HasGroupMember(group string) (bool, error)
+	UpdateStatus(id string, precondition Precondition, status model.UserStatus, deletionScheduledAt *time.Time) (model.User, error)
+	ListScheduledForDeletion(before time.Time) ([]model.User, error)
+	Anonymize(id string) (model.User, error)
 }
 
 // ErrUserNotFound
-var ErrUserNotFound = errors.New("user not found")
+var ErrUserNotFound = fmt.Errorf("user %w", ErrNotFound)
+
+// ErrEmailTaken
+var ErrEmailTaken = fmt.Errorf("%w: email already taken", ErrConflict)
+
+// ErrInvalidStatusTransition
+var ErrInvalidStatusTransition = fmt.Errorf("%w: invalid user status transition", ErrConflict)
 
 type InMemoryUserRepo struct {
-	mu     sync.RWMutex
-	users  map[string]model.User
-	ids    []string
+	mu    sync.RWMutex
+	users map[string]model.User
+	ids   []string
+	// emails is a unique index of normalized emails to user IDs, it must be updated together with users
 	emails map[string]string
 	dirty  bool
+	limit  int
 }
 
 func NewInMemoryUserRepo() *InMemoryUserRepo {
+	return NewInMemoryUserRepoWithLimit(DefaultUserListLimit)
+}
+
+// NewInMemoryUserRepoWithLimit returns a repo returning at most limit users per list request
+func NewInMemoryUserRepoWithLimit(limit int) *InMemoryUserRepo {
 	return &InMemoryUserRepo{
 		users:  make(map[string]model.User),
 		emails: make(map[string]string),
+		limit:  limit,
 	}
 }
 
@@ -43,20 +73,26 @@ func (r *InMemoryUserRepo) Create(uc model.UserCreate, passwordHash, passwordSal
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	email := strings.TrimSpace(uc.Email)
+	key := model.NormalizeEmail(email)
+	if _, exists := r.emails[key]; exists {
+		return model.User{}, fmt.Errorf("email: %s, err: %w", email, ErrEmailTaken)
+	}
+
 	u := model.User{
 		ID:           ulid.Make().String(),
 		Name:         uc.Name,
-		Email:        uc.Email,
+		Email:        email,
 		Groups:       uc.Groups,
+		Status:       model.UserStatusActive,
+		Version:      1,
 		PasswordHash: passwordHash,
 		PasswordSalt: passwordSalt,
 	}
 
-	u.ID = ulid.Make().String()
-
 	r.users[u.ID] = u
 	r.ids = append(r.ids, u.ID)
-	r.emails[u.Email] = u.ID
+	r.emails[key] = u.ID
 	r.dirty = true
 
 	return u, nil
@@ -75,15 +111,19 @@ func (r *InMemoryUserRepo) GetByID(id string) (model.User, error) {
 }
 
 func (r *InMemoryUserRepo) GetByEmail(email string) (model.User, error) {
-	id, exists := r.emails[email]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.emails[model.NormalizeEmail(email)]
 	if !exists {
 		return model.User{}, fmt.Errorf("email not found: %s, err: %w", email, ErrUserNotFound)
 	}
 
-	return r.GetByID(id)
+	return r.users[id], nil
 }
 
-func (r *InMemoryUserRepo) Update(id string, update model.UserUpdate) (model.User, error) {
+// Update changes the non-empty fields of the user if the precondition holds for its current version
+func (r *InMemoryUserRepo) Update(id string, precondition Precondition, update model.UserUpdate) (model.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -92,19 +132,34 @@ func (r *InMemoryUserRepo) Update(id string, update model.UserUpdate) (model.Use
 		return model.User{}, fmt.Errorf("not found: %s, err: %w", id, ErrUserNotFound)
 	}
 
+	if err := precondition.Check(id, user.Version); err != nil {
+		return model.User{}, err
+	}
+
 	if update.Name != "" {
 		user.Name = update.Name
 	}
-	if update.Email != "" {
-		user.Email = update.Email
+	if email := strings.TrimSpace(update.Email); email != "" {
+		oldKey, newKey := model.NormalizeEmail(user.Email), model.NormalizeEmail(email)
+		if ownerID, exists := r.emails[newKey]; exists && ownerID != id {
+			return model.User{}, fmt.Errorf("email: %s, err: %w", email, ErrEmailTaken)
+		}
+
+		delete(r.emails, oldKey)
+		r.emails[newKey] = id
+		user.Email = email
+	}
+	if update.Groups != nil {
+		user.Groups = update.Groups
 	}
+	user.Version++
 
 	r.users[id] = user
 
 	return user, nil
 }
 
-func (r *InMemoryUserRepo) UpdatePassword(id string, passwordHash []byte) (model.User, error) {
+func (r *InMemoryUserRepo) UpdatePassword(id string, precondition Precondition, passwordHash, passwordSalt []byte) (model.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -113,7 +168,13 @@ func (r *InMemoryUserRepo) UpdatePassword(id string, passwordHash []byte) (model
 		return model.User{}, fmt.Errorf("not found: %s, err: %w", id, ErrUserNotFound)
 	}
 
+	if err := precondition.Check(id, user.Version); err != nil {
+		return model.User{}, err
+	}
+
 	user.PasswordHash = passwordHash
+	user.PasswordSalt = passwordSalt
+	user.Version++
 
 	r.users[id] = user
 
@@ -124,11 +185,14 @@ func (r *InMemoryUserRepo) Delete(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.users[id]; !exists {
+	user, exists := r.users[id]
+	if !exists {
 		return fmt.Errorf("not found: %s, err: %w", id, ErrUserNotFound)
 	}
 
+	r.removeEmail(user)
 	delete(r.users, id)
+	r.dirty = true
 
 	return nil
 }
@@ -150,8 +214,8 @@ func (r *InMemoryUserRepo) List() ([]model.User, error) {
 	}
 
 	l := len(r.users)
-	if l > maxUserListLength {
-		l = maxUserListLength
+	if l > r.limit {
+		l = r.limit
 	}
 
 	users := make([]model.User, 0, l)
@@ -166,6 +230,74 @@ func (r *InMemoryUserRepo) List() ([]model.User, error) {
 	return users, nil
 }
 
+// Search returns a page of the users matching the search, with the total number of matching users
+func (r *InMemoryUserRepo) Search(search model.UserSearch) (model.UserPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Casers are stateful and must not be shared between goroutines
+	nameFolder := cases.Fold()
+	name := nameFolder.String(search.Name)
+	email := model.NormalizeEmail(search.Email)
+
+	matches := make([]model.User, 0)
+	for _, user := range r.users {
+		if name != "" && !strings.HasPrefix(nameFolder.String(user.Name), name) {
+			continue
+		}
+		if email != "" && !strings.HasPrefix(model.NormalizeEmail(user.Email), email) {
+			continue
+		}
+		if search.Group != "" && !slices.Contains(user.Groups, search.Group) {
+			continue
+		}
+		if search.Status != "" && user.Status != search.Status {
+			continue
+		}
+
+		matches = append(matches, user)
+	}
+
+	slices.SortFunc(matches, compareUsers(search.Sort, nameFolder))
+
+	total := len(matches)
+
+	limit := search.Limit
+	if limit <= 0 || limit > r.limit {
+		limit = r.limit
+	}
+
+	start := min(search.Offset, total)
+	end := min(start+limit, total)
+
+	return model.UserPage{Users: matches[start:end], Total: total, Offset: search.Offset, Limit: limit}, nil
+}
+
+// compareUsers returns a comparison function for the given sort order, ties are broken by ID
+func compareUsers(sort string, nameFolder cases.Caser) func(a, b model.User) int {
+	field, descending := strings.CutPrefix(sort, "-")
+
+	return func(a, b model.User) int {
+		var c int
+		switch field {
+		case "name":
+			c = strings.Compare(nameFolder.String(a.Name), nameFolder.String(b.Name))
+		case "email":
+			c = strings.Compare(model.NormalizeEmail(a.Email), model.NormalizeEmail(b.Email))
+		case "status":
+			c = strings.Compare(string(a.Status), string(b.Status))
+		}
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if descending {
+			c = -c
+		}
+
+		return c
+	}
+}
+
 func (r *InMemoryUserRepo) Has(id string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -174,3 +306,360 @@ func (r *InMemoryUserRepo) Has(id string) bool {
 
 	return exists
 }
+
+// HasGroupMember reports whether at least one active user belongs to the given group. Suspended users and users
+// pending deletion do not count, as they can not log in.
+func (r *InMemoryUserRepo) HasGroupMember(group string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Status == model.UserStatusActive && slices.Contains(user.Groups, group) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// UpdateStatus moves the user to a new lifecycle state if the precondition holds for its current version.
+// Leaving the active state invalidates all sessions.
+func (r *InMemoryUserRepo) UpdateStatus(id string, precondition Precondition, status model.UserStatus, deletionScheduledAt *time.Time) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return model.User{}, fmt.Errorf("not found: %s, err: %w", id, ErrUserNotFound)
+	}
+
+	if err := precondition.Check(id, user.Version); err != nil {
+		return model.User{}, err
+	}
+
+	if !user.Status.CanTransitionTo(status) {
+		return model.User{}, fmt.Errorf("%s -> %s, err: %w", user.Status, status, ErrInvalidStatusTransition)
+	}
+
+	user.Status = status
+	user.DeletionScheduledAt = deletionScheduledAt
+	user.Version++
+	if status != model.UserStatusActive {
+		user.SessionEpoch++
+	}
+
+	r.users[id] = user
+
+	return user, nil
+}
+
+// ListScheduledForDeletion returns the users pending deletion whose grace period ends before the given time
+func (r *InMemoryUserRepo) ListScheduledForDeletion(before time.Time) ([]model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]model.User, 0)
+	for _, user := range r.users {
+		if user.Status != model.UserStatusPendingDeletion || user.DeletionScheduledAt == nil {
+			continue
+		}
+
+		if user.DeletionScheduledAt.Before(before) {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// Anonymize irreversibly removes the personal data of a user pending deletion and marks them deleted.
+// The record itself is kept so that references to the user ID, like the ones in the audit log, remain valid.
+func (r *InMemoryUserRepo) Anonymize(id string) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return model.User{}, fmt.Errorf("not found: %s, err: %w", id, ErrUserNotFound)
+	}
+
+	if !user.Status.CanTransitionTo(model.UserStatusDeleted) {
+		return model.User{}, fmt.Errorf("%s -> %s, err: %w", user.Status, model.UserStatusDeleted, ErrInvalidStatusTransition)
+	}
+
+	r.removeEmail(user)
+
+	user = model.User{
+		ID:           user.ID,
+		Name:         "Deleted user",
+		Email:        strings.ToLower(user.ID) + "@deleted.invalid",
+		Groups:       []string{},
+		Status:       model.UserStatusDeleted,
+		Version:      user.Version + 1,
+		SessionEpoch: user.SessionEpoch + 1,
+	}
+
+	r.users[id] = user
+
+	return user, nil
+}
+
+// restore adds a stored user as it is, indexing its email unless it was anonymized
+func (r *InMemoryUserRepo) restore(user model.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[user.ID] = user
+	if user.Status != model.UserStatusDeleted {
+		r.emails[model.NormalizeEmail(user.Email)] = user.ID
+	}
+	r.dirty = true
+}
+
+// all returns every user sorted by ID, regardless of the list limit
+func (r *InMemoryUserRepo) all() []model.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := slices.Collect(maps.Values(r.users))
+	slices.SortFunc(users, func(a, b model.User) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return users
+}
+
+// removeEmail removes the email of the user from the email index, the caller must hold the write lock
+func (r *InMemoryUserRepo) removeEmail(user model.User) {
+	key := model.NormalizeEmail(user.Email)
+	if r.emails[key] == user.ID {
+		delete(r.emails, key)
+	}
+}
+
+// userRecord is the stored form of a user, including the fields never sent to clients
+type userRecord struct {
+	model.User
+	SessionEpoch int    `json:"sessionEpoch"`
+	PasswordHash []byte `json:"passwordHash"`
+	PasswordSalt []byte `json:"passwordSalt"`
+}
+
+// JSONFileUserRepo keeps the users in memory, and stores them in a JSON file after every change. The file is read
+// again whenever another process changed it, so that the server sees the administrators created or restored by
+// the create-admin command without a restart. Writes replace the file atomically, concurrent writes of different
+// processes are not coordinated beyond that.
+type JSONFileUserRepo struct {
+	mu    sync.Mutex
+	path  string
+	limit int
+	users *InMemoryUserRepo
+	// modTime and size identify the version of the file loaded or written last
+	modTime time.Time
+	size    int64
+}
+
+// NewJSONFileUserRepo returns a repo stored in the file at path, returning at most limit users per list request.
+// The file is created on the first change if it does not exist.
+func NewJSONFileUserRepo(path string, limit int) (*JSONFileUserRepo, error) {
+	r := &JSONFileUserRepo{path: path, limit: limit, users: NewInMemoryUserRepoWithLimit(limit), size: -1}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// reload reads the file again if it changed since it was loaded or written last, the caller must hold the lock
+func (r *JSONFileUserRepo) reload() error {
+	info, err := os.Stat(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Nothing was saved yet, so the users kept in memory, if any, are the ones of failed changes
+		r.users, r.modTime, r.size = NewInMemoryUserRepoWithLimit(r.limit), time.Time{}, -1
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat user file: %s, err: %w", r.path, err)
+	}
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return nil
+	}
+
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read user file: %s, err: %w", r.path, err)
+	}
+
+	var records []userRecord
+	if err := json.Unmarshal(content, &records); err != nil {
+		return fmt.Errorf("failed to decode user file: %s, err: %w", r.path, err)
+	}
+
+	users := NewInMemoryUserRepoWithLimit(r.limit)
+	for _, record := range records {
+		user := record.User
+		user.SessionEpoch, user.PasswordHash, user.PasswordSalt = record.SessionEpoch, record.PasswordHash, record.PasswordSalt
+		users.restore(user)
+	}
+
+	r.users, r.modTime, r.size = users, info.ModTime(), info.Size()
+
+	return nil
+}
+
+// save writes every user to a temporary file replacing the file, the caller must hold the lock. If it fails, the
+// file is read again by the next call, discarding the change which was not saved.
+func (r *JSONFileUserRepo) save() error {
+	users := r.users.all()
+	records := make([]userRecord, 0, len(users))
+	for _, user := range users {
+		records = append(records, userRecord{User: user, SessionEpoch: user.SessionEpoch, PasswordHash: user.PasswordHash, PasswordSalt: user.PasswordSalt})
+	}
+
+	if err := r.write(records); err != nil {
+		r.modTime, r.size = time.Time{}, -1
+		return err
+	}
+
+	return nil
+}
+
+func (r *JSONFileUserRepo) write(records []userRecord) error {
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode users, err: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create user file: %s, err: %w", r.path, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write user file: %s, err: %w", r.path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write user file: %s, err: %w", r.path, err)
+	}
+	if err := os.Rename(file.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to replace user file: %s, err: %w", r.path, err)
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat user file: %s, err: %w", r.path, err)
+	}
+	r.modTime, r.size = info.ModTime(), info.Size()
+
+	return nil
+}
+
+// readUsers calls read with the users as currently stored
+func readUsers[T any](r *JSONFileUserRepo, read func(users *InMemoryUserRepo) (T, error)) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return read(r.users)
+}
+
+// writeUsers calls write with the users as currently stored, and stores them if write succeeds
+func writeUsers[T any](r *JSONFileUserRepo, write func(users *InMemoryUserRepo) (T, error)) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var zero T
+	if err := r.reload(); err != nil {
+		return zero, err
+	}
+
+	v, err := write(r.users)
+	if err != nil {
+		return zero, err
+	}
+	if err := r.save(); err != nil {
+		return zero, err
+	}
+
+	return v, nil
+}
+
+func (r *JSONFileUserRepo) Create(uc model.UserCreate, passwordHash, passwordSalt []byte) (model.User, error) {
+	return writeUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.Create(uc, passwordHash, passwordSalt)
+	})
+}
+
+func (r *JSONFileUserRepo) GetByID(id string) (model.User, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.GetByID(id)
+	})
+}
+
+func (r *JSONFileUserRepo) GetByEmail(email string) (model.User, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.GetByEmail(email)
+	})
+}
+
+func (r *JSONFileUserRepo) Update(id string, precondition Precondition, update model.UserUpdate) (model.User, error) {
+	return writeUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.Update(id, precondition, update)
+	})
+}
+
+func (r *JSONFileUserRepo) UpdatePassword(id string, precondition Precondition, passwordHash, passwordSalt []byte) (model.User, error) {
+	return writeUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.UpdatePassword(id, precondition, passwordHash, passwordSalt)
+	})
+}
+
+func (r *JSONFileUserRepo) Delete(id string) error {
+	_, err := writeUsers(r, func(users *InMemoryUserRepo) (struct{}, error) {
+		return struct{}{}, users.Delete(id)
+	})
+
+	return err
+}
+
+func (r *JSONFileUserRepo) List() ([]model.User, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) ([]model.User, error) {
+		return users.List()
+	})
+}
+
+func (r *JSONFileUserRepo) Search(search model.UserSearch) (model.UserPage, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) (model.UserPage, error) {
+		return users.Search(search)
+	})
+}
+
+func (r *JSONFileUserRepo) HasGroupMember(group string) (bool, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) (bool, error) {
+		return users.HasGroupMember(group)
+	})
+}
+
+func (r *JSONFileUserRepo) UpdateStatus(id string, precondition Precondition, status model.UserStatus, deletionScheduledAt *time.Time) (model.User, error) {
+	return writeUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.UpdateStatus(id, precondition, status, deletionScheduledAt)
+	})
+}
+
+func (r *JSONFileUserRepo) ListScheduledForDeletion(before time.Time) ([]model.User, error) {
+	return readUsers(r, func(users *InMemoryUserRepo) ([]model.User, error) {
+		return users.ListScheduledForDeletion(before)
+	})
+}
+
+func (r *JSONFileUserRepo) Anonymize(id string) (model.User, error) {
+	return writeUsers(r, func(users *InMemoryUserRepo) (model.User, error) {
+		return users.Anonymize(id)
+	})
+}
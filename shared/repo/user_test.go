@@ -1,7 +1,12 @@
 package repo_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/peteraba/go-frameworks/shared/model"
 	"github.com/peteraba/go-frameworks/shared/repo"
@@ -29,6 +34,64 @@ func TestInMemoryUserRepo_Create(t *testing.T) {
 	})
 }
 
+func TestInMemoryUserRepo_Create_UniqueEmail(t *testing.T) {
+	r := repo.NewInMemoryUserRepo()
+
+	t.Run("duplicate email is rejected regardless of case", func(t *testing.T) {
+		// prepare
+		userCreateStub := model.RandomUserCreate()
+		userCreateStub.Email = "duplicate@example.com"
+		_, err := r.Create(userCreateStub, []byte{}, []byte{})
+		require.NoError(t, err)
+
+		userCreateStub2 := model.RandomUserCreate()
+		userCreateStub2.Email = "  Duplicate@EXAMPLE.com"
+
+		// execute
+		_, err = r.Create(userCreateStub2, []byte{}, []byte{})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrEmailTaken)
+		assert.ErrorIs(t, err, repo.ErrConflict)
+		users, err := r.List()
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("concurrent creation with the same email", func(t *testing.T) {
+		// prepare
+		const workers = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+
+		// execute
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				userCreateStub := model.RandomUserCreate()
+				userCreateStub.Email = "race@example.com"
+				_, err := r.Create(userCreateStub, []byte{}, []byte{})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// verify
+		var succeeded int
+		for err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, repo.ErrEmailTaken)
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+}
+
 func TestInMemoryUserRepo_GetByID(t *testing.T) {
 	r := repo.NewInMemoryUserRepo()
 
@@ -56,6 +119,7 @@ func TestInMemoryUserRepo_GetByID(t *testing.T) {
 		// verify
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+		assert.ErrorIs(t, err, repo.ErrNotFound)
 	})
 }
 
@@ -88,7 +152,7 @@ func TestInMemoryUserRepo_GetByEmail(t *testing.T) {
 		assert.ErrorIs(t, err, repo.ErrUserNotFound)
 	})
 
-	t.Run("case sensitive email matching", func(t *testing.T) {
+	t.Run("case insensitive email matching", func(t *testing.T) {
 		// prepare
 		userCreateStub := model.RandomUserCreate()
 		userCreateStub.Email = "TestUser@Example.com"
@@ -98,19 +162,13 @@ func TestInMemoryUserRepo_GetByEmail(t *testing.T) {
 		userStub, err := r.Create(userCreateStub, passwordHashStub, passwordSaltStub)
 		require.NoError(t, err)
 
-		// execute - should not find with different case
-		_, err = r.GetByEmail("testuser@example.com")
-
-		// verify
-		assert.Error(t, err)
-		assert.ErrorIs(t, err, repo.ErrUserNotFound)
-
-		// execute - should find with exact case
-		retrieved, err := r.GetByEmail("TestUser@Example.com")
+		// execute - should find with different case and surrounding whitespace
+		retrieved, err := r.GetByEmail(" testuser@example.COM ")
 
 		// verify
 		assert.NoError(t, err)
 		assert.Equal(t, userStub, retrieved)
+		assert.Equal(t, "TestUser@Example.com", retrieved.Email)
 	})
 
 	t.Run("multiple users with different emails", func(t *testing.T) {
@@ -155,7 +213,7 @@ func TestInMemoryUserRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		updated, err := r.Update(userStub.ID, userUpdateStub)
+		updated, err := r.Update(userStub.ID, repo.AnyVersion, userUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -177,7 +235,7 @@ func TestInMemoryUserRepo_Update(t *testing.T) {
 		}
 
 		// execute
-		updated, err := r.Update(userStub.ID, userUpdateStub)
+		updated, err := r.Update(userStub.ID, repo.AnyVersion, userUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -190,13 +248,67 @@ func TestInMemoryUserRepo_Update(t *testing.T) {
 		userUpdateStub := model.RandomUserUpdate()
 
 		// execute
-		_, err := r.Update("non-existing-id", userUpdateStub)
+		_, err := r.Update("non-existing-id", repo.AnyVersion, userUpdateStub)
 
 		// verify
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, repo.ErrUserNotFound)
 	})
 
+	t.Run("email change updates the email index", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		updated, err := r.Update(userStub.ID, repo.AnyVersion, model.UserUpdate{Email: "Changed@Example.com"})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, "Changed@Example.com", updated.Email)
+		_, err = r.GetByEmail(userStub.Email)
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+		retrieved, err := r.GetByEmail("changed@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, userStub.ID, retrieved.ID)
+	})
+
+	t.Run("email change to a taken email is rejected", func(t *testing.T) {
+		// prepare
+		userStub1, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+		userStub2, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.Update(userStub2.ID, repo.AnyVersion, model.UserUpdate{Name: "Changed", Email: strings.ToUpper(userStub1.Email)})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrEmailTaken)
+		unchanged, err := r.GetByID(userStub2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, userStub2, unchanged)
+		retrieved, err := r.GetByEmail(userStub2.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, userStub2.ID, retrieved.ID)
+	})
+
+	t.Run("changing the case of the own email is allowed", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		updated, err := r.Update(userStub.ID, repo.AnyVersion, model.UserUpdate{Email: strings.ToUpper(userStub.Email)})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToUpper(userStub.Email), updated.Email)
+		retrieved, err := r.GetByEmail(userStub.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, userStub.ID, retrieved.ID)
+	})
+
 	t.Run("empty update fields are ignored", func(t *testing.T) {
 		// prepare
 		userCreateStub := model.RandomUserCreate()
@@ -208,7 +320,7 @@ func TestInMemoryUserRepo_Update(t *testing.T) {
 		userUpdateStub := model.UserUpdate{}
 
 		// execute
-		updated, err := r.Update(userStub.ID, userUpdateStub)
+		updated, err := r.Update(userStub.ID, repo.AnyVersion, userUpdateStub)
 
 		// verify
 		assert.NoError(t, err)
@@ -234,6 +346,8 @@ func TestInMemoryUserRepo_Delete(t *testing.T) {
 
 		// verify
 		assert.NoError(t, err)
+		_, err = r.GetByEmail(userStub.Email)
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
 	})
 
 	t.Run("non-existing user", func(t *testing.T) {
@@ -297,3 +411,308 @@ func TestInMemoryUserRepo_List(t *testing.T) {
 		assert.Equal(t, 100, len(lists))
 	})
 }
+
+func TestInMemoryUserRepo_UpdateStatus(t *testing.T) {
+	r := repo.NewInMemoryUserRepo()
+
+	t.Run("suspension revokes sessions", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		updated, err := r.UpdateStatus(userStub.ID, repo.AnyVersion, model.UserStatusSuspended, nil)
+
+		// verify
+		assert.NoError(t, err)
+		assert.Equal(t, model.UserStatusSuspended, updated.Status)
+		assert.Greater(t, updated.SessionEpoch, userStub.SessionEpoch)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.UpdateStatus(userStub.ID, repo.AnyVersion, model.UserStatusDeleted, nil)
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrInvalidStatusTransition)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+		_, err = r.Update(userStub.ID, repo.Version(userStub.Version), model.UserUpdate{Name: "Renamed"})
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.UpdateStatus(userStub.ID, repo.Version(userStub.Version), model.UserStatusSuspended, nil)
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("non-existing user", func(t *testing.T) {
+		// execute
+		_, err := r.UpdateStatus("non-existing-id", repo.AnyVersion, model.UserStatusSuspended, nil)
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+	})
+}
+
+func TestInMemoryUserRepo_Anonymize(t *testing.T) {
+	r := repo.NewInMemoryUserRepo()
+
+	t.Run("user pending deletion", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte("hash"), []byte("salt"))
+		require.NoError(t, err)
+		scheduledAt := time.Now().Add(-time.Minute)
+		_, err = r.UpdateStatus(userStub.ID, repo.AnyVersion, model.UserStatusPendingDeletion, &scheduledAt)
+		require.NoError(t, err)
+
+		scheduled, err := r.ListScheduledForDeletion(time.Now())
+		require.NoError(t, err)
+		require.Len(t, scheduled, 1)
+
+		// execute
+		anonymized, err := r.Anonymize(userStub.ID)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, userStub.ID, anonymized.ID)
+		assert.Equal(t, model.UserStatusDeleted, anonymized.Status)
+		assert.NotEqual(t, userStub.Email, anonymized.Email)
+		assert.Empty(t, anonymized.PasswordHash)
+		assert.NoError(t, anonymized.Validate())
+		_, err = r.GetByEmail(userStub.Email)
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+
+		scheduled, err = r.ListScheduledForDeletion(time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, scheduled)
+	})
+
+	t.Run("active user can not be anonymized", func(t *testing.T) {
+		// prepare
+		userStub, err := r.Create(model.RandomUserCreate(), []byte{}, []byte{})
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.Anonymize(userStub.ID)
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrInvalidStatusTransition)
+	})
+}
+
+func TestInMemoryUserRepo_Search(t *testing.T) {
+	r := repo.NewInMemoryUserRepo()
+
+	create := func(t *testing.T, name, email string, groups ...string) model.User {
+		t.Helper()
+
+		userCreateStub := model.RandomUserCreate()
+		userCreateStub.Name = name
+		userCreateStub.Email = email
+		userCreateStub.Groups = groups
+
+		user, err := r.Create(userCreateStub, []byte{}, []byte{})
+		require.NoError(t, err)
+
+		return user
+	}
+
+	alice := create(t, "Alice Smith", "alice@example.com", model.GroupAdmin)
+	bob := create(t, "Bob Jones", "bob@example.org", model.GroupProjectRead)
+	alfred := create(t, "alfred Brown", "Alfred@Example.com", model.GroupProjectRead)
+	_, err := r.UpdateStatus(bob.ID, repo.AnyVersion, model.UserStatusSuspended, nil)
+	require.NoError(t, err)
+
+	ids := func(users []model.User) []string {
+		result := make([]string, 0, len(users))
+		for _, user := range users {
+			result = append(result, user.ID)
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		search    model.UserSearch
+		wantIDs   []string
+		wantTotal int
+		wantLimit int
+	}{
+		{"no filter", model.UserSearch{}, []string{alice.ID, bob.ID, alfred.ID}, 3, repo.DefaultUserListLimit},
+		{"name prefix is case-insensitive", model.UserSearch{Name: "AL"}, []string{alice.ID, alfred.ID}, 2, repo.DefaultUserListLimit},
+		{"email prefix", model.UserSearch{Email: "alfred@"}, []string{alfred.ID}, 1, repo.DefaultUserListLimit},
+		{"group", model.UserSearch{Group: model.GroupProjectRead}, []string{bob.ID, alfred.ID}, 2, repo.DefaultUserListLimit},
+		{"status", model.UserSearch{Status: model.UserStatusSuspended}, []string{bob.ID}, 1, repo.DefaultUserListLimit},
+		{"sort by name", model.UserSearch{Sort: "name"}, []string{alfred.ID, alice.ID, bob.ID}, 3, repo.DefaultUserListLimit},
+		{"sort by email descending", model.UserSearch{Sort: "-email"}, []string{bob.ID, alice.ID, alfred.ID}, 3, repo.DefaultUserListLimit},
+		{"pagination", model.UserSearch{Sort: "name", Offset: 1, Limit: 1}, []string{alice.ID}, 3, 1},
+		{"offset past the end", model.UserSearch{Offset: 10}, []string{}, 3, repo.DefaultUserListLimit},
+		{"limit above the one of the repo", model.UserSearch{Limit: 1000}, []string{alice.ID, bob.ID, alfred.ID}, 3, repo.DefaultUserListLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// execute
+			page, err := r.Search(tt.search)
+
+			// verify
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, ids(page.Users))
+			assert.Equal(t, tt.wantTotal, page.Total)
+			assert.Equal(t, tt.wantLimit, page.Limit)
+		})
+	}
+}
+
+func TestInMemoryUserRepo_HasGroupMember(t *testing.T) {
+	tests := map[string]struct {
+		status model.UserStatus
+		want   bool
+	}{
+		"active member":           {status: model.UserStatusActive, want: true},
+		"suspended member":        {status: model.UserStatusSuspended, want: false},
+		"member pending deletion": {status: model.UserStatusPendingDeletion, want: false},
+		"member of another group": {status: "", want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// prepare
+			r := repo.NewInMemoryUserRepo()
+			uc := model.RandomUserCreate()
+			uc.Groups = []string{model.GroupAdmin}
+			if tt.status == "" {
+				uc.Groups = []string{model.GroupProjectRead}
+			}
+			user, err := r.Create(uc, nil, nil)
+			require.NoError(t, err)
+			if tt.status != "" && tt.status != model.UserStatusActive {
+				_, err = r.UpdateStatus(user.ID, repo.AnyVersion, tt.status, nil)
+				require.NoError(t, err)
+			}
+
+			// execute
+			got, err := r.HasGroupMember(model.GroupAdmin)
+
+			// verify
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJSONFileUserRepo(t *testing.T) {
+	t.Run("users survive a restart with their credentials", func(t *testing.T) {
+		// prepare
+		path := filepath.Join(t.TempDir(), "users.json")
+		r, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		user, err := r.Create(model.RandomUserCreate(), []byte("hash"), []byte("salt"))
+		require.NoError(t, err)
+		user, err = r.UpdateStatus(user.ID, repo.AnyVersion, model.UserStatusSuspended, nil)
+		require.NoError(t, err)
+
+		// execute
+		restarted, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		got, err := restarted.GetByEmail(user.Email)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, user, got)
+		assert.Equal(t, []byte("hash"), got.PasswordHash)
+		assert.Equal(t, 1, got.SessionEpoch)
+	})
+
+	t.Run("changes of other processes are seen", func(t *testing.T) {
+		// prepare
+		path := filepath.Join(t.TempDir(), "users.json")
+		server, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		command, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		existing, err := server.Create(model.RandomUserCreate(), nil, nil)
+		require.NoError(t, err)
+		uc := model.RandomUserCreate()
+		uc.Groups = []string{model.GroupAdmin}
+
+		// execute
+		admin, err := command.Create(uc, nil, nil)
+		require.NoError(t, err)
+		hasAdmin, err := server.HasGroupMember(model.GroupAdmin)
+
+		// verify
+		require.NoError(t, err)
+		assert.True(t, hasAdmin)
+		got, err := server.GetByID(admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, admin, got)
+		_, err = server.GetByID(existing.ID)
+		assert.NoError(t, err, "the users created before are kept")
+	})
+
+	t.Run("failed changes are not stored", func(t *testing.T) {
+		// prepare
+		path := filepath.Join(t.TempDir(), "users.json")
+		r, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		uc := model.RandomUserCreate()
+		_, err = r.Create(uc, nil, nil)
+		require.NoError(t, err)
+
+		// execute
+		_, err = r.Create(uc, nil, nil)
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrEmailTaken)
+		users, err := r.List()
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("failed first write is not stored", func(t *testing.T) {
+		// prepare
+		dir := filepath.Join(t.TempDir(), "users")
+		path := filepath.Join(dir, "users.json")
+		r, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+		require.NoError(t, err)
+		failed := model.RandomUserCreate()
+
+		// execute
+		_, createErr := r.Create(failed, nil, nil)
+		require.NoError(t, os.Mkdir(dir, 0o700))
+		created, err := r.Create(model.RandomUserCreate(), nil, nil)
+		require.NoError(t, err)
+
+		// verify
+		assert.Error(t, createErr, "the directory of the file does not exist")
+		_, err = r.GetByEmail(failed.Email)
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+		users, err := r.List()
+		require.NoError(t, err)
+		assert.Equal(t, []model.User{created}, users)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		// prepare
+		path := filepath.Join(t.TempDir(), "users.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+		// execute
+		_, err := repo.NewJSONFileUserRepo(path, repo.DefaultUserListLimit)
+
+		// verify
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,26 @@
+package repo
+
+import "fmt"
+
+// Precondition decides whether an entity may be changed, based on its current version.
+// Updates taking a precondition are compare-and-swap operations: the check and the change are atomic.
+type Precondition func(version int) bool
+
+// AnyVersion is a precondition which always holds
+var AnyVersion Precondition
+
+// Version returns a precondition which only holds for the given version
+func Version(expected int) Precondition {
+	return func(version int) bool {
+		return version == expected
+	}
+}
+
+// Check returns ErrPreconditionFailed if the precondition does not hold for the version
+func (p Precondition) Check(id string, version int) error {
+	if p != nil && !p(version) {
+		return fmt.Errorf("version mismatch: %s, current version: %d, err: %w", id, version, ErrPreconditionFailed)
+	}
+
+	return nil
+}
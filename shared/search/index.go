@@ -0,0 +1,158 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+)
+
+// Key identifies a document of the index
+type Key struct {
+	Type string
+	ID   string
+}
+
+// Field is a piece of text of a document. Matches in fields with a higher weight rank higher.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Result is a document matching a search, with its relevance score
+type Result struct {
+	Key
+	Score float64
+}
+
+// Index is an in-memory inverted index, mapping every token to the documents containing it. It is safe for
+// concurrent use.
+type Index struct {
+	mu sync.RWMutex
+	// postings maps tokens to the documents containing them, and to the weighted frequency of the token in them
+	postings map[string]map[Key]float64
+	// tokens maps documents to their distinct tokens, so that they can be removed from the postings
+	tokens map[Key][]string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[Key]float64),
+		tokens:   make(map[Key][]string),
+	}
+}
+
+// Put adds a document to the index, replacing the previous version of it
+func (i *Index) Put(key Key, fields ...Field) {
+	frequencies := make(map[string]float64)
+	for _, f := range fields {
+		for _, token := range Tokenize(f.Text) {
+			frequencies[token] += f.Weight
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(key)
+
+	tokens := make([]string, 0, len(frequencies))
+	for token, frequency := range frequencies {
+		if i.postings[token] == nil {
+			i.postings[token] = make(map[Key]float64)
+		}
+		i.postings[token][key] = frequency
+		tokens = append(tokens, token)
+	}
+	i.tokens[key] = tokens
+}
+
+// Remove removes a document from the index, removing unknown documents is a no-op
+func (i *Index) Remove(key Key) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(key)
+}
+
+func (i *Index) remove(key Key) {
+	for _, token := range i.tokens[key] {
+		delete(i.postings[token], key)
+		if len(i.postings[token]) == 0 {
+			delete(i.postings, token)
+		}
+	}
+	delete(i.tokens, key)
+}
+
+// Len returns the number of documents in the index
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.tokens)
+}
+
+// Search returns at most limit documents containing every token of the query, the most relevant first.
+// Documents are only considered if allow returns true for them, a nil allow allows every document.
+// Relevance is the sum of the weighted frequency of each query token in the document, multiplied by the inverse
+// document frequency of the token, so that rare tokens count more than common ones.
+func (i *Index) Search(query string, allow func(Key) bool, limit int) []Result {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 || limit <= 0 {
+		return []Result{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// Start from the rarest token, it has the fewest candidates
+	slices.Sort(tokens)
+	tokens = slices.Compact(tokens)
+	slices.SortFunc(tokens, func(a, b string) int {
+		return cmp.Compare(len(i.postings[a]), len(i.postings[b]))
+	})
+
+	total := float64(len(i.tokens))
+	results := make([]Result, 0)
+
+candidates:
+	for key, frequency := range i.postings[tokens[0]] {
+		if allow != nil && !allow(key) {
+			continue
+		}
+
+		score := frequency * i.idf(tokens[0], total)
+		for _, token := range tokens[1:] {
+			frequency, ok := i.postings[token][key]
+			if !ok {
+				continue candidates
+			}
+			score += frequency * i.idf(token, total)
+		}
+
+		results = append(results, Result{Key: key, Score: score})
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// idf returns the inverse document frequency of the token
+func (i *Index) idf(token string, total float64) float64 {
+	return math.Log(1 + total/float64(len(i.postings[token])))
+}
@@ -0,0 +1,144 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keys(results []search.Result) []search.Key {
+	keys := make([]search.Key, 0, len(results))
+	for _, r := range results {
+		keys = append(keys, r.Key)
+	}
+
+	return keys
+}
+
+func TestIndex_Search(t *testing.T) {
+	// prepare
+	milk := search.Key{Type: "todo", ID: "1"}
+	bread := search.Key{Type: "todo", ID: "2"}
+	shopping := search.Key{Type: "project", ID: "3"}
+
+	index := search.NewIndex()
+	index.Put(milk, search.Field{Text: "Buy milk", Weight: 2}, search.Field{Text: "Bio, discounted", Weight: 1})
+	index.Put(bread, search.Field{Text: "Buy bread", Weight: 2}, search.Field{Text: "Goes well with milk", Weight: 1})
+	index.Put(shopping, search.Field{Text: "Shopping", Weight: 2}, search.Field{Text: "Everything to buy", Weight: 1})
+
+	tests := map[string]struct {
+		query string
+		allow func(search.Key) bool
+		limit int
+		want  []search.Key
+	}{
+		"single token": {
+			query: "bread",
+			limit: 10,
+			want:  []search.Key{bread},
+		},
+		"matches in heavier fields rank higher": {
+			query: "milk",
+			limit: 10,
+			want:  []search.Key{milk, bread},
+		},
+		"every token must match": {
+			query: "buy milk",
+			limit: 10,
+			want:  []search.Key{milk, bread},
+		},
+		"ties are broken by id": {
+			query: "buy",
+			limit: 10,
+			want:  []search.Key{milk, bread, shopping},
+		},
+		"query is normalized": {
+			query: "MÏLK!",
+			limit: 10,
+			want:  []search.Key{milk, bread},
+		},
+		"unknown token": {
+			query: "milk cheese",
+			limit: 10,
+			want:  []search.Key{},
+		},
+		"no tokens": {
+			query: "?!",
+			limit: 10,
+			want:  []search.Key{},
+		},
+		"limit": {
+			query: "buy",
+			limit: 1,
+			want:  []search.Key{milk},
+		},
+		"filtered before the limit": {
+			query: "buy",
+			allow: func(k search.Key) bool { return k.Type == "project" },
+			limit: 1,
+			want:  []search.Key{shopping},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			results := index.Search(tt.query, tt.allow, tt.limit)
+
+			// verify
+			assert.Equal(t, tt.want, keys(results))
+		})
+	}
+}
+
+func TestIndex_Put(t *testing.T) {
+	t.Run("replaces the previous version", func(t *testing.T) {
+		// prepare
+		key := search.Key{Type: "todo", ID: "1"}
+		index := search.NewIndex()
+		index.Put(key, search.Field{Text: "Buy milk", Weight: 1})
+
+		// execute
+		index.Put(key, search.Field{Text: "Buy bread", Weight: 1})
+
+		// verify
+		assert.Empty(t, index.Search("milk", nil, 10))
+		assert.Equal(t, []search.Key{key}, keys(index.Search("bread", nil, 10)))
+		assert.Equal(t, 1, index.Len())
+	})
+}
+
+func TestIndex_Remove(t *testing.T) {
+	t.Run("removed documents are not found", func(t *testing.T) {
+		// prepare
+		key := search.Key{Type: "todo", ID: "1"}
+		index := search.NewIndex()
+		index.Put(key, search.Field{Text: "Buy milk", Weight: 1})
+
+		// execute
+		index.Remove(key)
+		index.Remove(search.Key{Type: "todo", ID: "unknown"})
+
+		// verify
+		assert.Empty(t, index.Search("milk", nil, 10))
+		assert.Zero(t, index.Len())
+	})
+
+	t.Run("scores stay positive", func(t *testing.T) {
+		// prepare
+		index := search.NewIndex()
+		for _, id := range []string{"1", "2", "3"} {
+			index.Put(search.Key{Type: "todo", ID: id}, search.Field{Text: "milk", Weight: 1})
+		}
+
+		// execute
+		index.Remove(search.Key{Type: "todo", ID: "1"})
+		results := index.Search("milk", nil, 10)
+
+		// verify
+		require.Len(t, results, 2)
+		assert.Positive(t, results[0].Score)
+	})
+}
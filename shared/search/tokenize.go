@@ -0,0 +1,31 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// newNormalizer returns a transformer which decomposes text, drops the diacritical marks and folds the case,
+// so that "Årsmöte", "ARSMOTE" and "arsmote" all become the same token. Transformers are stateful and must not
+// be shared between goroutines.
+func newNormalizer() transform.Transformer {
+	return transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), cases.Fold(), norm.NFC)
+}
+
+// Tokenize splits text into normalized tokens. Tokens are the runs of letters and numbers in the text.
+func Tokenize(text string) []string {
+	normalized, _, err := transform.String(newNormalizer(), text)
+	if err != nil {
+		// The transformers only fail on invalid input, which is tokenized as is
+		normalized = strings.ToLower(text)
+	}
+
+	return strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
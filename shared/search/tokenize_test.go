@@ -0,0 +1,34 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want []string
+	}{
+		"empty":                  {"", []string{}},
+		"words are lowercased":   {"Buy MILK", []string{"buy", "milk"}},
+		"punctuation splits":     {"milk, bread & eggs!", []string{"milk", "bread", "eggs"}},
+		"numbers are kept":       {"call 2 times", []string{"call", "2", "times"}},
+		"diacritics are dropped": {"Årsmöte café", []string{"arsmote", "cafe"}},
+		"case is folded":         {"Straße", []string{"strasse"}},
+		"compatibility forms":    {"ﬁle №1", []string{"file", "no1"}},
+		"non-latin scripts":      {"Привет мир", []string{"привет", "мир"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// execute
+			got := search.Tokenize(tt.text)
+
+			// verify
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+)
+
+// Actor describes who initiated the current request, as far as the services are concerned.
+type Actor struct {
+	UserID string
+	IP     string
+}
+
+type actorContextKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying the actor of the request
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or an anonymous actor if there is none
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+
+	return actor
+}
+
+type AuditService struct {
+	sink repo.AuditSink
+}
+
+func NewAuditService(s repo.AuditSink) *AuditService {
+	return &AuditService{sink: s}
+}
+
+// Record appends an event to the audit log. Actor and IP default to the ones found in ctx.
+// Failing to write the audit log must not fail the audited operation, so errors are only logged.
+func (s *AuditService) Record(ctx context.Context, event model.AuditEvent) {
+	actor := ActorFromContext(ctx)
+	if event.Actor == "" {
+		event.Actor = actor.UserID
+	}
+	if event.IP == "" {
+		event.IP = actor.IP
+	}
+
+	if _, err := s.sink.Append(event); err != nil {
+		LoggerFromContext(ctx).Error("Failed to record audit event", "type", event.Type, "err", err)
+	}
+}
+
+func (s *AuditService) Query(filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	return s.sink.Query(filter)
+}
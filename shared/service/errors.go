@@ -0,0 +1,25 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds returned by the services on top of the ones defined by the repos. Specific errors wrap one of these,
+// so callers can decide how to react without knowing every error.
+var (
+	// ErrValidation means that the input of the service is invalid, the validator errors remain available
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized means that the caller could not be authenticated
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means that the caller is known, but is not allowed to perform the action
+	ErrForbidden = errors.New("forbidden")
+)
+
+// ErrReadOnlyField means that a partial update tried to change a field which can not be changed
+var ErrReadOnlyField = fmt.Errorf("read-only field changed, err: %w", ErrValidation)
+
+// invalid marks err as a validation error
+func invalid(err error) error {
+	return fmt.Errorf("%w: %w", ErrValidation, err)
+}
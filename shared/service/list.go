@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/peteraba/go-frameworks/shared/model"
 	"github.com/peteraba/go-frameworks/shared/repo"
 )
@@ -13,30 +16,96 @@ func NewListService(r repo.ListRepo) *ListService {
 	return &ListService{repo: r}
 }
 
-func (s *ListService) Create(lc model.ListCreate) (model.List, error) {
+func (s *ListService) Create(ctx context.Context, lc model.ListCreate) (model.List, error) {
 	if err := lc.Validate(); err != nil {
+		return model.List{}, invalid(err)
+	}
+
+	created, err := s.repo.Create(lc)
+	if err != nil {
 		return model.List{}, err
 	}
+	LoggerFromContext(ctx).Info("List created", "id", created.ID)
 
-	return s.repo.Create(lc)
+	return created, nil
 }
 
-func (s *ListService) GetByID(id string) (model.List, error) {
+func (s *ListService) GetByID(ctx context.Context, id string) (model.List, error) {
+	if err := ctx.Err(); err != nil {
+		return model.List{}, err
+	}
+
 	return s.repo.GetByID(id)
 }
 
-func (s *ListService) Update(id string, lu model.ListUpdate) (model.List, error) {
+// Update replaces the list if the precondition holds for its current version
+func (s *ListService) Update(ctx context.Context, id string, precondition repo.Precondition, lu model.ListUpdate) (model.List, error) {
 	if err := lu.Validate(); err != nil {
+		return model.List{}, invalid(err)
+	}
+
+	return s.update(ctx, id, precondition, lu)
+}
+
+// Patch applies a partial update to the list. The patched list must be valid, and it can not change its ID
+// or version, or move to another project. The change is only stored if the list was not modified in the meantime.
+func (s *ListService) Patch(ctx context.Context, id string, precondition repo.Precondition, apply func(model.List) (model.List, error)) (model.List, error) {
+	list, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.List{}, err
+	}
+
+	if err := precondition.Check(id, list.Version); err != nil {
+		return model.List{}, err
+	}
+
+	patched, err := apply(list)
+	if err != nil {
 		return model.List{}, err
 	}
 
-	return s.repo.Update(id, lu)
+	if patched.ID != list.ID {
+		return model.List{}, fmt.Errorf("field: id, err: %w", ErrReadOnlyField)
+	}
+	if patched.Version != list.Version {
+		return model.List{}, fmt.Errorf("field: version, err: %w", ErrReadOnlyField)
+	}
+	if patched.ProjectID != list.ProjectID {
+		return model.List{}, fmt.Errorf("field: projectId, err: %w", ErrReadOnlyField)
+	}
+	if err := patched.Validate(); err != nil {
+		return model.List{}, invalid(err)
+	}
+
+	return s.update(ctx, id, repo.Version(list.Version), model.ListUpdate{Name: patched.Name, Description: patched.Description})
 }
 
-func (s *ListService) Delete(id string) error {
-	return s.repo.Delete(id)
+// Delete removes the list if the precondition holds for its current version
+func (s *ListService) Delete(ctx context.Context, id string, precondition repo.Precondition) error {
+	if err := s.repo.Delete(id, precondition); err != nil {
+		return err
+	}
+	LoggerFromContext(ctx).Info("List deleted", "id", id)
+
+	return nil
 }
 
-func (s *ListService) List() ([]model.List, error) {
-	return s.repo.List()
+// update stores the change and logs it
+func (s *ListService) update(ctx context.Context, id string, precondition repo.Precondition, lu model.ListUpdate) (model.List, error) {
+	updated, err := s.repo.Update(id, precondition, lu)
+	if err != nil {
+		return model.List{}, err
+	}
+	LoggerFromContext(ctx).Info("List updated", "id", id, "version", updated.Version)
+
+	return updated, nil
+}
+
+// List returns the lists matching the query
+func (s *ListService) List(ctx context.Context, query repo.Query) ([]model.List, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(query)
 }
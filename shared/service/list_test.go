@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/peteraba/go-frameworks/shared/model"
@@ -16,7 +17,7 @@ func TestListService_CRUD(t *testing.T) {
 
 	// Create
 	lc := model.RandomListCreate()
-	list, err := svc.Create(lc)
+	list, err := svc.Create(context.Background(), lc)
 	require.NoError(t, err)
 	assert.Equal(t, lc.Name, list.Name)
 	assert.Equal(t, lc.Description, list.Description)
@@ -24,25 +25,80 @@ func TestListService_CRUD(t *testing.T) {
 	assert.NotEmpty(t, list.ID)
 
 	// GetByID
-	got, err := svc.GetByID(list.ID)
+	got, err := svc.GetByID(context.Background(), list.ID)
 	require.NoError(t, err)
 	assert.Equal(t, list, got)
 
 	// Update
 	update := model.RandomListUpdate()
-	updated, err := svc.Update(list.ID, update)
+	updated, err := svc.Update(context.Background(), list.ID, repo.AnyVersion, update)
 	require.NoError(t, err)
 	assert.Equal(t, update.Name, updated.Name)
 	assert.Equal(t, update.Description, updated.Description)
 
 	// List
-	lists, err := svc.List()
+	lists, err := svc.List(context.Background(), repo.Query{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, lists)
 
 	// Delete
-	err = svc.Delete(list.ID)
+	err = svc.Delete(context.Background(), list.ID, repo.AnyVersion)
 	assert.NoError(t, err)
-	_, err = svc.GetByID(list.ID)
+	_, err = svc.GetByID(context.Background(), list.ID)
 	assert.Error(t, err)
 }
+
+func TestListService_Patch(t *testing.T) {
+	svc := service.NewListService(repo.NewInMemoryListRepo())
+
+	list, err := svc.Create(context.Background(), model.RandomListCreate())
+	require.NoError(t, err)
+
+	t.Run("patched list is stored", func(t *testing.T) {
+		// execute
+		patched, err := svc.Patch(context.Background(), list.ID, repo.AnyVersion, func(l model.List) (model.List, error) {
+			l.Name = "Patched"
+
+			return l, nil
+		})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, "Patched", patched.Name)
+		assert.Equal(t, list.Description, patched.Description)
+	})
+
+	t.Run("project can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), list.ID, repo.AnyVersion, func(l model.List) (model.List, error) {
+			l.ProjectID = "01K02V79XJM8DS0W39VFEBB20Z"
+
+			return l, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+	})
+
+	t.Run("stale precondition", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), list.ID, repo.Version(0), func(l model.List) (model.List, error) {
+			return l, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("version can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), list.ID, repo.AnyVersion, func(l model.List) (model.List, error) {
+			l.Version = 100
+
+			return l, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+	})
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/peteraba/go-frameworks/shared/model"
 	"github.com/peteraba/go-frameworks/shared/repo"
 )
@@ -13,30 +16,93 @@ func NewProjectService(r repo.ProjectRepo) *ProjectService {
 	return &ProjectService{repo: r}
 }
 
-func (s *ProjectService) Create(pc model.ProjectCreate) (model.Project, error) {
+func (s *ProjectService) Create(ctx context.Context, pc model.ProjectCreate) (model.Project, error) {
 	if err := pc.Validate(); err != nil {
+		return model.Project{}, invalid(err)
+	}
+
+	created, err := s.repo.Create(pc)
+	if err != nil {
 		return model.Project{}, err
 	}
+	LoggerFromContext(ctx).Info("Project created", "id", created.ID)
 
-	return s.repo.Create(pc)
+	return created, nil
 }
 
-func (s *ProjectService) GetByID(id string) (model.Project, error) {
+func (s *ProjectService) GetByID(ctx context.Context, id string) (model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Project{}, err
+	}
+
 	return s.repo.GetByID(id)
 }
 
-func (s *ProjectService) Update(id string, pu model.ProjectUpdate) (model.Project, error) {
+// Update replaces the project if the precondition holds for its current version
+func (s *ProjectService) Update(ctx context.Context, id string, precondition repo.Precondition, pu model.ProjectUpdate) (model.Project, error) {
 	if err := pu.Validate(); err != nil {
+		return model.Project{}, invalid(err)
+	}
+
+	return s.update(ctx, id, precondition, pu)
+}
+
+// Patch applies a partial update to the project. The patched project must be valid, and its ID
+// and version can not change. The change is only stored if the project was not modified in the meantime.
+func (s *ProjectService) Patch(ctx context.Context, id string, precondition repo.Precondition, apply func(model.Project) (model.Project, error)) (model.Project, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
 		return model.Project{}, err
 	}
 
-	return s.repo.Update(id, pu)
+	if err := precondition.Check(id, project.Version); err != nil {
+		return model.Project{}, err
+	}
+
+	patched, err := apply(project)
+	if err != nil {
+		return model.Project{}, err
+	}
+
+	if patched.ID != project.ID {
+		return model.Project{}, fmt.Errorf("field: id, err: %w", ErrReadOnlyField)
+	}
+	if patched.Version != project.Version {
+		return model.Project{}, fmt.Errorf("field: version, err: %w", ErrReadOnlyField)
+	}
+	if err := patched.Validate(); err != nil {
+		return model.Project{}, invalid(err)
+	}
+
+	return s.update(ctx, id, repo.Version(project.Version), model.ProjectUpdate{Name: patched.Name, Description: patched.Description})
+}
+
+// Delete removes the project if the precondition holds for its current version
+func (s *ProjectService) Delete(ctx context.Context, id string, precondition repo.Precondition) error {
+	if err := s.repo.Delete(id, precondition); err != nil {
+		return err
+	}
+	LoggerFromContext(ctx).Info("Project deleted", "id", id)
+
+	return nil
 }
 
-func (s *ProjectService) Delete(id string) error {
-	return s.repo.Delete(id)
+// update stores the change and logs it
+func (s *ProjectService) update(ctx context.Context, id string, precondition repo.Precondition, pu model.ProjectUpdate) (model.Project, error) {
+	updated, err := s.repo.Update(id, precondition, pu)
+	if err != nil {
+		return model.Project{}, err
+	}
+	LoggerFromContext(ctx).Info("Project updated", "id", id, "version", updated.Version)
+
+	return updated, nil
 }
 
-func (s *ProjectService) List() ([]model.Project, error) {
-	return s.repo.List()
+// List returns the projects matching the query
+func (s *ProjectService) List(ctx context.Context, query repo.Query) ([]model.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(query)
 }
@@ -1,6 +1,9 @@
 package service_test
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
 	"testing"
 
 	"github.com/peteraba/go-frameworks/shared/model"
@@ -11,37 +14,156 @@ import (
 )
 
 func TestProjectService_CRUD(t *testing.T) {
-	repo := repo.NewInMemoryProjectRepo()
-	svc := service.NewProjectService(repo)
+	r := repo.NewInMemoryProjectRepo()
+	svc := service.NewProjectService(r)
 
 	// Create
 	pc := model.RandomProjectCreate()
-	project, err := svc.Create(pc)
+	project, err := svc.Create(context.Background(), pc)
 	require.NoError(t, err)
 	assert.Equal(t, pc.Name, project.Name)
 	assert.Equal(t, pc.Description, project.Description)
 	assert.NotEmpty(t, project.ID)
 
 	// GetByID
-	got, err := svc.GetByID(project.ID)
+	got, err := svc.GetByID(context.Background(), project.ID)
 	require.NoError(t, err)
 	assert.Equal(t, project, got)
 
 	// Update
 	update := model.RandomProjectUpdate()
-	updated, err := svc.Update(project.ID, update)
+	updated, err := svc.Update(context.Background(), project.ID, repo.AnyVersion, update)
 	require.NoError(t, err)
 	assert.Equal(t, update.Name, updated.Name)
 	assert.Equal(t, update.Description, updated.Description)
 
 	// List
-	projects, err := svc.List()
+	projects, err := svc.List(context.Background(), repo.Query{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, projects)
 
 	// Delete
-	err = svc.Delete(project.ID)
+	err = svc.Delete(context.Background(), project.ID, repo.AnyVersion)
 	assert.NoError(t, err)
-	_, err = svc.GetByID(project.ID)
+	_, err = svc.GetByID(context.Background(), project.ID)
 	assert.Error(t, err)
 }
+
+func TestProjectService_Patch(t *testing.T) {
+	svc := service.NewProjectService(repo.NewInMemoryProjectRepo())
+
+	project, err := svc.Create(context.Background(), model.RandomProjectCreate())
+	require.NoError(t, err)
+
+	t.Run("patched project is stored", func(t *testing.T) {
+		// execute
+		patched, err := svc.Patch(context.Background(), project.ID, repo.AnyVersion, func(p model.Project) (model.Project, error) {
+			p.Description = ""
+
+			return p, nil
+		})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, project.Name, patched.Name)
+		assert.Empty(t, patched.Description)
+
+		got, err := svc.GetByID(context.Background(), project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, patched, got)
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), project.ID, repo.AnyVersion, func(p model.Project) (model.Project, error) {
+			p.Name = ""
+
+			return p, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+
+	t.Run("id can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), project.ID, repo.AnyVersion, func(p model.Project) (model.Project, error) {
+			p.ID = "01K02SD13A5YKWWZFV9AQP7H1X"
+
+			return p, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), "01K02SD13A5YKWWZFV9AQP7H1X", repo.AnyVersion, func(p model.Project) (model.Project, error) {
+			return p, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrNotFound)
+	})
+
+	t.Run("stale precondition", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), project.ID, repo.Version(0), func(p model.Project) (model.Project, error) {
+			return p, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("version can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), project.ID, repo.AnyVersion, func(p model.Project) (model.Project, error) {
+			p.Version = 100
+
+			return p, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+	})
+}
+
+func TestProjectService_Context(t *testing.T) {
+	t.Run("changes are logged with the logger of the context", func(t *testing.T) {
+		// prepare
+		svc := service.NewProjectService(repo.NewInMemoryProjectRepo())
+		var buf bytes.Buffer
+		ctx := service.ContextWithLogger(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)).With("requestId", "req-1"))
+
+		// execute
+		project, err := svc.Create(ctx, model.RandomProjectCreate())
+		require.NoError(t, err)
+		_, err = svc.Update(ctx, project.ID, repo.AnyVersion, model.RandomProjectUpdate())
+		require.NoError(t, err)
+		err = svc.Delete(ctx, project.ID, repo.AnyVersion)
+		require.NoError(t, err)
+
+		// verify
+		assert.Contains(t, buf.String(), `msg="Project created" requestId=req-1 id=`+project.ID)
+		assert.Contains(t, buf.String(), `msg="Project updated" requestId=req-1 id=`+project.ID+" version=2")
+		assert.Contains(t, buf.String(), `msg="Project deleted" requestId=req-1 id=`+project.ID)
+	})
+
+	t.Run("canceled requests are not served", func(t *testing.T) {
+		// prepare
+		svc := service.NewProjectService(repo.NewInMemoryProjectRepo())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// execute
+		_, listErr := svc.List(ctx, repo.Query{})
+		_, getErr := svc.GetByID(ctx, model.RandomProject().ID)
+
+		// verify
+		assert.ErrorIs(t, listErr, context.Canceled)
+		assert.ErrorIs(t, getErr, context.Canceled)
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/search"
+)
+
+const defaultSearchLimit = 20
+
+type SearchService struct {
+	index    *search.Index
+	projects repo.ProjectRepo
+	lists    repo.ListRepo
+	todos    repo.TodoRepo
+}
+
+// NewSearchService returns a service searching the index, which must be kept up to date by the indexed repos
+func NewSearchService(index *search.Index, p repo.ProjectRepo, l repo.ListRepo, t repo.TodoRepo) *SearchService {
+	return &SearchService{index: index, projects: p, lists: l, todos: t}
+}
+
+// Search returns the hits of the query, the most relevant first. Only hits of the given types are returned,
+// so callers can leave out the types the user may not access.
+func (s *SearchService) Search(sq model.SearchQuery, types []string) ([]model.SearchHit, error) {
+	if err := sq.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	limit := sq.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[t] = true
+	}
+
+	results := s.index.Search(sq.Q, func(key search.Key) bool { return allowed[key.Type] }, limit)
+
+	hits := make([]model.SearchHit, 0, len(results))
+	for _, result := range results {
+		item, err := s.get(result.Key)
+		if errors.Is(err, repo.ErrNotFound) {
+			// The item was removed since the search
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		hits = append(hits, model.SearchHit{Type: result.Type, ID: result.ID, Score: result.Score, Item: item})
+	}
+
+	return hits, nil
+}
+
+// get returns the item identified by the key
+func (s *SearchService) get(key search.Key) (any, error) {
+	switch key.Type {
+	case model.SearchHitProject:
+		return s.projects.GetByID(key.ID)
+	case model.SearchHitList:
+		return s.lists.GetByID(key.ID)
+	case model.SearchHitTodo:
+		return s.todos.GetByID(key.ID)
+	}
+
+	return nil, fmt.Errorf("unknown search hit type: %s", key.Type)
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/peteraba/go-frameworks/shared/model"
+	"github.com/peteraba/go-frameworks/shared/repo"
+	"github.com/peteraba/go-frameworks/shared/search"
+	"github.com/peteraba/go-frameworks/shared/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	// prepare
+	index := search.NewIndex()
+	projects := repo.NewIndexedProjectRepo(repo.NewInMemoryProjectRepo(), index)
+	lists := repo.NewIndexedListRepo(repo.NewInMemoryListRepo(), index)
+	todos := repo.NewIndexedTodoRepo(repo.NewInMemoryTodoRepo(), index)
+	svc := service.NewSearchService(index, projects, lists, todos)
+
+	project, err := projects.Create(model.ProjectCreate{Name: "Groceries"})
+	require.NoError(t, err)
+	list, err := lists.Create(model.ListCreate{ProjectID: project.ID, Name: "Weekly groceries"})
+	require.NoError(t, err)
+	todo, err := todos.Create(model.TodoCreate{ListID: list.ID, Title: "Milk", Description: "From the groceries store"})
+	require.NoError(t, err)
+
+	allTypes := []string{model.SearchHitProject, model.SearchHitList, model.SearchHitTodo}
+
+	t.Run("typed hits with their items", func(t *testing.T) {
+		// execute
+		hits, err := svc.Search(model.SearchQuery{Q: "groceries"}, allTypes)
+
+		// verify
+		require.NoError(t, err)
+		require.Len(t, hits, 3)
+		items := map[string]any{}
+		for _, hit := range hits {
+			items[hit.Type] = hit.Item
+		}
+		assert.Equal(t, map[string]any{model.SearchHitProject: project, model.SearchHitList: list, model.SearchHitTodo: todo}, items)
+		assert.Equal(t, model.SearchHitTodo, hits[2].Type, "matches in descriptions rank lower")
+		assert.Greater(t, hits[1].Score, hits[2].Score)
+	})
+
+	t.Run("only allowed types are returned", func(t *testing.T) {
+		// execute
+		hits, err := svc.Search(model.SearchQuery{Q: "groceries"}, []string{model.SearchHitTodo})
+
+		// verify
+		require.NoError(t, err)
+		require.Len(t, hits, 1)
+		assert.Equal(t, todo.ID, hits[0].ID)
+	})
+
+	t.Run("no allowed types", func(t *testing.T) {
+		// execute
+		hits, err := svc.Search(model.SearchQuery{Q: "groceries"}, nil)
+
+		// verify
+		require.NoError(t, err)
+		assert.Empty(t, hits)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		// execute
+		hits, err := svc.Search(model.SearchQuery{Q: "groceries", Limit: 2}, allTypes)
+
+		// verify
+		require.NoError(t, err)
+		assert.Len(t, hits, 2)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		// execute
+		_, err := svc.Search(model.SearchQuery{}, allTypes)
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrValidation)
+	})
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/peteraba/go-frameworks/shared/model"
 	"github.com/peteraba/go-frameworks/shared/repo"
 )
@@ -13,30 +16,100 @@ func NewTodoService(r repo.TodoRepo) *TodoService {
 	return &TodoService{repo: r}
 }
 
-func (s *TodoService) Create(tc model.TodoCreate) (model.Todo, error) {
+func (s *TodoService) Create(ctx context.Context, tc model.TodoCreate) (model.Todo, error) {
 	if err := tc.Validate(); err != nil {
+		return model.Todo{}, invalid(err)
+	}
+
+	created, err := s.repo.Create(tc)
+	if err != nil {
 		return model.Todo{}, err
 	}
+	LoggerFromContext(ctx).Info("Todo item created", "id", created.ID)
 
-	return s.repo.Create(tc)
+	return created, nil
 }
 
-func (s *TodoService) GetByID(id string) (model.Todo, error) {
+func (s *TodoService) GetByID(ctx context.Context, id string) (model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Todo{}, err
+	}
+
 	return s.repo.GetByID(id)
 }
 
-func (s *TodoService) Update(id string, tu model.TodoUpdate) (model.Todo, error) {
+// Update replaces the todo if the precondition holds for its current version
+func (s *TodoService) Update(ctx context.Context, id string, precondition repo.Precondition, tu model.TodoUpdate) (model.Todo, error) {
 	if err := tu.Validate(); err != nil {
+		return model.Todo{}, invalid(err)
+	}
+
+	return s.update(ctx, id, precondition, tu)
+}
+
+// Patch applies a partial update to the todo item. The patched item must be valid, and it can not change its ID
+// or version, or move to another list. The change is only stored if the item was not modified in the meantime.
+func (s *TodoService) Patch(ctx context.Context, id string, precondition repo.Precondition, apply func(model.Todo) (model.Todo, error)) (model.Todo, error) {
+	todo, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.Todo{}, err
+	}
+
+	if err := precondition.Check(id, todo.Version); err != nil {
+		return model.Todo{}, err
+	}
+
+	patched, err := apply(todo)
+	if err != nil {
 		return model.Todo{}, err
 	}
 
-	return s.repo.Update(id, tu)
+	if patched.ID != todo.ID {
+		return model.Todo{}, fmt.Errorf("field: id, err: %w", ErrReadOnlyField)
+	}
+	if patched.Version != todo.Version {
+		return model.Todo{}, fmt.Errorf("field: version, err: %w", ErrReadOnlyField)
+	}
+	if patched.ListID != todo.ListID {
+		return model.Todo{}, fmt.Errorf("field: listId, err: %w", ErrReadOnlyField)
+	}
+	if err := patched.Validate(); err != nil {
+		return model.Todo{}, invalid(err)
+	}
+
+	return s.update(ctx, id, repo.Version(todo.Version), model.TodoUpdate{
+		Title:       patched.Title,
+		Description: patched.Description,
+		Completed:   patched.Completed,
+	})
 }
 
-func (s *TodoService) Delete(id string) error {
-	return s.repo.Delete(id)
+// Delete removes the todo if the precondition holds for its current version
+func (s *TodoService) Delete(ctx context.Context, id string, precondition repo.Precondition) error {
+	if err := s.repo.Delete(id, precondition); err != nil {
+		return err
+	}
+	LoggerFromContext(ctx).Info("Todo item deleted", "id", id)
+
+	return nil
 }
 
-func (s *TodoService) List() ([]model.Todo, error) {
-	return s.repo.List()
+// update stores the change and logs it
+func (s *TodoService) update(ctx context.Context, id string, precondition repo.Precondition, tu model.TodoUpdate) (model.Todo, error) {
+	updated, err := s.repo.Update(id, precondition, tu)
+	if err != nil {
+		return model.Todo{}, err
+	}
+	LoggerFromContext(ctx).Info("Todo item updated", "id", id, "version", updated.Version)
+
+	return updated, nil
+}
+
+// List returns the todo items matching the query
+func (s *TodoService) List(ctx context.Context, query repo.Query) ([]model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(query)
 }
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/peteraba/go-frameworks/shared/model"
@@ -11,12 +12,12 @@ import (
 )
 
 func TestTodoService_CRUD(t *testing.T) {
-	repo := repo.NewInMemoryTodoRepo()
-	svc := service.NewTodoService(repo)
+	r := repo.NewInMemoryTodoRepo()
+	svc := service.NewTodoService(r)
 
 	// Create
 	tc := model.RandomTodoCreate()
-	todo, err := svc.Create(tc)
+	todo, err := svc.Create(context.Background(), tc)
 	require.NoError(t, err)
 	assert.Equal(t, tc.Title, todo.Title)
 	assert.Equal(t, tc.Description, todo.Description)
@@ -25,28 +26,84 @@ func TestTodoService_CRUD(t *testing.T) {
 	assert.NotEmpty(t, todo.ID)
 
 	// GetByID
-	got, err := svc.GetByID(todo.ID)
+	got, err := svc.GetByID(context.Background(), todo.ID)
 	require.NoError(t, err)
 	assert.Equal(t, todo, got)
 
 	// Update
 	update := model.RandomTodoUpdate()
-	updated, err := svc.Update(todo.ID, update)
+	updated, err := svc.Update(context.Background(), todo.ID, repo.AnyVersion, update)
 	require.NoError(t, err)
 	assert.Equal(t, update.Title, updated.Title)
 	assert.Equal(t, update.Description, updated.Description)
-	if update.Completed != nil {
-		assert.Equal(t, *update.Completed, updated.Completed)
-	}
+	assert.Equal(t, update.Completed, updated.Completed)
 
 	// List
-	todos, err := svc.List()
+	todos, err := svc.List(context.Background(), repo.Query{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, todos)
 
 	// Delete
-	err = svc.Delete(todo.ID)
+	err = svc.Delete(context.Background(), todo.ID, repo.AnyVersion)
 	assert.NoError(t, err)
-	_, err = svc.GetByID(todo.ID)
+	_, err = svc.GetByID(context.Background(), todo.ID)
 	assert.Error(t, err)
 }
+
+func TestTodoService_Patch(t *testing.T) {
+	svc := service.NewTodoService(repo.NewInMemoryTodoRepo())
+
+	tc := model.RandomTodoCreate()
+	tc.Completed = false
+	todo, err := svc.Create(context.Background(), tc)
+	require.NoError(t, err)
+
+	t.Run("patched todo is stored", func(t *testing.T) {
+		// execute
+		patched, err := svc.Patch(context.Background(), todo.ID, repo.AnyVersion, func(t model.Todo) (model.Todo, error) {
+			t.Completed = true
+
+			return t, nil
+		})
+
+		// verify
+		require.NoError(t, err)
+		assert.True(t, patched.Completed)
+		assert.Equal(t, todo.Title, patched.Title)
+		assert.Equal(t, todo.Description, patched.Description)
+	})
+
+	t.Run("list can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), todo.ID, repo.AnyVersion, func(t model.Todo) (model.Todo, error) {
+			t.ListID = "01K02SDGMJM0Q915WHQYJ0YVDY"
+
+			return t, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+	})
+
+	t.Run("stale precondition", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), todo.ID, repo.Version(0), func(t model.Todo) (model.Todo, error) {
+			return t, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrPreconditionFailed)
+	})
+
+	t.Run("version can not change", func(t *testing.T) {
+		// execute
+		_, err := svc.Patch(context.Background(), todo.ID, repo.AnyVersion, func(t model.Todo) (model.Todo, error) {
+			t.Version = 100
+
+			return t, nil
+		})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrReadOnlyField)
+	})
+}
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/subtle"
 	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,103 +16,452 @@ import (
 	"golang.org/x/crypto/argon2"
 )
 
+// Argon2Params are the cost parameters of the Argon2id password hash. Passwords hashed with other parameters can
+// not be verified, so changing them invalidates existing passwords.
+type Argon2Params struct {
+	Time uint32
+	// Memory is the amount of memory to use in KiB
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// UserConfig holds the settings of the user service
+type UserConfig struct {
+	// JWTSigningKey signs and verifies the tokens, a random key is generated if it is empty, in which case tokens
+	// do not survive restarts
+	JWTSigningKey []byte
+	TokenIssuer   string
+	TokenExpiry   time.Duration
+	Argon2        Argon2Params
+	// DeletionGracePeriod is the time a user pending deletion can still be restored before being anonymized
+	DeletionGracePeriod time.Duration
+}
+
+// DefaultUserConfig returns the default settings of the user service.
 // The draft RFC recommends[2] time=1, and memory=64*1024 is a sensible number.
 // If using that amount of memory (64 MB) is not possible in some contexts then
 // the time parameter can be increased to compensate.
-const (
-	argonTime    = 1
-	argonMemory  = 64 * 1024
-	argonThreads = 4
-	argonKeyLen  = 32
-	argonSaltLen = 16
-)
+func DefaultUserConfig() UserConfig {
+	return UserConfig{
+		TokenIssuer: "!GOOOOFrrrr3r",
+		TokenExpiry: time.Hour,
+		Argon2: Argon2Params{
+			Time:    1,
+			Memory:  64 * 1024,
+			Threads: 4,
+			KeyLen:  32,
+			SaltLen: 16,
+		},
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+	}
+}
 
-var myJWTSigningKey = []byte("GoFr_am3work!s")
+type UserService struct {
+	repo  repo.UserRepo
+	audit *AuditService
+	cfg   UserConfig
+}
 
-const (
-	tokenExpiryTime = time.Hour
-	tokenIssuer     = "!GOOOOFrrrr3r"
-)
+func NewUserService(r repo.UserRepo, a *AuditService) *UserService {
+	return NewUserServiceWithConfig(r, a, DefaultUserConfig())
+}
 
-type UserService struct {
-	repo repo.UserRepo
+// NewUserServiceWithConfig returns a user service with the given settings
+func NewUserServiceWithConfig(r repo.UserRepo, a *AuditService, cfg UserConfig) *UserService {
+	if len(cfg.JWTSigningKey) == 0 {
+		cfg.JWTSigningKey = make([]byte, 32)
+		_, _ = rand.Read(cfg.JWTSigningKey)
+	}
+
+	return &UserService{repo: r, audit: a, cfg: cfg}
 }
 
-func NewUserService(r repo.UserRepo) *UserService {
-	return &UserService{repo: r}
+// hashPassword generates a new salt and hashes the password with Argon2id
+func (s *UserService) hashPassword(password string) (hash, salt []byte, err error) {
+	salt = make([]byte, s.cfg.Argon2.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return s.hash(password, salt), salt, nil
 }
 
-func (s *UserService) Create(uc model.UserCreate) (model.User, error) {
+// hash hashes the password with the given salt using Argon2id
+func (s *UserService) hash(password string, salt []byte) []byte {
+	p := s.cfg.Argon2
+
+	return argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+}
+
+func (s *UserService) Create(ctx context.Context, uc model.UserCreate) (model.User, error) {
 	if err := uc.Validate(); err != nil {
+		return model.User{}, invalid(err)
+	}
+
+	hash, salt, err := s.hashPassword(uc.Password)
+	if err != nil {
 		return model.User{}, err
 	}
 
-	// Generate salt
-	salt := make([]byte, argonSaltLen)
-	if _, err := rand.Read(salt); err != nil {
-		return model.User{}, fmt.Errorf("failed to generate salt: %w", err)
+	user, err := s.repo.Create(uc, hash, salt)
+	if err != nil {
+		return model.User{}, err
 	}
 
-	// Hash the password with Argon2id
-	hash := argon2.IDKey([]byte(uc.Password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	s.audit.Record(ctx, model.AuditEvent{
+		Type:    model.AuditEventUserCreated,
+		Target:  user.ID,
+		Outcome: model.AuditOutcomeSuccess,
+	})
 
-	user, err := s.repo.Create(uc, hash, salt)
+	return user, nil
+}
+
+func (s *UserService) GetByID(ctx context.Context, id string) (model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return model.User{}, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+func (s *UserService) List(ctx context.Context) ([]model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List()
+}
+
+// Search returns a page of the users matching the search, and the total number of matching users
+func (s *UserService) Search(ctx context.Context, us model.UserSearch) (model.UserPage, error) {
+	if err := us.Validate(); err != nil {
+		return model.UserPage{}, invalid(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return model.UserPage{}, err
+	}
+
+	return s.repo.Search(us)
+}
+
+// Update changes the user if the precondition holds for its current version
+func (s *UserService) Update(ctx context.Context, id string, precondition repo.Precondition, uu model.UserUpdate) (model.User, error) {
+	if err := uu.Validate(); err != nil {
+		return model.User{}, invalid(err)
+	}
+
+	original, err := s.repo.GetByID(id)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user, err := s.repo.Update(id, precondition, uu)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if !slices.Equal(original.Groups, user.Groups) {
+		s.audit.Record(ctx, model.AuditEvent{
+			Type:    model.AuditEventGroupsChanged,
+			Target:  user.ID,
+			Outcome: model.AuditOutcomeSuccess,
+			Reason:  fmt.Sprintf("groups changed from %v to %v", original.Groups, user.Groups),
+		})
+	}
+
+	return user, nil
+}
+
+// UpdatePassword sets a new password for the user if the precondition holds for its current version
+func (s *UserService) UpdatePassword(ctx context.Context, id string, precondition repo.Precondition, upu model.UserPasswordUpdate) (model.User, error) {
+	if err := upu.Validate(); err != nil {
+		return model.User{}, invalid(err)
+	}
+
+	hash, salt, err := s.hashPassword(upu.Password)
 	if err != nil {
 		return model.User{}, err
 	}
 
+	user, err := s.repo.UpdatePassword(id, precondition, hash, salt)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		Type:    model.AuditEventPasswordChanged,
+		Target:  user.ID,
+		Outcome: model.AuditOutcomeSuccess,
+	})
+
 	return user, nil
 }
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
+// Delete schedules the user for deletion. The account is locked immediately, but it is only anonymized once
+// the grace period is over, until then it can be restored by reactivating it.
+func (s *UserService) Delete(ctx context.Context, id string, precondition repo.Precondition) (model.User, error) {
+	return s.UpdateStatus(ctx, id, precondition, model.UserStatusUpdate{Status: model.UserStatusPendingDeletion})
+}
+
+// UpdateStatus moves the user to a new lifecycle state if the precondition holds for its current version.
+// Suspending or deleting a user ends all their sessions.
+func (s *UserService) UpdateStatus(ctx context.Context, id string, precondition repo.Precondition, usu model.UserStatusUpdate) (model.User, error) {
+	if err := usu.Validate(); err != nil {
+		return model.User{}, invalid(err)
+	}
+
+	var deletionScheduledAt *time.Time
+	if usu.Status == model.UserStatusPendingDeletion {
+		at := time.Now().Add(s.cfg.DeletionGracePeriod).UTC()
+		deletionScheduledAt = &at
+	}
+
+	user, err := s.repo.UpdateStatus(id, precondition, usu.Status, deletionScheduledAt)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		Type:    model.AuditEventStatusChanged,
+		Target:  user.ID,
+		Outcome: model.AuditOutcomeSuccess,
+		Reason:  "status changed to " + string(user.Status),
+	})
+
+	return user, nil
+}
+
+// PurgeDeleted anonymizes every user whose deletion grace period ended before now.
+// It returns the number of users anonymized.
+func (s *UserService) PurgeDeleted(ctx context.Context, now time.Time) (int, error) {
+	users, err := s.repo.ListScheduledForDeletion(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, user := range users {
+		if _, err := s.repo.Anonymize(user.ID); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) || errors.Is(err, repo.ErrInvalidStatusTransition) {
+				// The user was restored or removed since being listed
+				continue
+			}
+
+			return count, err
+		}
+
+		s.audit.Record(ctx, model.AuditEvent{
+			Type:    model.AuditEventUserDeleted,
+			Target:  user.ID,
+			Outcome: model.AuditOutcomeSuccess,
+		})
+		count++
+	}
+
+	return count, nil
+}
+
+// adminGroups are the groups granted to bootstrapped and recovered administrators
+var adminGroups = []string{model.GroupAdmin, model.GroupProjectRead, model.GroupProjectWrite}
+
+var ErrAdminExists = fmt.Errorf("an administrator already exists, err: %w", repo.ErrConflict)
+
+// BootstrapAdmin creates the first administrator. Once any administrator exists it returns ErrAdminExists,
+// making it safe to call on every start.
+func (s *UserService) BootstrapAdmin(ctx context.Context, name, email, password string) (model.User, error) {
+	exists, err := s.repo.HasGroupMember(model.GroupAdmin)
+	if err != nil {
+		return model.User{}, err
+	}
+	if exists {
+		return model.User{}, ErrAdminExists
+	}
+
+	return s.Create(ctx, model.UserCreate{
+		Name:      name,
+		Email:     email,
+		Password:  password,
+		Password2: password,
+		Groups:    adminGroups,
+	})
+}
+
+// CreateAdmin creates an administrator. If a user with the given email already exists, their password is
+// reset, they are granted the administrator groups and reactivated instead, which allows recovering access.
+func (s *UserService) CreateAdmin(ctx context.Context, name, email, password string) (model.User, error) {
+	user, err := s.repo.GetByEmail(email)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		return s.Create(ctx, model.UserCreate{
+			Name:      name,
+			Email:     email,
+			Password:  password,
+			Password2: password,
+			Groups:    adminGroups,
+		})
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	groups := slices.Clone(user.Groups)
+	for _, group := range adminGroups {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+
+	if _, err := s.Update(ctx, user.ID, repo.AnyVersion, model.UserUpdate{Groups: groups}); err != nil {
+		return model.User{}, err
+	}
+	if user.Status != model.UserStatusActive {
+		if _, err := s.UpdateStatus(ctx, user.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusActive}); err != nil {
+			return model.User{}, err
+		}
+	}
+
+	return s.UpdatePassword(ctx, user.ID, repo.AnyVersion, model.UserPasswordUpdate{Password: password, Password2: password})
+}
+
+// GeneratePassword returns a random password suitable for a bootstrapped administrator
+func GeneratePassword() string {
+	return rand.Text()
+}
+
+var (
+	ErrInvalidCredentials = fmt.Errorf("invalid credentials, err: %w", ErrUnauthorized)
+	ErrAccountInactive    = fmt.Errorf("account is not active, err: %w", ErrForbidden)
+)
+
+// maxAuditedEmailLength is the length of the longest prefix of unknown emails recorded in the audit log
+const maxAuditedEmailLength = 64
+
+func (s *UserService) Login(ctx context.Context, ul model.UserLogin) (string, error) {
+	if err := ul.Validate(); err != nil {
+		return "", invalid(err)
+	}
 
-func (s *UserService) Login(ul model.UserLogin) (string, error) {
 	user, err := s.repo.GetByEmail(ul.Email)
+	if errors.Is(err, repo.ErrNotFound) {
+		// Hash the password anyway, so that unknown emails can not be told apart by the response time
+		s.hash(ul.Password, make([]byte, s.cfg.Argon2.SaltLen))
+
+		// The email is not an actor, as it is not known to belong to anyone, only a hint of what was attempted
+		email := strings.ToLower(ul.Email)
+		if runes := []rune(email); len(runes) > maxAuditedEmailLength {
+			email = string(runes[:maxAuditedEmailLength])
+		}
+		s.audit.Record(ctx, model.AuditEvent{
+			Type:    model.AuditEventLoginFailed,
+			Outcome: model.AuditOutcomeFailure,
+			Reason:  "unknown email: " + email,
+		})
+
+		return "", ErrInvalidCredentials
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to sign the token, err: %w", err)
+		return "", fmt.Errorf("failed to get user, email: %s, err: %w", ul.Email, err)
 	}
 
 	// Hash the provided password with the stored salt
-	hash := argon2.IDKey([]byte(ul.Password), user.PasswordSalt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	hash := s.hash(ul.Password, user.PasswordSalt)
 
 	// Time-attack-resilient comparison of the password against the stored hash
 	if subtle.ConstantTimeCompare(hash, user.PasswordHash) != 1 {
+		s.audit.Record(ctx, model.AuditEvent{
+			Type:    model.AuditEventLoginFailed,
+			Actor:   user.ID,
+			Outcome: model.AuditOutcomeFailure,
+			Reason:  "invalid password",
+		})
+
 		return "", ErrInvalidCredentials
 	}
 
+	if user.Status != model.UserStatusActive {
+		s.audit.Record(ctx, model.AuditEvent{
+			Type:    model.AuditEventLoginFailed,
+			Actor:   user.ID,
+			Outcome: model.AuditOutcomeDenied,
+			Reason:  "account " + string(user.Status),
+		})
+
+		return "", ErrAccountInactive
+	}
+
 	// Create the Claims
 	claims := model.LoggedInUser{
-		ID:     user.ID,
-		Name:   user.Name,
-		Groups: user.Groups,
+		ID:           user.ID,
+		Name:         user.Name,
+		Groups:       user.Groups,
+		SessionEpoch: user.SessionEpoch,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenExpiryTime)),
-			Issuer:    tokenIssuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.cfg.TokenExpiry)),
+			Issuer:    s.cfg.TokenIssuer,
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString(myJWTSigningKey)
+	ss, err := token.SignedString(s.cfg.JWTSigningKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign the token, err: %w", err)
 	}
 
+	s.audit.Record(ctx, model.AuditEvent{
+		Type:    model.AuditEventLogin,
+		Actor:   user.ID,
+		Outcome: model.AuditOutcomeSuccess,
+	})
+
 	return ss, nil
 }
 
-var ErrUnknownClaimsType = errors.New("unknown claims type")
+var (
+	ErrUnknownClaimsType = fmt.Errorf("unknown claims type, err: %w", ErrUnauthorized)
+	ErrInvalidToken      = fmt.Errorf("invalid token, err: %w", ErrUnauthorized)
+	ErrSessionRevoked    = errors.New("session revoked")
+)
 
-func (s *UserService) TokenToLoggedInUser(tokenString string) (model.LoggedInUser, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &model.LoggedInUser{}, func(token *jwt.Token) (any, error) {
-		return myJWTSigningKey, nil
-	})
+// TokenToLoggedInUser verifies the token and returns the user it was issued to. Tokens of users who are no
+// longer active, or whose sessions were revoked since, are rejected. Groups are always the current ones.
+func (s *UserService) TokenToLoggedInUser(ctx context.Context, tokenString string) (model.LoggedInUser, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&model.LoggedInUser{},
+		func(token *jwt.Token) (any, error) {
+			return s.cfg.JWTSigningKey, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.cfg.TokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		log.Fatal(err)
+		return model.LoggedInUser{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
 	}
 
-	if claims, ok := token.Claims.(*model.LoggedInUser); ok {
-		return *claims, nil
+	claims, ok := token.Claims.(*model.LoggedInUser)
+	if !ok {
+		return model.LoggedInUser{}, ErrUnknownClaimsType
 	}
 
-	return model.LoggedInUser{}, ErrUnknownClaimsType
+	user, err := s.repo.GetByID(claims.ID)
+	if err != nil {
+		return model.LoggedInUser{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if user.Status != model.UserStatusActive {
+		LoggerFromContext(ctx).Debug("Token of inactive user rejected", "user", user.ID, "status", user.Status)
+		return model.LoggedInUser{}, fmt.Errorf("%w: %w", ErrInvalidToken, ErrAccountInactive)
+	}
+
+	if user.SessionEpoch != claims.SessionEpoch {
+		LoggerFromContext(ctx).Debug("Token of revoked session rejected", "user", user.ID)
+		return model.LoggedInUser{}, fmt.Errorf("%w: %w", ErrInvalidToken, ErrSessionRevoked)
+	}
+
+	claims.Groups = user.Groups
+
+	return *claims, nil
 }
@@ -1,7 +1,10 @@
 package service_test
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/peteraba/go-frameworks/shared/model"
 	"github.com/peteraba/go-frameworks/shared/repo"
@@ -12,12 +15,12 @@ import (
 
 func TestUserService_Create(t *testing.T) {
 	userRepo := repo.NewInMemoryUserRepo()
-	sut := service.NewUserService(userRepo)
+	sut := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
 
 	t.Run("successful creation", func(t *testing.T) {
 		uc := model.RandomUserCreate()
 
-		user, err := sut.Create(uc)
+		user, err := sut.Create(context.Background(), uc)
 		require.NoError(t, err)
 		assert.Equal(t, uc.Name, user.Name)
 		assert.Equal(t, uc.Email, user.Email)
@@ -31,9 +34,10 @@ func TestUserService_Create(t *testing.T) {
 		uc := model.RandomUserCreate()
 		uc.Password2 = "DifferentPassword!"
 
-		user, err := sut.Create(uc)
+		user, err := sut.Create(context.Background(), uc)
 		assert.Error(t, err)
-		assert.ErrorContains(t, err, "'Password2' failed on the 'eqfield'")
+		assert.ErrorContains(t, err, "'password2' failed on the 'eqfield'")
+		assert.ErrorIs(t, err, service.ErrValidation)
 		assert.Empty(t, user.ID)
 	})
 
@@ -42,7 +46,7 @@ func TestUserService_Create(t *testing.T) {
 		uc.Password = ""
 		uc.Password2 = ""
 
-		user, err := sut.Create(uc)
+		user, err := sut.Create(context.Background(), uc)
 		assert.Error(t, err)
 		assert.Empty(t, user.ID)
 	})
@@ -51,21 +55,21 @@ func TestUserService_Create(t *testing.T) {
 		// Simulate duplicate user by using the same repo and user details
 		uc := model.RandomUserCreate()
 
-		_, err := sut.Create(uc)
+		_, err := sut.Create(context.Background(), uc)
 		require.NoError(t, err)
-		// Try to create again with the same details (should not error, as ID is generated, but let's check)
-		_, err = sut.Create(uc)
-		assert.NoError(t, err)
+		// Try to create again with the same details, the email is already taken
+		_, err = sut.Create(context.Background(), uc)
+		assert.ErrorIs(t, err, repo.ErrEmailTaken)
 	})
 }
 
 func TestUserService_Login(t *testing.T) {
 	userRepo := repo.NewInMemoryUserRepo()
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
 
 	// Create a user
 	sut := model.RandomUserCreate()
-	_, err := userService.Create(sut)
+	_, err := userService.Create(context.Background(), sut)
 	require.NoError(t, err)
 
 	t.Run("successful login", func(t *testing.T) {
@@ -76,7 +80,7 @@ func TestUserService_Login(t *testing.T) {
 		}
 
 		// execute
-		token, err := userService.Login(ul)
+		token, err := userService.Login(context.Background(), ul)
 
 		// verify
 		assert.NoError(t, err)
@@ -88,15 +92,30 @@ func TestUserService_Login(t *testing.T) {
 		// prepare
 		ul := model.UserLogin{
 			Email:    sut.Email,
-			Password: "wrong",
+			Password: "wrong password",
 		}
 
 		// execute
-		_, err := userService.Login(ul)
+		_, err := userService.Login(context.Background(), ul)
 
 		// verify
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, "invalid credentials")
+		assert.ErrorIs(t, err, service.ErrUnauthorized)
+	})
+
+	t.Run("invalid login", func(t *testing.T) {
+		// prepare
+		ul := model.UserLogin{
+			Email:    strings.Repeat("a", 250) + "@example.com",
+			Password: "irrelevant",
+		}
+
+		// execute
+		_, err := userService.Login(context.Background(), ul)
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrValidation)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
@@ -107,21 +126,20 @@ func TestUserService_Login(t *testing.T) {
 		}
 
 		// execute
-		_, err := userService.Login(ul)
+		_, err := userService.Login(context.Background(), ul)
 
 		// verify
-		assert.Error(t, err)
-		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+		assert.Equal(t, service.ErrInvalidCredentials, err, "unknown emails are reported like wrong passwords")
 	})
 }
 
 func TestUserService_TokenToLoggedInUser(t *testing.T) {
 	userRepo := repo.NewInMemoryUserRepo()
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
 
 	// Create a user
 	ucStub := model.RandomUserCreate()
-	userStub, err := userService.Create(ucStub)
+	userStub, err := userService.Create(context.Background(), ucStub)
 	require.NoError(t, err)
 
 	t.Run("successful decoding", func(t *testing.T) {
@@ -131,14 +149,435 @@ func TestUserService_TokenToLoggedInUser(t *testing.T) {
 			Password: ucStub.Password,
 		}
 
-		token, err := userService.Login(ul)
+		token, err := userService.Login(context.Background(), ul)
 		require.NoError(t, err)
 
 		// execute
-		liu, err := userService.TokenToLoggedInUser(token)
+		liu, err := userService.TokenToLoggedInUser(context.Background(), token)
 
 		// verify
 		assert.NoError(t, err)
 		assert.Equal(t, userStub.ID, liu.ID)
 	})
 }
+
+func TestNewUserServiceWithConfig(t *testing.T) {
+	// prepare
+	cfg := service.DefaultUserConfig()
+	cfg.JWTSigningKey = []byte("0123456789abcdef0123456789abcdef")
+	cfg.TokenIssuer = "test"
+	cfg.TokenExpiry = time.Minute
+	cfg.Argon2.Time = 2
+	cfg.Argon2.Memory = 8 * 1024
+
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserServiceWithConfig(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()), cfg)
+
+	ucStub := model.RandomUserCreate()
+	_, err := userService.Create(context.Background(), ucStub)
+	require.NoError(t, err)
+
+	t.Run("tokens follow the configuration", func(t *testing.T) {
+		// execute
+		token, err := userService.Login(context.Background(), model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+		require.NoError(t, err)
+		liu, err := userService.TokenToLoggedInUser(context.Background(), token)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, "test", liu.Issuer)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), liu.ExpiresAt.Time, 5*time.Second)
+	})
+
+	t.Run("tokens of services with another key are rejected", func(t *testing.T) {
+		// prepare
+		otherCfg := cfg
+		otherCfg.JWTSigningKey = []byte("fedcba9876543210fedcba9876543210")
+		other := service.NewUserServiceWithConfig(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()), otherCfg)
+
+		token, err := userService.Login(context.Background(), model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+		require.NoError(t, err)
+
+		// execute
+		_, err = other.TokenToLoggedInUser(context.Background(), token)
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+
+	t.Run("passwords hashed with other parameters can not be verified", func(t *testing.T) {
+		// prepare
+		other := service.NewUserServiceWithConfig(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()), service.DefaultUserConfig())
+
+		// execute
+		_, err := other.Login(context.Background(), model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	})
+}
+
+func TestUserService_TokenToLoggedInUser_InvalidToken(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
+
+	// execute
+	_, err := userService.TokenToLoggedInUser(context.Background(), "not-a-token")
+
+	// verify
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+}
+
+func TestUserService_Audit(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	auditSink := repo.NewInMemoryAuditSink()
+	userService := service.NewUserService(userRepo, service.NewAuditService(auditSink))
+
+	ctx := service.ContextWithActor(context.Background(), service.Actor{UserID: "admin-id", IP: "127.0.0.1"})
+
+	ucStub := model.RandomUserCreate()
+	userStub, err := userService.Create(ctx, ucStub)
+	require.NoError(t, err)
+
+	t.Run("user creation is recorded", func(t *testing.T) {
+		// execute
+		events, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventUserCreated})
+
+		// verify
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "admin-id", events[0].Actor)
+		assert.Equal(t, userStub.ID, events[0].Target)
+		assert.Equal(t, "127.0.0.1", events[0].IP)
+		assert.Equal(t, model.AuditOutcomeSuccess, events[0].Outcome)
+	})
+
+	t.Run("successful and failed logins are recorded", func(t *testing.T) {
+		// prepare
+		loginCtx := service.ContextWithActor(context.Background(), service.Actor{IP: "10.0.0.1"})
+
+		// execute
+		_, err := userService.Login(loginCtx, model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+		require.NoError(t, err)
+		_, err = userService.Login(loginCtx, model.UserLogin{Email: ucStub.Email, Password: "wrong password"})
+		require.Error(t, err)
+		_, err = userService.Login(loginCtx, model.UserLogin{Email: "NotFound@Example.com", Password: "wrong password"})
+		require.Error(t, err)
+
+		// verify
+		logins, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventLogin})
+		require.NoError(t, err)
+		require.Len(t, logins, 1)
+		assert.Equal(t, userStub.ID, logins[0].Actor)
+		assert.Equal(t, "10.0.0.1", logins[0].IP)
+
+		failures, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventLoginFailed})
+		require.NoError(t, err)
+		require.Len(t, failures, 2)
+		assert.Equal(t, userStub.ID, failures[0].Actor)
+		assert.Empty(t, failures[1].Actor, "unknown emails are not actors")
+		assert.Equal(t, "unknown email: notfound@example.com", failures[1].Reason)
+		assert.Equal(t, model.AuditOutcomeFailure, failures[1].Outcome)
+	})
+
+	t.Run("password change is recorded", func(t *testing.T) {
+		// prepare
+		upu := model.RandomUserPasswordUpdate()
+
+		// execute
+		_, err := userService.UpdatePassword(ctx, userStub.ID, repo.AnyVersion, upu)
+		require.NoError(t, err)
+
+		// verify
+		events, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventPasswordChanged, Target: userStub.ID})
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		_, err = userService.Login(ctx, model.UserLogin{Email: ucStub.Email, Password: upu.Password})
+		assert.NoError(t, err)
+	})
+
+	t.Run("group change is recorded, other updates are not", func(t *testing.T) {
+		// execute
+		_, err := userService.Update(ctx, userStub.ID, repo.AnyVersion, model.UserUpdate{Name: "Renamed"})
+		require.NoError(t, err)
+		_, err = userService.Update(ctx, userStub.ID, repo.AnyVersion, model.UserUpdate{Groups: []string{model.GroupAdmin}})
+		require.NoError(t, err)
+
+		// verify
+		events, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventGroupsChanged})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, userStub.ID, events[0].Target)
+	})
+
+	t.Run("scheduled deletion and purge are recorded", func(t *testing.T) {
+		// execute
+		_, err := userService.Delete(ctx, userStub.ID, repo.AnyVersion)
+		require.NoError(t, err)
+		_, err = userService.PurgeDeleted(ctx, time.Now().Add(31*24*time.Hour))
+		require.NoError(t, err)
+
+		// verify
+		statusEvents, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventStatusChanged})
+		require.NoError(t, err)
+		assert.Len(t, statusEvents, 1)
+		deleteEvents, err := auditSink.Query(model.AuditEventFilter{Type: model.AuditEventUserDeleted})
+		require.NoError(t, err)
+		assert.Len(t, deleteEvents, 1)
+	})
+}
+
+func TestUserService_BootstrapAdmin(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
+
+	t.Run("first admin is created", func(t *testing.T) {
+		// prepare
+		password := service.GeneratePassword()
+
+		// execute
+		admin, err := userService.BootstrapAdmin(context.Background(), "Admin", "admin@example.com", password)
+
+		// verify
+		require.NoError(t, err)
+		assert.Contains(t, admin.Groups, model.GroupAdmin)
+		_, err = userService.Login(context.Background(), model.UserLogin{Email: "admin@example.com", Password: password})
+		assert.NoError(t, err)
+	})
+
+	t.Run("bootstrap is skipped once an admin exists", func(t *testing.T) {
+		// execute
+		_, err := userService.BootstrapAdmin(context.Background(), "Other", "other@example.com", service.GeneratePassword())
+
+		// verify
+		assert.ErrorIs(t, err, service.ErrAdminExists)
+		_, err = userRepo.GetByEmail("other@example.com")
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+	})
+
+	t.Run("suspended admins do not count", func(t *testing.T) {
+		// prepare
+		admin, err := userRepo.GetByEmail("admin@example.com")
+		require.NoError(t, err)
+		_, err = userService.UpdateStatus(context.Background(), admin.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+		require.NoError(t, err)
+
+		// execute
+		other, err := userService.BootstrapAdmin(context.Background(), "Other", "other@example.com", service.GeneratePassword())
+
+		// verify
+		require.NoError(t, err)
+		assert.Contains(t, other.Groups, model.GroupAdmin)
+	})
+}
+
+func TestUserService_CreateAdmin(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
+
+	t.Run("new admin is created", func(t *testing.T) {
+		// execute
+		admin, err := userService.CreateAdmin(context.Background(), "Admin", "admin@example.com", service.GeneratePassword())
+
+		// verify
+		require.NoError(t, err)
+		assert.Contains(t, admin.Groups, model.GroupAdmin)
+	})
+
+	t.Run("existing user is promoted and their password is reset", func(t *testing.T) {
+		// prepare
+		ucStub := model.RandomUserCreate()
+		ucStub.Groups = []string{model.GroupProjectRead}
+		userStub, err := userService.Create(context.Background(), ucStub)
+		require.NoError(t, err)
+		password := service.GeneratePassword()
+
+		// execute
+		admin, err := userService.CreateAdmin(context.Background(), "Ignored", ucStub.Email, password)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, userStub.ID, admin.ID)
+		assert.Equal(t, userStub.Name, admin.Name)
+		assert.ElementsMatch(t, []string{model.GroupAdmin, model.GroupProjectRead, model.GroupProjectWrite}, admin.Groups)
+		_, err = userService.Login(context.Background(), model.UserLogin{Email: ucStub.Email, Password: password})
+		assert.NoError(t, err)
+	})
+
+	t.Run("suspended admin is reactivated", func(t *testing.T) {
+		// prepare
+		ucStub := model.RandomUserCreate()
+		ucStub.Groups = []string{model.GroupAdmin}
+		userStub, err := userService.Create(context.Background(), ucStub)
+		require.NoError(t, err)
+		_, err = userService.UpdateStatus(context.Background(), userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+		require.NoError(t, err)
+		password := service.GeneratePassword()
+
+		// execute
+		admin, err := userService.CreateAdmin(context.Background(), "Ignored", ucStub.Email, password)
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusActive, admin.Status)
+		_, err = userService.Login(context.Background(), model.UserLogin{Email: ucStub.Email, Password: password})
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserService_Lifecycle(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
+	ctx := context.Background()
+
+	createUser := func(t *testing.T) (model.UserCreate, model.User, string) {
+		t.Helper()
+
+		uc := model.RandomUserCreate()
+		user, err := userService.Create(ctx, uc)
+		require.NoError(t, err)
+		token, err := userService.Login(ctx, model.UserLogin{Email: uc.Email, Password: uc.Password})
+		require.NoError(t, err)
+
+		return uc, user, token
+	}
+
+	t.Run("suspended user can not log in and their sessions end", func(t *testing.T) {
+		// prepare
+		ucStub, userStub, token := createUser(t)
+
+		// execute
+		suspended, err := userService.UpdateStatus(ctx, userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusSuspended, suspended.Status)
+		_, err = userService.Login(ctx, model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+		assert.ErrorIs(t, err, service.ErrAccountInactive)
+		_, err = userService.TokenToLoggedInUser(context.Background(), token)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+		assert.ErrorIs(t, err, service.ErrAccountInactive)
+	})
+
+	t.Run("reactivated user can log in, but old sessions stay revoked", func(t *testing.T) {
+		// prepare
+		ucStub, userStub, token := createUser(t)
+		_, err := userService.UpdateStatus(ctx, userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusSuspended})
+		require.NoError(t, err)
+
+		// execute
+		reactivated, err := userService.UpdateStatus(ctx, userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusActive})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusActive, reactivated.Status)
+		_, err = userService.TokenToLoggedInUser(context.Background(), token)
+		assert.ErrorIs(t, err, service.ErrSessionRevoked)
+		newToken, err := userService.Login(ctx, model.UserLogin{Email: ucStub.Email, Password: ucStub.Password})
+		require.NoError(t, err)
+		_, err = userService.TokenToLoggedInUser(context.Background(), newToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deleted user is anonymized after the grace period only", func(t *testing.T) {
+		// prepare
+		ucStub, userStub, _ := createUser(t)
+
+		// execute
+		pending, err := userService.Delete(ctx, userStub.ID, repo.AnyVersion)
+		require.NoError(t, err)
+		earlyCount, err := userService.PurgeDeleted(ctx, time.Now())
+		require.NoError(t, err)
+		lateCount, err := userService.PurgeDeleted(ctx, time.Now().Add(31*24*time.Hour))
+		require.NoError(t, err)
+
+		// verify
+		assert.Equal(t, model.UserStatusPendingDeletion, pending.Status)
+		require.NotNil(t, pending.DeletionScheduledAt)
+		assert.True(t, pending.DeletionScheduledAt.After(time.Now().Add(29*24*time.Hour)))
+		assert.Equal(t, 0, earlyCount)
+		assert.Equal(t, 1, lateCount)
+
+		deleted, err := userService.GetByID(context.Background(), userStub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusDeleted, deleted.Status)
+		assert.NotEqual(t, userStub.Name, deleted.Name)
+		assert.NotEqual(t, userStub.Email, deleted.Email)
+		assert.Empty(t, deleted.PasswordHash)
+		_, err = userRepo.GetByEmail(ucStub.Email)
+		assert.ErrorIs(t, err, repo.ErrUserNotFound)
+	})
+
+	t.Run("deletion can be cancelled during the grace period", func(t *testing.T) {
+		// prepare
+		_, userStub, _ := createUser(t)
+		_, err := userService.Delete(ctx, userStub.ID, repo.AnyVersion)
+		require.NoError(t, err)
+
+		// execute
+		restored, err := userService.UpdateStatus(ctx, userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusActive})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusActive, restored.Status)
+		assert.Nil(t, restored.DeletionScheduledAt)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		// prepare
+		_, userStub, _ := createUser(t)
+
+		// execute
+		_, err := userService.UpdateStatus(ctx, userStub.ID, repo.AnyVersion, model.UserStatusUpdate{Status: model.UserStatusActive})
+
+		// verify
+		assert.ErrorIs(t, err, repo.ErrInvalidStatusTransition)
+	})
+}
+
+func TestUserService_Search(t *testing.T) {
+	userRepo := repo.NewInMemoryUserRepo()
+	userService := service.NewUserService(userRepo, service.NewAuditService(repo.NewInMemoryAuditSink()))
+
+	userStub, err := userService.Create(context.Background(), model.RandomUserCreate())
+	require.NoError(t, err)
+
+	t.Run("valid search", func(t *testing.T) {
+		// execute
+		page, err := userService.Search(context.Background(), model.UserSearch{Email: userStub.Email, Sort: "-name"})
+
+		// verify
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.Total)
+		assert.Equal(t, []model.User{userStub}, page.Users)
+	})
+
+	t.Run("invalid sort field", func(t *testing.T) {
+		// execute
+		_, err := userService.Search(context.Background(), model.UserSearch{Sort: "password"})
+
+		// verify
+		assert.Error(t, err)
+	})
+
+	t.Run("limit too high", func(t *testing.T) {
+		// execute
+		_, err := userService.Search(context.Background(), model.UserSearch{Limit: 1000})
+
+		// verify
+		assert.Error(t, err)
+	})
+	t.Run("canceled request", func(t *testing.T) {
+		// prepare
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// execute
+		_, err := userService.Search(ctx, model.UserSearch{})
+
+		// verify
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}